@@ -49,6 +49,10 @@ import (
 	"github.com/golang/glog"
 	"github.com/heptiolabs/healthcheck"
 	"github.com/kubermatic/machine-controller/pkg/apis/cluster/v1alpha1/migrations"
+	hostpoolclientset "github.com/kubermatic/machine-controller/pkg/client/clientset/versioned"
+	"github.com/kubermatic/machine-controller/pkg/cloudprovider/provider/aws"
+	"github.com/kubermatic/machine-controller/pkg/cloudprovider/provider/fake"
+	"github.com/kubermatic/machine-controller/pkg/cloudprovider/provider/hostpool"
 	"github.com/kubermatic/machine-controller/pkg/clusterinfo"
 	machinecontroller "github.com/kubermatic/machine-controller/pkg/controller/machine"
 	machinehealth "github.com/kubermatic/machine-controller/pkg/health"
@@ -193,6 +197,14 @@ func main() {
 		glog.Fatalf("error building example clientset for machineClient: %v", err)
 	}
 
+	hostPoolClient, err := hostpoolclientset.NewForConfig(cfg)
+	if err != nil {
+		glog.Fatalf("error building clientset for hostPoolClient: %v", err)
+	}
+	hostpool.SetClient(hostPoolClient)
+	fake.SetClient(kubeClient)
+	aws.SetEventRecorder(createRecorder(kubeClient))
+
 	leaderElectionClient, err := kubernetes.NewForConfig(cfg)
 	if err != nil {
 		glog.Fatalf("error building kubernetes clientset for leaderElectionClient: %v", err)
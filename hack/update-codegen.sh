@@ -26,5 +26,5 @@ SCRIPT_ROOT=$(dirname "${BASH_SOURCE}")
 echo $SCRIPT_ROOT
 ./vendor/k8s.io/code-generator/generate-groups.sh all \
     github.com/kubermatic/machine-controller/pkg/client github.com/kubermatic/machine-controller/pkg \
-    machines:v1alpha1 \
+    "machines:v1alpha1 hostpool:v1alpha1" \
     --go-header-file=${SCRIPT_ROOT}/header.txt
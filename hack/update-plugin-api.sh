@@ -0,0 +1,12 @@
+#!/bin/bash
+
+set -o errexit
+set -o nounset
+set -o pipefail
+
+# Regenerates the go code of the cloud provider plugin protocol.
+# Requires protoc and protoc-gen-go in the version of the vendored github.com/golang/protobuf.
+
+cd $(dirname "${BASH_SOURCE}")/..
+
+protoc --go_out=plugins=grpc:. pkg/cloudprovider/provider/plugin/api/v1alpha1/plugin.proto
@@ -20,6 +20,7 @@ package versioned
 
 import (
 	glog "github.com/golang/glog"
+	hostpoolv1alpha1 "github.com/kubermatic/machine-controller/pkg/client/clientset/versioned/typed/hostpool/v1alpha1"
 	machinev1alpha1 "github.com/kubermatic/machine-controller/pkg/client/clientset/versioned/typed/machines/v1alpha1"
 	discovery "k8s.io/client-go/discovery"
 	rest "k8s.io/client-go/rest"
@@ -28,6 +29,9 @@ import (
 
 type Interface interface {
 	Discovery() discovery.DiscoveryInterface
+	HostpoolV1alpha1() hostpoolv1alpha1.HostpoolV1alpha1Interface
+	// Deprecated: please explicitly pick a version if possible.
+	Hostpool() hostpoolv1alpha1.HostpoolV1alpha1Interface
 	MachineV1alpha1() machinev1alpha1.MachineV1alpha1Interface
 	// Deprecated: please explicitly pick a version if possible.
 	Machine() machinev1alpha1.MachineV1alpha1Interface
@@ -37,7 +41,19 @@ type Interface interface {
 // version included in a Clientset.
 type Clientset struct {
 	*discovery.DiscoveryClient
-	machineV1alpha1 *machinev1alpha1.MachineV1alpha1Client
+	hostpoolV1alpha1 *hostpoolv1alpha1.HostpoolV1alpha1Client
+	machineV1alpha1  *machinev1alpha1.MachineV1alpha1Client
+}
+
+// HostpoolV1alpha1 retrieves the HostpoolV1alpha1Client
+func (c *Clientset) HostpoolV1alpha1() hostpoolv1alpha1.HostpoolV1alpha1Interface {
+	return c.hostpoolV1alpha1
+}
+
+// Deprecated: Hostpool retrieves the default version of HostpoolClient.
+// Please explicitly pick a version.
+func (c *Clientset) Hostpool() hostpoolv1alpha1.HostpoolV1alpha1Interface {
+	return c.hostpoolV1alpha1
 }
 
 // MachineV1alpha1 retrieves the MachineV1alpha1Client
@@ -67,6 +83,10 @@ func NewForConfig(c *rest.Config) (*Clientset, error) {
 	}
 	var cs Clientset
 	var err error
+	cs.hostpoolV1alpha1, err = hostpoolv1alpha1.NewForConfig(&configShallowCopy)
+	if err != nil {
+		return nil, err
+	}
 	cs.machineV1alpha1, err = machinev1alpha1.NewForConfig(&configShallowCopy)
 	if err != nil {
 		return nil, err
@@ -84,6 +104,7 @@ func NewForConfig(c *rest.Config) (*Clientset, error) {
 // panics if there is an error in the config.
 func NewForConfigOrDie(c *rest.Config) *Clientset {
 	var cs Clientset
+	cs.hostpoolV1alpha1 = hostpoolv1alpha1.NewForConfigOrDie(c)
 	cs.machineV1alpha1 = machinev1alpha1.NewForConfigOrDie(c)
 
 	cs.DiscoveryClient = discovery.NewDiscoveryClientForConfigOrDie(c)
@@ -93,6 +114,7 @@ func NewForConfigOrDie(c *rest.Config) *Clientset {
 // New creates a new Clientset for the given RESTClient.
 func New(c rest.Interface) *Clientset {
 	var cs Clientset
+	cs.hostpoolV1alpha1 = hostpoolv1alpha1.New(c)
 	cs.machineV1alpha1 = machinev1alpha1.New(c)
 
 	cs.DiscoveryClient = discovery.NewDiscoveryClient(c)
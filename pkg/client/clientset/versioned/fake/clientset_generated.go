@@ -20,6 +20,8 @@ package fake
 
 import (
 	clientset "github.com/kubermatic/machine-controller/pkg/client/clientset/versioned"
+	hostpoolv1alpha1 "github.com/kubermatic/machine-controller/pkg/client/clientset/versioned/typed/hostpool/v1alpha1"
+	fakehostpoolv1alpha1 "github.com/kubermatic/machine-controller/pkg/client/clientset/versioned/typed/hostpool/v1alpha1/fake"
 	machinev1alpha1 "github.com/kubermatic/machine-controller/pkg/client/clientset/versioned/typed/machines/v1alpha1"
 	fakemachinev1alpha1 "github.com/kubermatic/machine-controller/pkg/client/clientset/versioned/typed/machines/v1alpha1/fake"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -70,6 +72,16 @@ func (c *Clientset) Discovery() discovery.DiscoveryInterface {
 
 var _ clientset.Interface = &Clientset{}
 
+// HostpoolV1alpha1 retrieves the HostpoolV1alpha1Client
+func (c *Clientset) HostpoolV1alpha1() hostpoolv1alpha1.HostpoolV1alpha1Interface {
+	return &fakehostpoolv1alpha1.FakeHostpoolV1alpha1{Fake: &c.Fake}
+}
+
+// Hostpool retrieves the HostpoolV1alpha1Client
+func (c *Clientset) Hostpool() hostpoolv1alpha1.HostpoolV1alpha1Interface {
+	return &fakehostpoolv1alpha1.FakeHostpoolV1alpha1{Fake: &c.Fake}
+}
+
 // MachineV1alpha1 retrieves the MachineV1alpha1Client
 func (c *Clientset) MachineV1alpha1() machinev1alpha1.MachineV1alpha1Interface {
 	return &fakemachinev1alpha1.FakeMachineV1alpha1{Fake: &c.Fake}
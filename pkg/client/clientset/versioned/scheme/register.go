@@ -19,6 +19,7 @@ limitations under the License.
 package scheme
 
 import (
+	hostpoolv1alpha1 "github.com/kubermatic/machine-controller/pkg/hostpool/v1alpha1"
 	machinev1alpha1 "github.com/kubermatic/machine-controller/pkg/machines/v1alpha1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
@@ -50,5 +51,6 @@ func init() {
 // After this, RawExtensions in Kubernetes types will serialize kube-aggregator types
 // correctly.
 func AddToScheme(scheme *runtime.Scheme) {
+	hostpoolv1alpha1.AddToScheme(scheme)
 	machinev1alpha1.AddToScheme(scheme)
 }
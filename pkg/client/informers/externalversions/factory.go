@@ -24,6 +24,7 @@ import (
 	time "time"
 
 	versioned "github.com/kubermatic/machine-controller/pkg/client/clientset/versioned"
+	hostpool "github.com/kubermatic/machine-controller/pkg/client/informers/externalversions/hostpool"
 	internalinterfaces "github.com/kubermatic/machine-controller/pkg/client/informers/externalversions/internalinterfaces"
 	machines "github.com/kubermatic/machine-controller/pkg/client/informers/externalversions/machines"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -123,9 +124,14 @@ type SharedInformerFactory interface {
 	ForResource(resource schema.GroupVersionResource) (GenericInformer, error)
 	WaitForCacheSync(stopCh <-chan struct{}) map[reflect.Type]bool
 
+	Hostpool() hostpool.Interface
 	Machine() machines.Interface
 }
 
+func (f *sharedInformerFactory) Hostpool() hostpool.Interface {
+	return hostpool.New(f, f.namespace, f.tweakListOptions)
+}
+
 func (f *sharedInformerFactory) Machine() machines.Interface {
 	return machines.New(f, f.namespace, f.tweakListOptions)
 }
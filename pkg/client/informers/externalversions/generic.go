@@ -21,7 +21,8 @@ package externalversions
 import (
 	"fmt"
 
-	v1alpha1 "github.com/kubermatic/machine-controller/pkg/machines/v1alpha1"
+	v1alpha1 "github.com/kubermatic/machine-controller/pkg/hostpool/v1alpha1"
+	machines_v1alpha1 "github.com/kubermatic/machine-controller/pkg/machines/v1alpha1"
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
 	cache "k8s.io/client-go/tools/cache"
 )
@@ -52,8 +53,12 @@ func (f *genericInformer) Lister() cache.GenericLister {
 // TODO extend this to unknown resources with a client pool
 func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource) (GenericInformer, error) {
 	switch resource {
-	// Group=machine.k8s.io, Version=v1alpha1
-	case v1alpha1.SchemeGroupVersion.WithResource("machines"):
+	// Group=hostpool.kubermatic.io, Version=v1alpha1
+	case v1alpha1.SchemeGroupVersion.WithResource("hosts"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Hostpool().V1alpha1().Hosts().Informer()}, nil
+
+		// Group=machine.k8s.io, Version=v1alpha1
+	case machines_v1alpha1.SchemeGroupVersion.WithResource("machines"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Machine().V1alpha1().Machines().Informer()}, nil
 
 	}
@@ -49,5 +49,12 @@ type Provider interface {
 	MigrateUID(machine *clusterv1alpha1.Machine, new types.UID) error
 }
 
+// MachineConfigProvider is implemented by providers whose cloud config depends on more than the machine spec,
+// e.g. on the MachineDeployment the machine belongs to
+type MachineConfigProvider interface {
+	// ForMachine returns the provider the userdata of the given machine gets rendered with
+	ForMachine(machine *clusterv1alpha1.Machine) Provider
+}
+
 // MachineUpdater defines a function to persist an update to a machine
 type MachineUpdater func(*clusterv1alpha1.Machine, func(*clusterv1alpha1.Machine)) (*clusterv1alpha1.Machine, error)
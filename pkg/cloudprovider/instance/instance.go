@@ -16,4 +16,7 @@ const (
 	StatusDeleted  Status = "deleted"
 	StatusCreating Status = "creating"
 	StatusUnknown  Status = "unknown"
+	// StatusInterrupted means the instance gets or got interrupted by the cloud provider,
+	// e.g. a reclaimed spot instance. The machine gets replaced.
+	StatusInterrupted Status = "interrupted"
 )
@@ -4,12 +4,15 @@ import (
 	"errors"
 
 	"github.com/kubermatic/machine-controller/pkg/cloudprovider/cloud"
+	"github.com/kubermatic/machine-controller/pkg/cloudprovider/provider/alibaba"
 	"github.com/kubermatic/machine-controller/pkg/cloudprovider/provider/aws"
 	"github.com/kubermatic/machine-controller/pkg/cloudprovider/provider/azure"
 	"github.com/kubermatic/machine-controller/pkg/cloudprovider/provider/digitalocean"
 	"github.com/kubermatic/machine-controller/pkg/cloudprovider/provider/fake"
 	"github.com/kubermatic/machine-controller/pkg/cloudprovider/provider/hetzner"
+	"github.com/kubermatic/machine-controller/pkg/cloudprovider/provider/hostpool"
 	"github.com/kubermatic/machine-controller/pkg/cloudprovider/provider/openstack"
+	"github.com/kubermatic/machine-controller/pkg/cloudprovider/provider/plugin"
 	"github.com/kubermatic/machine-controller/pkg/cloudprovider/provider/vsphere"
 	"github.com/kubermatic/machine-controller/pkg/providerconfig"
 )
@@ -37,9 +40,18 @@ var (
 		providerconfig.CloudProviderAzure: func(cvr *providerconfig.ConfigVarResolver) cloud.Provider {
 			return azure.New(cvr)
 		},
+		providerconfig.CloudProviderAlibaba: func(cvr *providerconfig.ConfigVarResolver) cloud.Provider {
+			return alibaba.New(cvr)
+		},
 		providerconfig.CloudProviderFake: func(cvr *providerconfig.ConfigVarResolver) cloud.Provider {
 			return fake.New(cvr)
 		},
+		providerconfig.CloudProviderPlugin: func(cvr *providerconfig.ConfigVarResolver) cloud.Provider {
+			return plugin.New(cvr)
+		},
+		providerconfig.CloudProviderHostPool: func(cvr *providerconfig.ConfigVarResolver) cloud.Provider {
+			return hostpool.New(cvr)
+		},
 	}
 )
 
@@ -0,0 +1,40 @@
+package alibaba
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// CloudConfig is the config consumed by the Alibaba cloud-controller-manager
+type CloudConfig struct {
+	Global GlobalOpts `json:"global"`
+}
+
+type GlobalOpts struct {
+	// The cloud-controller-manager expects the AccessKey base64 encoded,
+	// CloudConfigToString takes care of the encoding
+	AccessKeyID     string `json:"accessKeyID,omitempty"`
+	AccessKeySecret string `json:"accessKeySecret,omitempty"`
+
+	Region    string `json:"region"`
+	ZoneID    string `json:"zoneid"`
+	VSwitchID string `json:"vswitchid"`
+}
+
+func CloudConfigToString(c *CloudConfig) (string, error) {
+	encoded := *c
+	if c.Global.AccessKeyID != "" {
+		encoded.Global.AccessKeyID = base64.StdEncoding.EncodeToString([]byte(c.Global.AccessKeyID))
+	}
+	if c.Global.AccessKeySecret != "" {
+		encoded.Global.AccessKeySecret = base64.StdEncoding.EncodeToString([]byte(c.Global.AccessKeySecret))
+	}
+
+	b, err := json.Marshal(encoded)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal config: %v", err)
+	}
+
+	return string(b), nil
+}
@@ -0,0 +1,69 @@
+package alibaba
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"testing"
+
+	testhelper "github.com/kubermatic/machine-controller/pkg/test"
+)
+
+var update = flag.Bool("update", false, "update .golden files")
+
+func TestCloudConfigToString(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *CloudConfig
+	}{
+		{
+			name: "simple-config",
+			config: &CloudConfig{
+				Global: GlobalOpts{
+					Region:    "eu-central-1",
+					ZoneID:    "eu-central-1a",
+					VSwitchID: "vsw-gw8g8mn4ohmj483hsylmn",
+				},
+			},
+		},
+		{
+			name: "config-with-access-key",
+			config: &CloudConfig{
+				Global: GlobalOpts{
+					AccessKeyID:     "LTAI4Fu1kBnFMs9C",
+					AccessKeySecret: "vV8UDn3sjvzJQWP9bkOLtYq0AWq2Qv",
+					Region:          "eu-central-1",
+					ZoneID:          "eu-central-1a",
+					VSwitchID:       "vsw-gw8g8mn4ohmj483hsylmn",
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, err := CloudConfigToString(test.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Logf("\n%s", s)
+
+			nc := &CloudConfig{}
+			if err := json.Unmarshal([]byte(s), nc); err != nil {
+				t.Fatalf("failed to load string into config object: %v", err)
+			}
+			for _, key := range []*string{&nc.Global.AccessKeyID, &nc.Global.AccessKeySecret} {
+				decoded, err := base64.StdEncoding.DecodeString(*key)
+				if err != nil {
+					t.Fatalf("expected the AccessKey to be base64 encoded: %v", err)
+				}
+				*key = string(decoded)
+			}
+			if *nc != *test.config {
+				t.Errorf("expected the parsed config %+v to match %+v", *nc, *test.config)
+			}
+
+			testhelper.CompareOutput(t, test.name, s, *update)
+		})
+	}
+}
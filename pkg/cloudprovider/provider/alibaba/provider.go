@@ -0,0 +1,612 @@
+package alibaba
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aliyun/alibaba-cloud-sdk-go/sdk/requests"
+	"github.com/aliyun/alibaba-cloud-sdk-go/services/ecs"
+	"github.com/golang/glog"
+
+	"github.com/kubermatic/machine-controller/pkg/cloudprovider/cloud"
+	cloudprovidererrors "github.com/kubermatic/machine-controller/pkg/cloudprovider/errors"
+	"github.com/kubermatic/machine-controller/pkg/cloudprovider/instance"
+	"github.com/kubermatic/machine-controller/pkg/providerconfig"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	alierrors "github.com/aliyun/alibaba-cloud-sdk-go/sdk/errors"
+	common "sigs.k8s.io/cluster-api/pkg/apis/cluster/common"
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+)
+
+const (
+	machineUIDTag = "Machine-UID"
+
+	instanceResourceType = "instance"
+
+	instanceStatusPending  = "Pending"
+	instanceStatusStarting = "Starting"
+	instanceStatusRunning  = "Running"
+
+	defaultDiskCategory = "cloud_efficiency"
+	defaultDiskSize     = 40
+
+	providerIDScript = `#!/bin/bash
+set -euo pipefail
+
+region_id="$(curl -sSf --retry 10 http://100.100.100.200/latest/meta-data/region-id)"
+instance_id="$(curl -sSf --retry 10 http://100.100.100.200/latest/meta-data/instance-id)"
+echo "${region_id}.${instance_id}"
+`
+)
+
+var (
+	diskCategories = sets.NewString(
+		"cloud",
+		"cloud_efficiency",
+		"cloud_ssd",
+		"cloud_essd",
+	)
+
+	// imageNames are the name patterns of the public system images
+	// we use for the given operating system
+	imageNames = map[providerconfig.OperatingSystem]string{
+		providerconfig.OperatingSystemCoreos: "coreos_*_64_*",
+		providerconfig.OperatingSystemCentOS: "centos_7_*_64_*",
+		providerconfig.OperatingSystemUbuntu: "ubuntu_18_04_64_*",
+	}
+)
+
+type provider struct {
+	configVarResolver *providerconfig.ConfigVarResolver
+}
+
+// New returns an Alibaba cloud provider
+func New(configVarResolver *providerconfig.ConfigVarResolver) cloud.Provider {
+	return &provider{configVarResolver: configVarResolver}
+}
+
+type RawConfig struct {
+	AccessKeyID     providerconfig.ConfigVarString `json:"accessKeyID"`
+	AccessKeySecret providerconfig.ConfigVarString `json:"accessKeySecret"`
+
+	RegionID providerconfig.ConfigVarString `json:"regionID"`
+	ZoneID   providerconfig.ConfigVarString `json:"zoneID"`
+
+	VSwitchID        providerconfig.ConfigVarString   `json:"vSwitchID"`
+	SecurityGroupIDs []providerconfig.ConfigVarString `json:"securityGroupIDs"`
+
+	InstanceType providerconfig.ConfigVarString `json:"instanceType"`
+	ImageID      providerconfig.ConfigVarString `json:"imageID"`
+	DiskCategory providerconfig.ConfigVarString `json:"diskCategory"`
+	DiskSize     int                            `json:"diskSize"`
+	Tags         map[string]string              `json:"tags"`
+}
+
+type Config struct {
+	AccessKeyID     string
+	AccessKeySecret string
+
+	RegionID string
+	ZoneID   string
+
+	VSwitchID        string
+	SecurityGroupIDs []string
+
+	InstanceType string
+	ImageID      string
+	DiskCategory string
+	DiskSize     int
+	Tags         map[string]string
+}
+
+func (p *provider) getConfig(s v1alpha1.ProviderConfig) (*Config, *providerconfig.Config, error) {
+	if s.Value == nil {
+		return nil, nil, fmt.Errorf("machine.spec.providerconfig.value is nil")
+	}
+	pconfig := providerconfig.Config{}
+	err := json.Unmarshal(s.Value.Raw, &pconfig)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rawConfig := RawConfig{}
+	if err = json.Unmarshal(pconfig.CloudProviderSpec.Raw, &rawConfig); err != nil {
+		return nil, nil, err
+	}
+
+	c := Config{}
+	c.AccessKeyID, err = p.configVarResolver.GetConfigVarStringValueOrEnv(rawConfig.AccessKeyID, "ALIBABA_ACCESS_KEY_ID")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get the value of \"accessKeyID\" field, error = %v", err)
+	}
+	c.AccessKeySecret, err = p.configVarResolver.GetConfigVarStringValueOrEnv(rawConfig.AccessKeySecret, "ALIBABA_ACCESS_KEY_SECRET")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get the value of \"accessKeySecret\" field, error = %v", err)
+	}
+	c.RegionID, err = p.configVarResolver.GetConfigVarStringValue(rawConfig.RegionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	c.ZoneID, err = p.configVarResolver.GetConfigVarStringValue(rawConfig.ZoneID)
+	if err != nil {
+		return nil, nil, err
+	}
+	c.VSwitchID, err = p.configVarResolver.GetConfigVarStringValue(rawConfig.VSwitchID)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, securityGroupIDRaw := range rawConfig.SecurityGroupIDs {
+		securityGroupID, err := p.configVarResolver.GetConfigVarStringValue(securityGroupIDRaw)
+		if err != nil {
+			return nil, nil, err
+		}
+		c.SecurityGroupIDs = append(c.SecurityGroupIDs, securityGroupID)
+	}
+	c.InstanceType, err = p.configVarResolver.GetConfigVarStringValue(rawConfig.InstanceType)
+	if err != nil {
+		return nil, nil, err
+	}
+	c.ImageID, err = p.configVarResolver.GetConfigVarStringValue(rawConfig.ImageID)
+	if err != nil {
+		return nil, nil, err
+	}
+	c.DiskCategory, err = p.configVarResolver.GetConfigVarStringValue(rawConfig.DiskCategory)
+	if err != nil {
+		return nil, nil, err
+	}
+	if c.DiskCategory == "" {
+		c.DiskCategory = defaultDiskCategory
+	}
+	c.DiskSize = rawConfig.DiskSize
+	if c.DiskSize == 0 {
+		c.DiskSize = defaultDiskSize
+	}
+	c.Tags = rawConfig.Tags
+
+	return &c, &pconfig, nil
+}
+
+func getClient(regionID, accessKeyID, accessKeySecret string) (*ecs.Client, error) {
+	client, err := ecs.NewClientWithAccessKey(regionID, accessKeyID, accessKeySecret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ecs client: %v", err)
+	}
+	return client, nil
+}
+
+func getDefaultImageID(client *ecs.Client, os providerconfig.OperatingSystem, regionID string) (string, error) {
+	imageName, osSupported := imageNames[os]
+	if !osSupported {
+		return "", fmt.Errorf("operating system %q not supported", os)
+	}
+
+	request := ecs.CreateDescribeImagesRequest()
+	request.RegionId = regionID
+	request.ImageOwnerAlias = "system"
+	request.ImageName = imageName
+	request.OSType = "linux"
+	request.Architecture = "x86_64"
+	request.Status = "Available"
+	request.PageSize = requests.NewInteger(100)
+
+	response, err := client.DescribeImages(request)
+	if err != nil {
+		return "", err
+	}
+
+	if len(response.Images.Image) == 0 {
+		return "", fmt.Errorf("could not find image for '%s'", os)
+	}
+
+	image := response.Images.Image[0]
+	for _, v := range response.Images.Image {
+		itime, _ := time.Parse(time.RFC3339, image.CreationTime)
+		vtime, _ := time.Parse(time.RFC3339, v.CreationTime)
+		if vtime.After(itime) {
+			image = v
+		}
+	}
+
+	return image.ImageId, nil
+}
+
+func (p *provider) AddDefaults(spec v1alpha1.MachineSpec) (v1alpha1.MachineSpec, bool, error) {
+	return spec, false, nil
+}
+
+func (p *provider) Validate(spec v1alpha1.MachineSpec) error {
+	c, pc, err := p.getConfig(spec.ProviderConfig)
+	if err != nil {
+		return fmt.Errorf("failed to parse config: %v", err)
+	}
+
+	if c.AccessKeyID == "" {
+		return errors.New("accessKeyID is missing")
+	}
+	if c.AccessKeySecret == "" {
+		return errors.New("accessKeySecret is missing")
+	}
+	if c.RegionID == "" {
+		return errors.New("regionID is missing")
+	}
+	if c.ZoneID == "" {
+		return errors.New("zoneID is missing")
+	}
+	if c.VSwitchID == "" {
+		return errors.New("vSwitchID is missing")
+	}
+	if c.InstanceType == "" {
+		return errors.New("instanceType is missing")
+	}
+	if len(c.SecurityGroupIDs) == 0 {
+		return errors.New("securityGroupIDs are missing")
+	}
+
+	if !diskCategories.Has(c.DiskCategory) {
+		return fmt.Errorf("invalid disk category %s specified. Supported: %s", c.DiskCategory, diskCategories.List())
+	}
+
+	if _, osSupported := imageNames[pc.OperatingSystem]; !osSupported {
+		return fmt.Errorf("unsupported os %s", pc.OperatingSystem)
+	}
+
+	client, err := getClient(c.RegionID, c.AccessKeyID, c.AccessKeySecret)
+	if err != nil {
+		return err
+	}
+
+	zonesRequest := ecs.CreateDescribeZonesRequest()
+	zonesRequest.RegionId = c.RegionID
+	zonesResponse, err := client.DescribeZones(zonesRequest)
+	if err != nil {
+		return fmt.Errorf("invalid region %q specified: %v", c.RegionID, err)
+	}
+	var zoneFound bool
+	for _, zone := range zonesResponse.Zones.Zone {
+		if zone.ZoneId == c.ZoneID {
+			zoneFound = true
+			break
+		}
+	}
+	if !zoneFound {
+		return fmt.Errorf("invalid zone %q specified", c.ZoneID)
+	}
+
+	if c.ImageID != "" {
+		imagesRequest := ecs.CreateDescribeImagesRequest()
+		imagesRequest.RegionId = c.RegionID
+		imagesRequest.ImageId = c.ImageID
+		imagesResponse, err := client.DescribeImages(imagesRequest)
+		if err != nil {
+			return fmt.Errorf("failed to validate image: %v", err)
+		}
+		if len(imagesResponse.Images.Image) == 0 {
+			return fmt.Errorf("image %q not found", c.ImageID)
+		}
+	}
+
+	for _, securityGroupID := range c.SecurityGroupIDs {
+		sgRequest := ecs.CreateDescribeSecurityGroupsRequest()
+		sgRequest.RegionId = c.RegionID
+		sgRequest.SecurityGroupIds = fmt.Sprintf("[%q]", securityGroupID)
+		sgResponse, err := client.DescribeSecurityGroups(sgRequest)
+		if err != nil {
+			return fmt.Errorf("failed to validate security group %q: %v", securityGroupID, err)
+		}
+		if len(sgResponse.SecurityGroups.SecurityGroup) == 0 {
+			return fmt.Errorf("security group %q not found", securityGroupID)
+		}
+	}
+
+	return nil
+}
+
+func (p *provider) Create(machine *v1alpha1.Machine, _ cloud.MachineUpdater, userdata string) (instance.Instance, error) {
+	c, pc, err := p.getConfig(machine.Spec.ProviderConfig)
+	if err != nil {
+		return nil, cloudprovidererrors.TerminalError{
+			Reason:  common.InvalidConfigurationMachineError,
+			Message: fmt.Sprintf("Failed to parse MachineSpec, due to %v", err),
+		}
+	}
+
+	client, err := getClient(c.RegionID, c.AccessKeyID, c.AccessKeySecret)
+	if err != nil {
+		return nil, err
+	}
+
+	imageID := c.ImageID
+	if imageID == "" {
+		if imageID, err = getDefaultImageID(client, pc.OperatingSystem, c.RegionID); err != nil {
+			return nil, cloudprovidererrors.TerminalError{
+				Reason:  common.InvalidConfigurationMachineError,
+				Message: fmt.Sprintf("Invalid Region and Operating System configuration: %v", err),
+			}
+		}
+	}
+
+	tags := []ecs.RunInstancesTag{
+		{
+			Key:   machineUIDTag,
+			Value: string(machine.UID),
+		},
+	}
+	for k, v := range c.Tags {
+		tags = append(tags, ecs.RunInstancesTag{
+			Key:   k,
+			Value: v,
+		})
+	}
+
+	request := ecs.CreateRunInstancesRequest()
+	request.RegionId = c.RegionID
+	request.ZoneId = c.ZoneID
+	request.InstanceName = machine.Spec.Name
+	request.HostName = machine.Spec.Name
+	request.InstanceType = c.InstanceType
+	request.ImageId = imageID
+	request.VSwitchId = c.VSwitchID
+	request.SystemDiskCategory = c.DiskCategory
+	request.SystemDiskSize = strconv.Itoa(c.DiskSize)
+	request.UserData = base64.StdEncoding.EncodeToString([]byte(userdata))
+	request.Tag = &tags
+	request.Amount = requests.NewInteger(1)
+	request.SecurityGroupIds = &c.SecurityGroupIDs
+
+	response, err := client.RunInstances(request)
+	if err != nil {
+		return nil, aliErrorToTerminalError(err, "failed to create instance at alibaba cloud")
+	}
+	if len(response.InstanceIdSets.InstanceIdSet) != 1 {
+		return nil, fmt.Errorf("expected exactly one instance to be created, got %d", len(response.InstanceIdSets.InstanceIdSet))
+	}
+	glog.V(4).Infof("Created instance %s for machine %s", response.InstanceIdSets.InstanceIdSet[0], machine.Spec.Name)
+
+	return p.Get(machine)
+}
+
+func (p *provider) Delete(machine *v1alpha1.Machine, _ cloud.MachineUpdater) error {
+	instance, err := p.Get(machine)
+	if err != nil {
+		if err == cloudprovidererrors.ErrInstanceNotFound {
+			return nil
+		}
+		return err
+	}
+
+	c, _, err := p.getConfig(machine.Spec.ProviderConfig)
+	if err != nil {
+		return cloudprovidererrors.TerminalError{
+			Reason:  common.InvalidConfigurationMachineError,
+			Message: fmt.Sprintf("Failed to parse MachineSpec, due to %v", err),
+		}
+	}
+
+	client, err := getClient(c.RegionID, c.AccessKeyID, c.AccessKeySecret)
+	if err != nil {
+		return err
+	}
+
+	request := ecs.CreateDeleteInstanceRequest()
+	request.InstanceId = instance.ID()
+	request.Force = requests.NewBoolean(true)
+	if _, err = client.DeleteInstance(request); err != nil {
+		return aliErrorToTerminalError(err, "failed to delete instance")
+	}
+	glog.V(4).Infof("Successfully triggered deletion of instance %s at alibaba cloud", instance.ID())
+
+	return nil
+}
+
+func (p *provider) Get(machine *v1alpha1.Machine) (instance.Instance, error) {
+	c, _, err := p.getConfig(machine.Spec.ProviderConfig)
+	if err != nil {
+		return nil, cloudprovidererrors.TerminalError{
+			Reason:  common.InvalidConfigurationMachineError,
+			Message: fmt.Sprintf("Failed to parse MachineSpec, due to %v", err),
+		}
+	}
+
+	client, err := getClient(c.RegionID, c.AccessKeyID, c.AccessKeySecret)
+	if err != nil {
+		return nil, err
+	}
+
+	ecsInstance, err := getInstanceByTag(client, c.RegionID, machineUIDTag, string(machine.UID))
+	if err != nil {
+		return nil, err
+	}
+
+	return &alibabaInstance{instance: ecsInstance}, nil
+}
+
+func getInstanceByTag(client *ecs.Client, regionID, key, value string) (*ecs.Instance, error) {
+	request := ecs.CreateDescribeInstancesRequest()
+	request.RegionId = regionID
+	request.Tag = &[]ecs.DescribeInstancesTag{
+		{
+			Key:   key,
+			Value: value,
+		},
+	}
+
+	response, err := client.DescribeInstances(request)
+	if err != nil {
+		return nil, aliErrorToTerminalError(err, "failed to list instances from alibaba cloud")
+	}
+
+	for i := range response.Instances.Instance {
+		if getTagValue(key, response.Instances.Instance[i].Tags.Tag) == value {
+			return &response.Instances.Instance[i], nil
+		}
+	}
+
+	return nil, cloudprovidererrors.ErrInstanceNotFound
+}
+
+func (p *provider) GetCloudConfig(spec v1alpha1.MachineSpec) (config string, name string, err error) {
+	c, _, err := p.getConfig(spec.ProviderConfig)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to parse config: %v", err)
+	}
+
+	// The AccessKey is left out on purpose: the kubelet doesn't read the config of an external
+	// cloud provider and the cloud-controller-manager gets configured with its own credentials
+	cc := &CloudConfig{
+		Global: GlobalOpts{
+			Region:    c.RegionID,
+			ZoneID:    c.ZoneID,
+			VSwitchID: c.VSwitchID,
+		},
+	}
+
+	s, err := CloudConfigToString(cc)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to convert cloud-config to string: %v", err)
+	}
+
+	// The Alibaba cloud provider is not part of the kubelet,
+	// the config gets consumed by the external cloud-controller-manager
+	return s, "external", nil
+}
+
+// GetProviderIDScript returns a script which prints the provider id of the instance. The
+// cloud-controller-manager identifies the nodes by <region-id>.<instance-id>, which only
+// the metadata service of the instance knows
+func (p *provider) GetProviderIDScript(spec v1alpha1.MachineSpec) (string, error) {
+	return providerIDScript, nil
+}
+
+func (p *provider) MachineMetricsLabels(machine *v1alpha1.Machine) (map[string]string, error) {
+	labels := make(map[string]string)
+
+	c, _, err := p.getConfig(machine.Spec.ProviderConfig)
+	if err == nil {
+		labels["size"] = c.InstanceType
+		labels["region"] = c.RegionID
+		labels["zone"] = c.ZoneID
+		labels["image"] = c.ImageID
+	}
+
+	return labels, err
+}
+
+func (p *provider) MigrateUID(machine *v1alpha1.Machine, new types.UID) error {
+	instance, err := p.Get(machine)
+	if err != nil {
+		if err == cloudprovidererrors.ErrInstanceNotFound {
+			return nil
+		}
+		return fmt.Errorf("failed to get instance: %v", err)
+	}
+
+	c, _, err := p.getConfig(machine.Spec.ProviderConfig)
+	if err != nil {
+		return cloudprovidererrors.TerminalError{
+			Reason:  common.InvalidConfigurationMachineError,
+			Message: fmt.Sprintf("Failed to parse MachineSpec, due to %v", err),
+		}
+	}
+
+	client, err := getClient(c.RegionID, c.AccessKeyID, c.AccessKeySecret)
+	if err != nil {
+		return err
+	}
+
+	request := ecs.CreateAddTagsRequest()
+	request.RegionId = c.RegionID
+	request.ResourceType = instanceResourceType
+	request.ResourceId = instance.ID()
+	request.Tag = &[]ecs.AddTagsTag{{Key: machineUIDTag, Value: string(new)}}
+	if _, err = client.AddTags(request); err != nil {
+		return fmt.Errorf("failed to update instance with new machineUIDTag: %v", err)
+	}
+
+	return nil
+}
+
+type alibabaInstance struct {
+	instance *ecs.Instance
+}
+
+func (a *alibabaInstance) Name() string {
+	return a.instance.InstanceName
+}
+
+func (a *alibabaInstance) ID() string {
+	return a.instance.InstanceId
+}
+
+func (a *alibabaInstance) Addresses() []string {
+	var addresses []string
+	addresses = append(addresses, a.instance.PublicIpAddress.IpAddress...)
+	if a.instance.EipAddress.IpAddress != "" {
+		addresses = append(addresses, a.instance.EipAddress.IpAddress)
+	}
+	addresses = append(addresses, a.instance.VpcAttributes.PrivateIpAddress.IpAddress...)
+	return append(addresses, a.instance.InnerIpAddress.IpAddress...)
+}
+
+func (a *alibabaInstance) Status() instance.Status {
+	switch a.instance.Status {
+	case instanceStatusPending, instanceStatusStarting:
+		return instance.StatusCreating
+	case instanceStatusRunning:
+		return instance.StatusRunning
+	default:
+		return instance.StatusUnknown
+	}
+}
+
+func getTagValue(key string, tags []ecs.Tag) string {
+	for _, t := range tags {
+		if t.TagKey == key {
+			return t.TagValue
+		}
+	}
+	return ""
+}
+
+// aliErrorToTerminalError judges if the given error
+// can be qualified as a "terminal" error, for more info see v1alpha1.MachineStatus
+//
+// if the given error doesn't qualify the error passed as
+// an argument will be formatted according to msg and returned
+func aliErrorToTerminalError(err error, msg string) error {
+	prepareAndReturnError := func() error {
+		return fmt.Errorf("%s, due to %s", msg, err)
+	}
+
+	if err != nil {
+		serverErr, ok := err.(*alierrors.ServerError)
+		if !ok {
+			return prepareAndReturnError()
+		}
+		code := serverErr.ErrorCode()
+		switch {
+		case code == "InvalidAccessKeyId.NotFound" || code == "SignatureDoesNotMatch" || strings.HasPrefix(code, "Forbidden"):
+			// authorization primitives come from MachineSpec
+			// thus we are setting InvalidConfigurationMachineError
+			return cloudprovidererrors.TerminalError{
+				Reason:  common.InvalidConfigurationMachineError,
+				Message: "A request has been rejected due to invalid credentials which were taken from the MachineSpec",
+			}
+		case strings.HasPrefix(code, "QuotaExceed"):
+			return cloudprovidererrors.TerminalError{
+				Reason:  common.InsufficientResourcesMachineError,
+				Message: "You've reached the Alibaba cloud quota for number of instances of this type",
+			}
+		default:
+			return prepareAndReturnError()
+		}
+	}
+	return err
+}
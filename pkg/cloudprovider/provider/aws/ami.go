@@ -0,0 +1,301 @@
+package aws
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/golang/glog"
+
+	"github.com/kubermatic/machine-controller/pkg/providerconfig"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// amiCacheTTL is the time a resolved ami gets reused, so new releases get picked up without
+// querying aws for every created instance
+const amiCacheTTL = time.Hour
+
+var (
+	architectures = sets.NewString(ec2.ArchitectureValuesX8664, ec2.ArchitectureValuesArm64)
+
+	defaultAMIs = map[providerconfig.OperatingSystem]defaultAMI{
+		providerconfig.OperatingSystemCoreos: {
+			filter: amiFilter{
+				description: "CoreOS Container Linux stable*",
+				// The AWS marketplace ID from CoreOS
+				owners: []string{"595879546273"},
+			},
+		},
+		providerconfig.OperatingSystemCentOS: {
+			filter: amiFilter{
+				description: "CentOS Linux 7 x86_64 HVM EBS*",
+				// The AWS marketplace ID from AWS
+				owners: []string{"679593333241"},
+			},
+		},
+		providerconfig.OperatingSystemUbuntu: {
+			// Public parameter from Canonical, which always points to the current release
+			parameter: "/aws/service/canonical/ubuntu/server/18.04/stable/current/{{arch}}/hvm/ebs-gp2/ami-id",
+			parameterArchitectures: map[string]string{
+				ec2.ArchitectureValuesX8664: "amd64",
+				ec2.ArchitectureValuesArm64: "arm64",
+			},
+		},
+	}
+
+	amiCacheLock sync.Mutex
+	// amiCache holds the resolved amis per account, region, source and architecture,
+	// as well as the architectures of the instance types
+	amiCache = map[amiCacheKey]amiCacheEntry{}
+)
+
+// AMIFilter selects the latest image which matches the owners and the name
+type AMIFilter struct {
+	// Owners are account ids or aliases like "amazon", "aws-marketplace" or "self"
+	Owners []string `json:"owners,omitempty"`
+	// Name may contain wildcards, e.g. "ubuntu/images/hvm-ssd/ubuntu-bionic-18.04-*"
+	Name string `json:"name,omitempty"`
+}
+
+type amiFilter struct {
+	owners      []string
+	name        string
+	description string
+}
+
+type defaultAMI struct {
+	// parameter is the name of a ssm parameter with the ami id, "{{arch}}" gets replaced by the
+	// name of the architecture in parameterArchitectures. The filter gets used without a parameter.
+	parameter              string
+	parameterArchitectures map[string]string
+	filter                 amiFilter
+}
+
+// amiCacheKey identifies a cached ami or instance type architecture. The credentials are part of the key,
+// as private parameters and images shared with an account differ between the accounts.
+type amiCacheKey struct {
+	accessKeyID   string
+	assumeRoleARN string
+	region        string
+
+	parameter string
+
+	owners      string
+	name        string
+	description string
+
+	instanceType string
+	architecture string
+}
+
+func newAMICacheKey(c *Config) amiCacheKey {
+	return amiCacheKey{accessKeyID: c.AccessKeyID, assumeRoleARN: c.AssumeRoleARN, region: c.Region}
+}
+
+type amiCacheEntry struct {
+	id      string
+	expires time.Time
+}
+
+func validateAMI(c *Config) error {
+	sources := 0
+	for _, set := range []bool{c.AMI != "", c.AMIParameter != "", c.AMIFilter != nil} {
+		if set {
+			sources++
+		}
+	}
+	if sources > 1 {
+		return fmt.Errorf("only one of ami, amiParameter and amiFilter can be specified")
+	}
+	if c.AMIFilter != nil && len(c.AMIFilter.Owners) == 0 && c.AMIFilter.Name == "" {
+		return fmt.Errorf("the ami filter needs owners or a name")
+	}
+	if c.Architecture != "" && !architectures.Has(c.Architecture) {
+		return fmt.Errorf("invalid architecture %s specified. Supported: %s", c.Architecture, architectures)
+	}
+	return nil
+}
+
+func getCachedAMI(key amiCacheKey) (string, bool) {
+	amiCacheLock.Lock()
+	defer amiCacheLock.Unlock()
+
+	entry, ok := amiCache[key]
+	if !ok || time.Now().After(entry.expires) {
+		return "", false
+	}
+	return entry.id, true
+}
+
+func setCachedAMI(key amiCacheKey, id string) {
+	amiCacheLock.Lock()
+	defer amiCacheLock.Unlock()
+
+	amiCache[key] = amiCacheEntry{id: id, expires: time.Now().Add(amiCacheTTL)}
+}
+
+// getAMIID returns the ami of the amiParameter, the amiFilter or the default of the operating system,
+// matching the architecture of the instance type
+func getAMIID(c *Config, os providerconfig.OperatingSystem, client *ec2.EC2) (string, error) {
+	architecture := c.Architecture
+	if architecture == "" {
+		architecture = getInstanceTypeArchitecture(client, c)
+	}
+
+	var (
+		parameter string
+		filter    amiFilter
+	)
+	switch {
+	case c.AMIParameter != "":
+		parameter = c.AMIParameter
+	case c.AMIFilter != nil:
+		filter = amiFilter{owners: c.AMIFilter.Owners, name: c.AMIFilter.Name}
+	default:
+		defaults, osSupported := defaultAMIs[os]
+		if !osSupported {
+			return "", fmt.Errorf("operating system %q not supported", os)
+		}
+		if defaults.parameter != "" {
+			parameterArchitecture, ok := defaults.parameterArchitectures[architecture]
+			if !ok {
+				return "", fmt.Errorf("no image available for operating system %q and architecture %s", os, architecture)
+			}
+			parameter = strings.Replace(defaults.parameter, "{{arch}}", parameterArchitecture, -1)
+		}
+		filter = defaults.filter
+	}
+
+	if parameter != "" {
+		key := newAMICacheKey(c)
+		key.parameter = parameter
+		if id, ok := getCachedAMI(key); ok {
+			return id, nil
+		}
+		id, err := getAMIParameter(c, parameter)
+		if err != nil {
+			return "", err
+		}
+		glog.V(4).Infof("Resolved ami %s from parameter %s in region %s", id, parameter, c.Region)
+		setCachedAMI(key, id)
+		return id, nil
+	}
+
+	key := newAMICacheKey(c)
+	key.owners = strings.Join(filter.owners, ",")
+	key.name = filter.name
+	key.description = filter.description
+	key.architecture = architecture
+	if id, ok := getCachedAMI(key); ok {
+		return id, nil
+	}
+	id, err := getLatestAMI(client, filter, architecture)
+	if err != nil {
+		return "", err
+	}
+	glog.V(4).Infof("Resolved ami %s for architecture %s in region %s", id, architecture, c.Region)
+	setCachedAMI(key, id)
+	return id, nil
+}
+
+func getAMIParameter(c *Config, name string) (string, error) {
+	client, err := getSSMclient(c)
+	if err != nil {
+		return "", err
+	}
+	out, err := client.GetParameter(&ssm.GetParameterInput{Name: aws.String(name)})
+	if err != nil {
+		return "", fmt.Errorf("failed to get ami parameter %s: %v", name, err)
+	}
+	if out.Parameter == nil || aws.StringValue(out.Parameter.Value) == "" {
+		return "", fmt.Errorf("ami parameter %s is empty", name)
+	}
+	return aws.StringValue(out.Parameter.Value), nil
+}
+
+func getLatestAMI(client *ec2.EC2, filter amiFilter, architecture string) (string, error) {
+	filters := []*ec2.Filter{
+		{
+			Name:   aws.String("architecture"),
+			Values: aws.StringSlice([]string{architecture}),
+		},
+		{
+			Name:   aws.String("virtualization-type"),
+			Values: aws.StringSlice([]string{"hvm"}),
+		},
+		{
+			Name:   aws.String("root-device-type"),
+			Values: aws.StringSlice([]string{"ebs"}),
+		},
+		{
+			Name:   aws.String("state"),
+			Values: aws.StringSlice([]string{ec2.ImageStateAvailable}),
+		},
+	}
+	if filter.name != "" {
+		filters = append(filters, &ec2.Filter{Name: aws.String("name"), Values: aws.StringSlice([]string{filter.name})})
+	}
+	if filter.description != "" {
+		filters = append(filters, &ec2.Filter{Name: aws.String("description"), Values: aws.StringSlice([]string{filter.description})})
+	}
+
+	input := &ec2.DescribeImagesInput{Filters: filters}
+	if len(filter.owners) > 0 {
+		input.Owners = aws.StringSlice(filter.owners)
+	}
+	imagesOut, err := client.DescribeImages(input)
+	if err != nil {
+		return "", err
+	}
+
+	if len(imagesOut.Images) == 0 {
+		return "", fmt.Errorf("could not find an image for architecture %s", architecture)
+	}
+
+	image := imagesOut.Images[0]
+	for _, v := range imagesOut.Images {
+		itime, _ := time.Parse(time.RFC3339, *image.CreationDate)
+		vtime, _ := time.Parse(time.RFC3339, *v.CreationDate)
+		if vtime.After(itime) {
+			image = v
+		}
+	}
+
+	return *image.ImageId, nil
+}
+
+// getInstanceTypeArchitecture returns the architecture of the instance type, preferring x86_64
+// for types which support multiple ones. It falls back to x86_64 when the instance type can't be described.
+func getInstanceTypeArchitecture(client *ec2.EC2, c *Config) string {
+	instanceType := c.InstanceType
+	if instanceType == "" {
+		return ec2.ArchitectureValuesX8664
+	}
+
+	key := newAMICacheKey(c)
+	key.instanceType = instanceType
+	if architecture, ok := getCachedAMI(key); ok {
+		return architecture
+	}
+
+	out, err := client.DescribeInstanceTypes(&ec2.DescribeInstanceTypesInput{
+		InstanceTypes: aws.StringSlice([]string{instanceType}),
+	})
+	if err != nil || len(out.InstanceTypes) != 1 || out.InstanceTypes[0].ProcessorInfo == nil {
+		glog.V(2).Infof("Failed to get the architecture of instance type %s, assuming %s: %v", instanceType, ec2.ArchitectureValuesX8664, err)
+		return ec2.ArchitectureValuesX8664
+	}
+
+	supported := sets.NewString(aws.StringValueSlice(out.InstanceTypes[0].ProcessorInfo.SupportedArchitectures)...)
+	architecture := ec2.ArchitectureValuesX8664
+	if !supported.Has(ec2.ArchitectureValuesX8664) && supported.Has(ec2.ArchitectureValuesArm64) {
+		architecture = ec2.ArchitectureValuesArm64
+	}
+	setCachedAMI(key, architecture)
+	return architecture
+}
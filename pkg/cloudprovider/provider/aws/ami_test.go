@@ -0,0 +1,73 @@
+package aws
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAMICache(t *testing.T) {
+	key := newAMICacheKey(&Config{AccessKeyID: "AKIAEXAMPLE", Region: "eu-central-1"})
+	key.parameter = "test"
+	setCachedAMI(key, "ami-123")
+	if id, ok := getCachedAMI(key); !ok || id != "ami-123" {
+		t.Errorf("expected cached ami ami-123, got %q", id)
+	}
+
+	otherRegion := key
+	otherRegion.region = "us-east-1"
+	if _, ok := getCachedAMI(otherRegion); ok {
+		t.Errorf("expected no cached ami for another region")
+	}
+	otherAccount := key
+	otherAccount.accessKeyID = "AKIAOTHER"
+	if _, ok := getCachedAMI(otherAccount); ok {
+		t.Errorf("expected no cached ami for another account")
+	}
+	otherRole := key
+	otherRole.assumeRoleARN = "arn:aws:iam::123456789012:role/other"
+	if _, ok := getCachedAMI(otherRole); ok {
+		t.Errorf("expected no cached ami for another role")
+	}
+	filter := key
+	filter.parameter = ""
+	filter.name = "test"
+	if _, ok := getCachedAMI(filter); ok {
+		t.Errorf("expected no cached ami for a filter with the name of the parameter")
+	}
+
+	amiCacheLock.Lock()
+	amiCache[key] = amiCacheEntry{id: "ami-123", expires: time.Now().Add(-time.Second)}
+	amiCacheLock.Unlock()
+	if _, ok := getCachedAMI(key); ok {
+		t.Errorf("expected the expired ami not to be used")
+	}
+}
+
+func TestValidateAMI(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		valid  bool
+	}{
+		{name: "default", config: Config{}, valid: true},
+		{name: "ami", config: Config{AMI: "ami-123"}, valid: true},
+		{name: "parameter", config: Config{AMIParameter: "/aws/service/ami"}, valid: true},
+		{name: "filter", config: Config{AMIFilter: &AMIFilter{Owners: []string{"self"}}}, valid: true},
+		{name: "empty filter", config: Config{AMIFilter: &AMIFilter{}}, valid: false},
+		{name: "ami and parameter", config: Config{AMI: "ami-123", AMIParameter: "/aws/service/ami"}, valid: false},
+		{name: "arm64", config: Config{Architecture: "arm64"}, valid: true},
+		{name: "unknown architecture", config: Config{Architecture: "sparc"}, valid: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := validateAMI(&test.config)
+			if test.valid && err != nil {
+				t.Errorf("expected config to be valid, got %v", err)
+			}
+			if !test.valid && err == nil {
+				t.Errorf("expected config to be invalid")
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package aws
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+
+	"github.com/kubermatic/machine-controller/pkg/ini"
+
+	"github.com/Masterminds/sprig"
+)
+
+const (
+	cloudConfigTpl = `[Global]
+Zone                = {{ .Global.Zone | iniEscape }}
+RoleARN             = {{ .Global.RoleARN | iniEscape }}
+KubernetesClusterID = {{ .Global.KubernetesClusterID | iniEscape }}
+`
+)
+
+// GlobalOpts only contains what the cloud provider of the kubelet needs.
+// VPC and SubnetID are left out on purpose, setting them makes the cloud provider assume it runs outside of aws.
+type GlobalOpts struct {
+	Zone string
+	// RoleARN is the role the cloud provider of the kubelet assumes
+	RoleARN string
+	// KubernetesClusterID is the id of the cluster the instance is tagged with
+	KubernetesClusterID string
+}
+
+// CloudConfig is used to read and store information from the cloud configuration file
+type CloudConfig struct {
+	Global GlobalOpts
+}
+
+func CloudConfigToString(c *CloudConfig) (string, error) {
+	funcMap := sprig.TxtFuncMap()
+	funcMap["iniEscape"] = ini.Escape
+
+	tpl, err := template.New("cloud-config").Funcs(funcMap).Parse(cloudConfigTpl)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse the cloud config template: %v", err)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := tpl.Execute(buf, c); err != nil {
+		return "", fmt.Errorf("failed to execute cloud config template: %v", err)
+	}
+
+	return buf.String(), nil
+}
@@ -0,0 +1,59 @@
+package aws
+
+import (
+	"flag"
+	"testing"
+
+	"gopkg.in/gcfg.v1"
+
+	testhelper "github.com/kubermatic/machine-controller/pkg/test"
+)
+
+var update = flag.Bool("update", false, "update .golden files")
+
+func TestCloudConfigToString(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *CloudConfig
+	}{
+		{
+			name: "simple-config",
+			config: &CloudConfig{
+				Global: GlobalOpts{
+					Zone:                "eu-central-1a",
+					KubernetesClusterID: "my-cluster",
+				},
+			},
+		},
+		{
+			name: "config-with-role",
+			config: &CloudConfig{
+				Global: GlobalOpts{
+					Zone:                "eu-central-1a",
+					RoleARN:             `arn:aws:iam::123456789012:role/"nodes"`,
+					KubernetesClusterID: "my-cluster",
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, err := CloudConfigToString(test.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Logf("\n%s", s)
+
+			nc := &CloudConfig{}
+			if err := gcfg.ReadStringInto(nc, s); err != nil {
+				t.Fatalf("failed to load string into config object: %v", err)
+			}
+			if *nc != *test.config {
+				t.Errorf("expected the parsed config %+v to match %+v", *nc, *test.config)
+			}
+
+			testhelper.CompareOutput(t, test.name, s, *update)
+		})
+	}
+}
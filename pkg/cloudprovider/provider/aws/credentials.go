@@ -0,0 +1,104 @@
+package aws
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sts"
+)
+
+const (
+	roleSessionName = "machine-controller"
+	// credentialsExpiryWindow refreshes assumed credentials before they expire,
+	// so requests which are in flight don't fail
+	credentialsExpiryWindow = 5 * time.Minute
+)
+
+var (
+	credentialsCacheLock sync.Mutex
+	// credentialsCache holds the credentials per access key and role. The provider gets created for every call,
+	// so without the cache every call would assume the role again. The credentials refresh themselves.
+	credentialsCache = map[string]*credentials.Credentials{}
+)
+
+func validateCredentials(c *Config) error {
+	if (c.AccessKeyID == "") != (c.SecretAccessKey == "") {
+		return fmt.Errorf("either both or none of the access key id and secret access key must be specified")
+	}
+	if c.AssumeRoleARN == "" {
+		if c.AssumeRoleExternalID != "" || c.WebIdentityTokenFile != "" {
+			return fmt.Errorf("an external id or web identity token file requires a role arn to assume")
+		}
+		return nil
+	}
+	if c.WebIdentityTokenFile != "" {
+		if c.AssumeRoleExternalID != "" {
+			return fmt.Errorf("an external id can't be used together with a web identity token file")
+		}
+		if c.AccessKeyID != "" {
+			return fmt.Errorf("static credentials can't be used together with a web identity token file")
+		}
+	}
+	return nil
+}
+
+func credentialsCacheKey(c *Config) string {
+	secretHash := sha256.Sum256([]byte(c.SecretAccessKey))
+	return strings.Join([]string{
+		c.AccessKeyID,
+		fmt.Sprintf("%x", secretHash),
+		c.AssumeRoleARN,
+		c.AssumeRoleExternalID,
+		c.WebIdentityTokenFile,
+	}, "/")
+}
+
+// getCredentials returns the cached credentials for the config.
+// Without an access key the default credential chain of the SDK gets used,
+// which covers the environment, the shared config, web identities and the instance profile.
+func getCredentials(c *Config) (*credentials.Credentials, error) {
+	credentialsCacheLock.Lock()
+	defer credentialsCacheLock.Unlock()
+
+	key := credentialsCacheKey(c)
+	if creds, ok := credentialsCache[key]; ok {
+		return creds, nil
+	}
+
+	config := aws.NewConfig().WithRegion(c.Region).WithMaxRetries(maxRetries)
+	if c.AccessKeyID != "" {
+		config = config.WithCredentials(credentials.NewStaticCredentials(c.AccessKeyID, c.SecretAccessKey, ""))
+	}
+	// The session resolves the default credential chain when no access key is set
+	sess, err := session.NewSession(config)
+	if err != nil {
+		return nil, err
+	}
+	creds := sess.Config.Credentials
+
+	if c.AssumeRoleARN != "" {
+		if c.WebIdentityTokenFile != "" {
+			provider := stscreds.NewWebIdentityRoleProvider(sts.New(sess), c.AssumeRoleARN, roleSessionName, c.WebIdentityTokenFile)
+			provider.ExpiryWindow = credentialsExpiryWindow
+			creds = credentials.NewCredentials(provider)
+		} else {
+			creds = stscreds.NewCredentials(sess, c.AssumeRoleARN, func(p *stscreds.AssumeRoleProvider) {
+				p.RoleSessionName = roleSessionName
+				p.ExpiryWindow = credentialsExpiryWindow
+				if c.AssumeRoleExternalID != "" {
+					p.ExternalID = aws.String(c.AssumeRoleExternalID)
+				}
+			})
+		}
+	}
+
+	credentialsCache[key] = creds
+	return creds, nil
+}
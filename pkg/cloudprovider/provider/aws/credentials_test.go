@@ -0,0 +1,57 @@
+package aws
+
+import "testing"
+
+func TestGetCredentialsCache(t *testing.T) {
+	static := &Config{Region: "eu-central-1", AccessKeyID: "AKID", SecretAccessKey: "secret"}
+	assumed := &Config{Region: "eu-central-1", AccessKeyID: "AKID", SecretAccessKey: "secret", AssumeRoleARN: "arn:aws:iam::123456789012:role/nodes"}
+	otherRegion := &Config{Region: "us-east-1", AccessKeyID: "AKID", SecretAccessKey: "secret", AssumeRoleARN: "arn:aws:iam::123456789012:role/nodes"}
+
+	staticCreds, err := getCredentials(static)
+	if err != nil {
+		t.Fatalf("failed to get static credentials: %v", err)
+	}
+	assumedCreds, err := getCredentials(assumed)
+	if err != nil {
+		t.Fatalf("failed to get assumed credentials: %v", err)
+	}
+	if staticCreds == assumedCreds {
+		t.Errorf("expected different credentials for the assumed role")
+	}
+
+	cachedCreds, err := getCredentials(otherRegion)
+	if err != nil {
+		t.Fatalf("failed to get cached credentials: %v", err)
+	}
+	if cachedCreds != assumedCreds {
+		t.Errorf("expected the credentials of the role to be cached across regions")
+	}
+}
+
+func TestValidateCredentials(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		valid  bool
+	}{
+		{name: "default chain", config: Config{}, valid: true},
+		{name: "static", config: Config{AccessKeyID: "AKID", SecretAccessKey: "secret"}, valid: true},
+		{name: "missing secret", config: Config{AccessKeyID: "AKID"}, valid: false},
+		{name: "assume role", config: Config{AssumeRoleARN: "arn", AssumeRoleExternalID: "id"}, valid: true},
+		{name: "external id without role", config: Config{AssumeRoleExternalID: "id"}, valid: false},
+		{name: "web identity", config: Config{AssumeRoleARN: "arn", WebIdentityTokenFile: "/var/run/token"}, valid: true},
+		{name: "web identity with external id", config: Config{AssumeRoleARN: "arn", AssumeRoleExternalID: "id", WebIdentityTokenFile: "/var/run/token"}, valid: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := validateCredentials(&test.config)
+			if test.valid && err != nil {
+				t.Errorf("expected config to be valid, got %v", err)
+			}
+			if !test.valid && err == nil {
+				t.Errorf("expected config to be invalid")
+			}
+		})
+	}
+}
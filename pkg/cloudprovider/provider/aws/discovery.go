@@ -0,0 +1,175 @@
+package aws
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// SecurityGroupSelector selects security groups by their names and tags.
+// A group needs to have one of the names and all of the tags.
+type SecurityGroupSelector struct {
+	Names []string `json:"names,omitempty"`
+	// Tags with an empty value only need to exist, e.g. "kubernetes.io/cluster/my-cluster": ""
+	Tags map[string]string `json:"tags,omitempty"`
+}
+
+func validateSelectors(c *Config) error {
+	if _, ok := c.SubnetSelector[""]; ok {
+		return fmt.Errorf("the subnet selector contains an empty tag key")
+	}
+	if len(c.SubnetSelector) > 0 && c.SubnetID == "" {
+		return fmt.Errorf("the subnet selector has not been resolved to a subnet")
+	}
+
+	if s := c.SecurityGroupSelector; s != nil {
+		if len(s.Names) == 0 && len(s.Tags) == 0 {
+			return fmt.Errorf("the security group selector needs names or tags")
+		}
+		if _, ok := s.Tags[""]; ok {
+			return fmt.Errorf("the security group selector contains an empty tag key")
+		}
+		if len(c.SecurityGroupIDs) == 0 {
+			return fmt.Errorf("the security group selector has not been resolved to security groups")
+		}
+	}
+	return nil
+}
+
+// tagFilters returns the filters for the tags with a value.
+// Tags without a value can't be combined in a single filter, they get checked by matchesTags instead.
+func tagFilters(selector map[string]string) []*ec2.Filter {
+	var keys []string
+	for key, value := range selector {
+		if value != "" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var filters []*ec2.Filter
+	for _, key := range keys {
+		filters = append(filters, &ec2.Filter{
+			Name:   aws.String("tag:" + key),
+			Values: aws.StringSlice([]string{selector[key]}),
+		})
+	}
+	return filters
+}
+
+func matchesTags(tags []*ec2.Tag, selector map[string]string) bool {
+	existing := map[string]string{}
+	for _, tag := range tags {
+		existing[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+	}
+	for key, value := range selector {
+		existingValue, ok := existing[key]
+		if !ok || (value != "" && existingValue != value) {
+			return false
+		}
+	}
+	return true
+}
+
+// selectSubnet returns the subnet with the most free ips. Ties get resolved by the subnet id,
+// so the same subnet gets selected as long as nothing changes.
+func selectSubnet(subnets []*ec2.Subnet) *ec2.Subnet {
+	var selected *ec2.Subnet
+	for _, subnet := range subnets {
+		if selected == nil {
+			selected = subnet
+			continue
+		}
+		free, selectedFree := aws.Int64Value(subnet.AvailableIpAddressCount), aws.Int64Value(selected.AvailableIpAddressCount)
+		if free > selectedFree || (free == selectedFree && aws.StringValue(subnet.SubnetId) < aws.StringValue(selected.SubnetId)) {
+			selected = subnet
+		}
+	}
+	return selected
+}
+
+// findSubnet returns the available subnet which matches the subnet selector and has the most free ips.
+// The configured vpc and availability zone restrict the subnets.
+func findSubnet(client *ec2.EC2, c *Config) (*ec2.Subnet, error) {
+	filters := append(tagFilters(c.SubnetSelector), &ec2.Filter{
+		Name:   aws.String("state"),
+		Values: aws.StringSlice([]string{ec2.SubnetStateAvailable}),
+	})
+	if c.VpcID != "" {
+		filters = append(filters, &ec2.Filter{Name: aws.String("vpc-id"), Values: aws.StringSlice([]string{c.VpcID})})
+	}
+	if c.AvailabilityZone != "" {
+		filters = append(filters, &ec2.Filter{Name: aws.String("availability-zone"), Values: aws.StringSlice([]string{c.AvailabilityZone})})
+	}
+
+	var subnets []*ec2.Subnet
+	err := client.DescribeSubnetsPages(&ec2.DescribeSubnetsInput{Filters: filters}, func(out *ec2.DescribeSubnetsOutput, lastPage bool) bool {
+		for _, subnet := range out.Subnets {
+			if matchesTags(subnet.Tags, c.SubnetSelector) {
+				subnets = append(subnets, subnet)
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, awsErrorToTerminalError(err, "failed to list subnets")
+	}
+
+	subnet := selectSubnet(subnets)
+	if subnet == nil {
+		return nil, fmt.Errorf("no available subnet matches the subnet selector %v", c.SubnetSelector)
+	}
+	return subnet, nil
+}
+
+func getSubnet(client *ec2.EC2, id string) (*ec2.Subnet, error) {
+	out, err := client.DescribeSubnets(&ec2.DescribeSubnetsInput{
+		SubnetIds: aws.StringSlice([]string{id}),
+	})
+	if err != nil {
+		return nil, awsErrorToTerminalError(err, fmt.Sprintf("failed to get subnet %s", id))
+	}
+	if len(out.Subnets) != 1 {
+		return nil, fmt.Errorf("unable to find specified subnet with id %q", id)
+	}
+	return out.Subnets[0], nil
+}
+
+// findSecurityGroups returns the ids of the security groups which match the selector, sorted by id.
+// Every name of the selector needs to match a group.
+func findSecurityGroups(client *ec2.EC2, selector *SecurityGroupSelector, vpcID string) ([]string, error) {
+	filters := tagFilters(selector.Tags)
+	if len(selector.Names) > 0 {
+		filters = append(filters, &ec2.Filter{Name: aws.String("group-name"), Values: aws.StringSlice(selector.Names)})
+	}
+	if vpcID != "" {
+		filters = append(filters, &ec2.Filter{Name: aws.String("vpc-id"), Values: aws.StringSlice([]string{vpcID})})
+	}
+
+	ids := sets.NewString()
+	names := sets.NewString()
+	err := client.DescribeSecurityGroupsPages(&ec2.DescribeSecurityGroupsInput{Filters: filters}, func(out *ec2.DescribeSecurityGroupsOutput, lastPage bool) bool {
+		for _, group := range out.SecurityGroups {
+			if matchesTags(group.Tags, selector.Tags) {
+				ids.Insert(aws.StringValue(group.GroupId))
+				names.Insert(aws.StringValue(group.GroupName))
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, awsErrorToTerminalError(err, "failed to list security groups")
+	}
+
+	if missing := sets.NewString(selector.Names...).Difference(names); missing.Len() > 0 {
+		return nil, fmt.Errorf("no security groups found for the names %v", missing.List())
+	}
+	if ids.Len() == 0 {
+		return nil, fmt.Errorf("no security groups match the security group selector")
+	}
+	return ids.List(), nil
+}
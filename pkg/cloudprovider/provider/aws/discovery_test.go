@@ -0,0 +1,67 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+func TestMatchesTags(t *testing.T) {
+	tags := []*ec2.Tag{
+		{Key: aws.String("kubernetes.io/role/internal-elb"), Value: aws.String("1")},
+		{Key: aws.String("kubernetes.io/cluster/my-cluster"), Value: aws.String("shared")},
+	}
+
+	tests := []struct {
+		name     string
+		selector map[string]string
+		matches  bool
+	}{
+		{name: "empty selector", selector: nil, matches: true},
+		{name: "value", selector: map[string]string{"kubernetes.io/role/internal-elb": "1"}, matches: true},
+		{name: "existing key", selector: map[string]string{"kubernetes.io/cluster/my-cluster": ""}, matches: true},
+		{name: "all tags", selector: map[string]string{"kubernetes.io/role/internal-elb": "1", "kubernetes.io/cluster/my-cluster": ""}, matches: true},
+		{name: "other value", selector: map[string]string{"kubernetes.io/role/internal-elb": "0"}, matches: false},
+		{name: "missing key", selector: map[string]string{"kubernetes.io/role/elb": ""}, matches: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if matches := matchesTags(tags, test.selector); matches != test.matches {
+				t.Errorf("expected %v, got %v", test.matches, matches)
+			}
+		})
+	}
+}
+
+func TestSelectSubnet(t *testing.T) {
+	subnet := func(id string, free int64) *ec2.Subnet {
+		return &ec2.Subnet{SubnetId: aws.String(id), AvailableIpAddressCount: aws.Int64(free)}
+	}
+
+	tests := []struct {
+		name     string
+		subnets  []*ec2.Subnet
+		expected string
+	}{
+		{name: "no subnets", subnets: nil, expected: ""},
+		{name: "most free ips", subnets: []*ec2.Subnet{subnet("subnet-a", 10), subnet("subnet-b", 250), subnet("subnet-c", 20)}, expected: "subnet-b"},
+		{name: "tie", subnets: []*ec2.Subnet{subnet("subnet-c", 100), subnet("subnet-a", 100), subnet("subnet-b", 50)}, expected: "subnet-a"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			selected := selectSubnet(test.subnets)
+			if test.expected == "" {
+				if selected != nil {
+					t.Errorf("expected no subnet, got %s", aws.StringValue(selected.SubnetId))
+				}
+				return
+			}
+			if selected == nil || aws.StringValue(selected.SubnetId) != test.expected {
+				t.Errorf("expected subnet %s, got %v", test.expected, selected)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package aws
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/golang/glog"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	minHTTPPutResponseHopLimit = 1
+	maxHTTPPutResponseHopLimit = 64
+)
+
+var (
+	httpTokensStates   = sets.NewString(ec2.HttpTokensStateOptional, ec2.HttpTokensStateRequired)
+	httpEndpointStates = sets.NewString(ec2.InstanceMetadataEndpointStateEnabled, ec2.InstanceMetadataEndpointStateDisabled)
+	shutdownBehaviors  = sets.NewString(ec2.ShutdownBehaviorStop, ec2.ShutdownBehaviorTerminate)
+)
+
+// MetadataOptions configure the instance metadata service of the instance.
+// Unset fields use the defaults of aws.
+type MetadataOptions struct {
+	// HTTPTokens is "required" for IMDSv2 only instances, or "optional"
+	HTTPTokens string `json:"httpTokens,omitempty"`
+	// HTTPPutResponseHopLimit limits how far the responses of the metadata service travel.
+	// A limit of 1 prevents containers which don't use the host network from reaching it.
+	HTTPPutResponseHopLimit int64 `json:"httpPutResponseHopLimit,omitempty"`
+	// HTTPEndpoint is "enabled" or "disabled"
+	HTTPEndpoint string `json:"httpEndpoint,omitempty"`
+}
+
+func validateInstanceOptions(c *Config) error {
+	if o := c.MetadataOptions; o != nil {
+		if o.HTTPTokens != "" && !httpTokensStates.Has(o.HTTPTokens) {
+			return fmt.Errorf("invalid metadata http tokens %s specified. Supported: %s", o.HTTPTokens, httpTokensStates)
+		}
+		if o.HTTPEndpoint != "" && !httpEndpointStates.Has(o.HTTPEndpoint) {
+			return fmt.Errorf("invalid metadata http endpoint %s specified. Supported: %s", o.HTTPEndpoint, httpEndpointStates)
+		}
+		if o.HTTPPutResponseHopLimit != 0 && (o.HTTPPutResponseHopLimit < minHTTPPutResponseHopLimit || o.HTTPPutResponseHopLimit > maxHTTPPutResponseHopLimit) {
+			return fmt.Errorf("metadata http put response hop limit must be between %d and %d", minHTTPPutResponseHopLimit, maxHTTPPutResponseHopLimit)
+		}
+	}
+
+	if c.ShutdownBehavior != "" && !shutdownBehaviors.Has(c.ShutdownBehavior) {
+		return fmt.Errorf("invalid shutdown behavior %s specified. Supported: %s", c.ShutdownBehavior, shutdownBehaviors)
+	}
+	if c.SpotInstanceConfig != nil {
+		if c.DisableAPITermination {
+			return fmt.Errorf("termination protection is not supported for spot instances")
+		}
+		if c.ShutdownBehavior == ec2.ShutdownBehaviorStop {
+			return fmt.Errorf("the %q shutdown behavior is not supported for spot instances", ec2.ShutdownBehaviorStop)
+		}
+	}
+	return nil
+}
+
+func setInstanceOptions(c *Config, input *ec2.RunInstancesInput) {
+	if o := c.MetadataOptions; o != nil {
+		options := &ec2.InstanceMetadataOptionsRequest{}
+		if o.HTTPTokens != "" {
+			options.HttpTokens = aws.String(o.HTTPTokens)
+		}
+		if o.HTTPPutResponseHopLimit != 0 {
+			options.HttpPutResponseHopLimit = aws.Int64(o.HTTPPutResponseHopLimit)
+		}
+		if o.HTTPEndpoint != "" {
+			options.HttpEndpoint = aws.String(o.HTTPEndpoint)
+		}
+		input.MetadataOptions = options
+	}
+	if c.DisableAPITermination {
+		input.DisableApiTermination = aws.Bool(true)
+	}
+	if c.ShutdownBehavior != "" {
+		input.InstanceInitiatedShutdownBehavior = aws.String(c.ShutdownBehavior)
+	}
+}
+
+// disableTerminationProtection lifts the termination protection, so the instance can be terminated.
+// The attribute gets checked on the instance, as the protection might come from a launch template or got set manually.
+func disableTerminationProtection(client *ec2.EC2, instanceID string) error {
+	out, err := client.DescribeInstanceAttribute(&ec2.DescribeInstanceAttributeInput{
+		InstanceId: aws.String(instanceID),
+		Attribute:  aws.String(ec2.InstanceAttributeNameDisableApiTermination),
+	})
+	if err != nil {
+		return awsErrorToTerminalError(err, fmt.Sprintf("failed to get termination protection of instance %s", instanceID))
+	}
+	if out.DisableApiTermination == nil || !aws.BoolValue(out.DisableApiTermination.Value) {
+		return nil
+	}
+
+	glog.V(4).Infof("Disabling termination protection of instance %s", instanceID)
+	_, err = client.ModifyInstanceAttribute(&ec2.ModifyInstanceAttributeInput{
+		InstanceId:            aws.String(instanceID),
+		DisableApiTermination: &ec2.AttributeBooleanValue{Value: aws.Bool(false)},
+	})
+	if err != nil {
+		return awsErrorToTerminalError(err, fmt.Sprintf("failed to disable termination protection of instance %s", instanceID))
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package aws
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+
+	"github.com/kubermatic/machine-controller/pkg/providerconfig"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+type NetworkInterfaceRawConfig struct {
+	SubnetID providerconfig.ConfigVarString `json:"subnetId"`
+	// SecurityGroupIDs default to the security groups of the primary interface
+	SecurityGroupIDs        []providerconfig.ConfigVarString `json:"securityGroupIDs,omitempty"`
+	SecondaryPrivateIPCount int64                            `json:"secondaryPrivateIPCount,omitempty"`
+	IPv6AddressCount        int64                            `json:"ipv6AddressCount,omitempty"`
+}
+
+type NetworkInterfaceConfig struct {
+	SubnetID                string
+	SecurityGroupIDs        []string
+	SecondaryPrivateIPCount int64
+	IPv6AddressCount        int64
+}
+
+func (p *provider) getNetworkInterfaceConfig(raw NetworkInterfaceRawConfig) (*NetworkInterfaceConfig, error) {
+	var err error
+	c := NetworkInterfaceConfig{
+		SecondaryPrivateIPCount: raw.SecondaryPrivateIPCount,
+		IPv6AddressCount:        raw.IPv6AddressCount,
+	}
+	c.SubnetID, err = p.configVarResolver.GetConfigVarStringValue(raw.SubnetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the value of \"additionalNetworkInterfaces.subnetId\" field, error = %v", err)
+	}
+	for _, securityGroupIDRaw := range raw.SecurityGroupIDs {
+		securityGroupID, err := p.configVarResolver.GetConfigVarStringValue(securityGroupIDRaw)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the value of \"additionalNetworkInterfaces.securityGroupIDs\" field, error = %v", err)
+		}
+		c.SecurityGroupIDs = append(c.SecurityGroupIDs, securityGroupID)
+	}
+	return &c, nil
+}
+
+func validateNetworkInterfaces(c *Config) error {
+	if c.SecondaryPrivateIPCount < 0 || c.IPv6AddressCount < 0 {
+		return fmt.Errorf("the number of secondary private ips and ipv6 addresses must not be negative")
+	}
+	if len(c.AdditionalNetworkInterfaces) > 0 && aws.BoolValue(c.AssignPublicIP) {
+		return fmt.Errorf("a public ip can't be assigned to instances with additional network interfaces")
+	}
+	for i, nic := range c.AdditionalNetworkInterfaces {
+		if nic.SubnetID == "" {
+			return fmt.Errorf("no subnet specified for additional network interface %d", i)
+		}
+		if nic.SecondaryPrivateIPCount < 0 || nic.IPv6AddressCount < 0 {
+			return fmt.Errorf("the number of secondary private ips and ipv6 addresses of additional network interface %d must not be negative", i)
+		}
+	}
+	return nil
+}
+
+// getNetworkInterfaceSpecifications returns the primary interface in the subnet of the machine
+// followed by the additional interfaces
+func getNetworkInterfaceSpecifications(c *Config, securityGroupIDs []string) []*ec2.InstanceNetworkInterfaceSpecification {
+	primary := &ec2.InstanceNetworkInterfaceSpecification{
+		DeviceIndex:         aws.Int64(0), // eth0
+		DeleteOnTermination: aws.Bool(true),
+		SubnetId:            aws.String(c.SubnetID),
+		Groups:              aws.StringSlice(securityGroupIDs),
+	}
+	// AWS rejects the parameter for launches with multiple interfaces, even when it is false.
+	// Without additional interfaces a public ip gets assigned unless it got disabled.
+	if len(c.AdditionalNetworkInterfaces) == 0 {
+		primary.AssociatePublicIpAddress = aws.Bool(c.AssignPublicIP == nil || *c.AssignPublicIP)
+	}
+	setAddressCounts(primary, c.SecondaryPrivateIPCount, c.IPv6AddressCount)
+
+	specs := []*ec2.InstanceNetworkInterfaceSpecification{primary}
+	for i, nic := range c.AdditionalNetworkInterfaces {
+		groups := nic.SecurityGroupIDs
+		if len(groups) == 0 {
+			groups = securityGroupIDs
+		}
+		spec := &ec2.InstanceNetworkInterfaceSpecification{
+			DeviceIndex:         aws.Int64(int64(i + 1)),
+			DeleteOnTermination: aws.Bool(true),
+			SubnetId:            aws.String(nic.SubnetID),
+			Groups:              aws.StringSlice(groups),
+		}
+		setAddressCounts(spec, nic.SecondaryPrivateIPCount, nic.IPv6AddressCount)
+		specs = append(specs, spec)
+	}
+	return specs
+}
+
+func setAddressCounts(spec *ec2.InstanceNetworkInterfaceSpecification, secondaryPrivateIPCount, ipv6AddressCount int64) {
+	if secondaryPrivateIPCount > 0 {
+		spec.SecondaryPrivateIpAddressCount = aws.Int64(secondaryPrivateIPCount)
+	}
+	if ipv6AddressCount > 0 {
+		spec.Ipv6AddressCount = aws.Int64(ipv6AddressCount)
+	}
+}
+
+// getInterfaceAddresses returns the addresses of all interfaces of the instance,
+// including secondary private ips and ipv6 addresses
+func getInterfaceAddresses(i *ec2.Instance) []string {
+	var addresses []string
+	seen := sets.NewString()
+	add := func(address *string) {
+		if a := aws.StringValue(address); a != "" && !seen.Has(a) {
+			seen.Insert(a)
+			addresses = append(addresses, a)
+		}
+	}
+
+	for _, nic := range i.NetworkInterfaces {
+		if nic.Association != nil {
+			add(nic.Association.PublicIp)
+			add(nic.Association.PublicDnsName)
+		}
+		for _, ip := range nic.PrivateIpAddresses {
+			if ip.Association != nil {
+				add(ip.Association.PublicIp)
+			}
+			add(ip.PrivateIpAddress)
+			add(ip.PrivateDnsName)
+		}
+		for _, ip := range nic.Ipv6Addresses {
+			add(ip.Ipv6Address)
+		}
+	}
+	return addresses
+}
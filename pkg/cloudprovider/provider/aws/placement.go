@@ -0,0 +1,123 @@
+package aws
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+
+	"github.com/kubermatic/machine-controller/pkg/providerconfig"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const defaultLaunchTemplateVersion = "$Default"
+
+var (
+	tenancies      = sets.NewString(ec2.TenancyDefault, ec2.TenancyDedicated, ec2.TenancyHost)
+	hostAffinities = sets.NewString(ec2.AffinityDefault, ec2.AffinityHost)
+)
+
+type LaunchTemplateRawConfig struct {
+	ID providerconfig.ConfigVarString `json:"id"`
+	// Version defaults to the default version of the launch template. "$Latest" uses the latest version.
+	Version providerconfig.ConfigVarString `json:"version,omitempty"`
+}
+
+type LaunchTemplateConfig struct {
+	ID      string
+	Version string
+}
+
+func (p *provider) getLaunchTemplateConfig(raw *LaunchTemplateRawConfig) (*LaunchTemplateConfig, error) {
+	if raw == nil {
+		return nil, nil
+	}
+
+	var err error
+	c := LaunchTemplateConfig{}
+	c.ID, err = p.configVarResolver.GetConfigVarStringValue(raw.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the value of \"launchTemplate.id\" field, error = %v", err)
+	}
+	c.Version, err = p.configVarResolver.GetConfigVarStringValue(raw.Version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the value of \"launchTemplate.version\" field, error = %v", err)
+	}
+	if c.Version == "" {
+		c.Version = defaultLaunchTemplateVersion
+	}
+	return &c, nil
+}
+
+func validatePlacement(c *Config) error {
+	if c.LaunchTemplate != nil && c.LaunchTemplate.ID == "" {
+		return fmt.Errorf("no id specified for the launch template")
+	}
+	if c.LaunchTemplate != nil && c.SubnetID == "" {
+		if c.AssignPublicIP != nil || c.SecondaryPrivateIPCount != 0 || c.IPv6AddressCount != 0 || len(c.AdditionalNetworkInterfaces) > 0 {
+			return fmt.Errorf("the network interfaces of the launch template can only be overridden together with a subnet")
+		}
+	}
+
+	if c.Tenancy != "" && !tenancies.Has(c.Tenancy) {
+		return fmt.Errorf("invalid tenancy %s specified. Supported: %s", c.Tenancy, tenancies)
+	}
+	if c.HostAffinity != "" && !hostAffinities.Has(c.HostAffinity) {
+		return fmt.Errorf("invalid host affinity %s specified. Supported: %s", c.HostAffinity, hostAffinities)
+	}
+	if (c.HostID != "" || c.HostAffinity != "") && c.Tenancy != ec2.TenancyHost {
+		return fmt.Errorf("host id and host affinity require the %q tenancy", ec2.TenancyHost)
+	}
+	if c.Tenancy == ec2.TenancyHost && c.SpotInstanceConfig != nil {
+		return fmt.Errorf("spot instances can't be launched on dedicated hosts")
+	}
+	return nil
+}
+
+// getPlacement returns nil when nothing is configured, so the placement of the launch template gets used
+func getPlacement(c *Config) *ec2.Placement {
+	if c.AvailabilityZone == "" && c.PlacementGroup == "" && c.Tenancy == "" && c.HostID == "" && c.HostAffinity == "" {
+		return nil
+	}
+
+	placement := &ec2.Placement{}
+	if c.AvailabilityZone != "" {
+		placement.AvailabilityZone = aws.String(c.AvailabilityZone)
+	}
+	if c.PlacementGroup != "" {
+		placement.GroupName = aws.String(c.PlacementGroup)
+	}
+	if c.Tenancy != "" {
+		placement.Tenancy = aws.String(c.Tenancy)
+	}
+	if c.HostID != "" {
+		placement.HostId = aws.String(c.HostID)
+	}
+	if c.HostAffinity != "" {
+		placement.Affinity = aws.String(c.HostAffinity)
+	}
+	return placement
+}
+
+// getLaunchTemplateData returns the data of the configured launch template version
+func getLaunchTemplateData(client *ec2.EC2, c *LaunchTemplateConfig) (*ec2.ResponseLaunchTemplateData, error) {
+	out, err := client.DescribeLaunchTemplateVersions(&ec2.DescribeLaunchTemplateVersionsInput{
+		LaunchTemplateId: aws.String(c.ID),
+		Versions:         aws.StringSlice([]string{c.Version}),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(out.LaunchTemplateVersions) == 0 || out.LaunchTemplateVersions[0].LaunchTemplateData == nil {
+		return nil, fmt.Errorf("launch template version not found")
+	}
+	return out.LaunchTemplateVersions[0].LaunchTemplateData, nil
+}
+
+func validatePlacementGroup(client *ec2.EC2, name string) error {
+	_, err := client.DescribePlacementGroups(&ec2.DescribePlacementGroupsInput{
+		GroupNames: aws.StringSlice([]string{name}),
+	})
+	return err
+}
@@ -4,22 +4,28 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
-	"time"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/kubermatic/machine-controller/pkg/cloudprovider/cloud"
 	cloudprovidererrors "github.com/kubermatic/machine-controller/pkg/cloudprovider/errors"
 	"github.com/kubermatic/machine-controller/pkg/cloudprovider/instance"
 	"github.com/kubermatic/machine-controller/pkg/providerconfig"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
-	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/ssm"
 	"github.com/golang/glog"
 
 	common "sigs.k8s.io/cluster-api/pkg/apis/cluster/common"
@@ -30,6 +36,27 @@ type provider struct {
 	configVarResolver *providerconfig.ConfigVarResolver
 }
 
+var (
+	recorderLock sync.RWMutex
+	// recorder is used to emit events for machines, e.g. when duplicate instances got terminated
+	recorder record.EventRecorder
+)
+
+// SetEventRecorder sets the recorder which gets used to emit events for machines
+func SetEventRecorder(r record.EventRecorder) {
+	recorderLock.Lock()
+	defer recorderLock.Unlock()
+	recorder = r
+}
+
+func recordEvent(machine *v1alpha1.Machine, eventType, reason, message string) {
+	recorderLock.RLock()
+	defer recorderLock.RUnlock()
+	if recorder != nil {
+		recorder.Event(machine, eventType, reason, message)
+	}
+}
+
 // New returns a aws provider
 func New(configVarResolver *providerconfig.ConfigVarResolver) cloud.Provider {
 	return &provider{configVarResolver: configVarResolver}
@@ -38,6 +65,9 @@ func New(configVarResolver *providerconfig.ConfigVarResolver) cloud.Provider {
 const (
 	nameTag       = "Name"
 	machineUIDTag = "Machine-UID"
+	// clusterTag is the legacy tag of the aws cloud provider, clusterIDTagPrefix the current one
+	clusterTag         = "KubernetesCluster"
+	clusterIDTagPrefix = "kubernetes.io/cluster/"
 
 	policyRoute53FullAccess = "arn:aws:iam::aws:policy/AmazonRoute53FullAccess"
 	policyEC2FullAccess     = "arn:aws:iam::aws:policy/AmazonEC2FullAccess"
@@ -47,6 +77,10 @@ const (
 	defaultSecurityGroupName   = "kubernetes-v1"
 
 	maxRetries = 100
+
+	// launchGenerationAnnotation counts the instances which got launched for the machine and terminated afterwards.
+	// It is part of the client token, so a new instance gets launched after the previous one got terminated.
+	launchGenerationAnnotation = "machine-controller/aws-launch-generation"
 )
 
 var (
@@ -70,31 +104,21 @@ var (
     }
   ]
 }`
-
-	amiFilters = map[providerconfig.OperatingSystem]amiFilter{
-		providerconfig.OperatingSystemCoreos: {
-			description: "CoreOS Container Linux stable*",
-			// The AWS marketplace ID from CoreOS
-			owner: "595879546273",
-		},
-		providerconfig.OperatingSystemCentOS: {
-			description: "CentOS Linux 7 x86_64 HVM EBS*",
-			// The AWS marketplace ID from AWS
-			owner: "679593333241",
-		},
-		providerconfig.OperatingSystemUbuntu: {
-			// Be as precise as possible - otherwise we might get a nightly dev build
-			description: "Canonical, Ubuntu, 18.04 LTS, amd64 bionic image build on ????-??-??",
-			// The AWS marketplace ID from Canonical
-			owner: "099720109477",
-		},
-	}
 )
 
 type RawConfig struct {
+	// AccessKeyID and SecretAccessKey are optional, the default credential chain gets used without them
 	AccessKeyID     providerconfig.ConfigVarString `json:"accessKeyId"`
 	SecretAccessKey providerconfig.ConfigVarString `json:"secretAccessKey"`
 
+	// AssumeRoleARN is the role which gets assumed with the credentials or the WebIdentityTokenFile
+	AssumeRoleARN        providerconfig.ConfigVarString `json:"assumeRoleARN,omitempty"`
+	AssumeRoleExternalID providerconfig.ConfigVarString `json:"assumeRoleExternalID,omitempty"`
+	WebIdentityTokenFile providerconfig.ConfigVarString `json:"webIdentityTokenFile,omitempty"`
+
+	// CloudProviderRoleARN is the role the aws cloud provider of the kubelet assumes
+	CloudProviderRoleARN providerconfig.ConfigVarString `json:"cloudProviderRoleARN,omitempty"`
+
 	Region           providerconfig.ConfigVarString `json:"region"`
 	AvailabilityZone providerconfig.ConfigVarString `json:"availabilityZone"`
 
@@ -103,17 +127,72 @@ type RawConfig struct {
 	SecurityGroupIDs []providerconfig.ConfigVarString `json:"securityGroupIDs"`
 	InstanceProfile  providerconfig.ConfigVarString   `json:"instanceProfile"`
 
+	// SubnetSelector selects the subnet by its tags when no SubnetID is set.
+	// The availability zone and vpc get taken from the selected subnet.
+	SubnetSelector map[string]string `json:"subnetSelector,omitempty"`
+	// SecurityGroupSelector selects the security groups when no SecurityGroupIDs are set
+	SecurityGroupSelector *SecurityGroupSelector `json:"securityGroupSelector,omitempty"`
+
+	// AssignPublicIP defaults to true, unless there are additional network interfaces
+	AssignPublicIP          *bool `json:"assignPublicIP,omitempty"`
+	SecondaryPrivateIPCount int64 `json:"secondaryPrivateIPCount,omitempty"`
+	IPv6AddressCount        int64 `json:"ipv6AddressCount,omitempty"`
+	// AdditionalNetworkInterfaces get attached to the instance in addition to the primary one in the SubnetID
+	AdditionalNetworkInterfaces []NetworkInterfaceRawConfig `json:"additionalNetworkInterfaces,omitempty"`
+
 	InstanceType providerconfig.ConfigVarString `json:"instanceType"`
 	AMI          providerconfig.ConfigVarString `json:"ami"`
 	DiskSize     int64                          `json:"diskSize"`
 	DiskType     providerconfig.ConfigVarString `json:"diskType"`
 	Tags         map[string]string              `json:"tags"`
+
+	// AMIParameter is the name of a ssm parameter with the ami id,
+	// e.g. "/aws/service/canonical/ubuntu/server/20.04/stable/current/amd64/hvm/ebs-gp2/ami-id"
+	AMIParameter providerconfig.ConfigVarString `json:"amiParameter,omitempty"`
+	// AMIFilter selects the latest matching ami
+	AMIFilter *AMIFilter `json:"amiFilter,omitempty"`
+	// Architecture of the ami (x86_64 or arm64). Defaults to the architecture of the instance type
+	Architecture providerconfig.ConfigVarString `json:"architecture,omitempty"`
+
+	// DiskEncrypted encrypts the root volume with the default aws/ebs key, unless a DiskKMSKeyID is set
+	DiskEncrypted bool                           `json:"diskEncrypted,omitempty"`
+	DiskKMSKeyID  providerconfig.ConfigVarString `json:"diskKMSKeyId,omitempty"`
+	// DiskDeleteOnTermination defaults to true
+	DiskDeleteOnTermination *bool `json:"diskDeleteOnTermination,omitempty"`
+
+	// DataVolumes get attached to the instance in addition to the root volume
+	DataVolumes []DataVolumeRawConfig `json:"dataVolumes,omitempty"`
+
+	// LaunchTemplate provides the settings which are not configured in the other fields
+	LaunchTemplate *LaunchTemplateRawConfig `json:"launchTemplate,omitempty"`
+
+	PlacementGroup providerconfig.ConfigVarString `json:"placementGroup,omitempty"`
+	// Tenancy is one of default, dedicated or host
+	Tenancy providerconfig.ConfigVarString `json:"tenancy,omitempty"`
+	// HostID and HostAffinity require the host tenancy
+	HostID       providerconfig.ConfigVarString `json:"hostId,omitempty"`
+	HostAffinity providerconfig.ConfigVarString `json:"hostAffinity,omitempty"`
+
+	MetadataOptions *MetadataOptions `json:"metadataOptions,omitempty"`
+	// DisableAPITermination enables the termination protection, it gets lifted when the machine gets deleted
+	DisableAPITermination bool `json:"disableApiTermination,omitempty"`
+	// ShutdownBehavior is stop or terminate
+	ShutdownBehavior providerconfig.ConfigVarString `json:"shutdownBehavior,omitempty"`
+
+	// SpotInstanceConfig launches spot instances instead of on-demand ones when set
+	SpotInstanceConfig *SpotInstanceRawConfig `json:"spotInstanceConfig,omitempty"`
 }
 
 type Config struct {
 	AccessKeyID     string
 	SecretAccessKey string
 
+	AssumeRoleARN        string
+	AssumeRoleExternalID string
+	WebIdentityTokenFile string
+
+	CloudProviderRoleARN string
+
 	Region           string
 	AvailabilityZone string
 
@@ -122,59 +201,40 @@ type Config struct {
 	SecurityGroupIDs []string
 	InstanceProfile  string
 
+	SubnetSelector        map[string]string
+	SecurityGroupSelector *SecurityGroupSelector
+
+	AssignPublicIP              *bool
+	SecondaryPrivateIPCount     int64
+	IPv6AddressCount            int64
+	AdditionalNetworkInterfaces []NetworkInterfaceConfig
+
 	InstanceType string
 	AMI          string
 	DiskSize     int64
 	DiskType     string
 	Tags         map[string]string
-}
-
-type amiFilter struct {
-	description string
-	owner       string
-}
 
-func getDefaultAMIID(client *ec2.EC2, os providerconfig.OperatingSystem) (string, error) {
-	filter, osSupported := amiFilters[os]
-	if !osSupported {
-		return "", fmt.Errorf("operating system %q not supported", os)
-	}
+	AMIParameter string
+	AMIFilter    *AMIFilter
+	Architecture string
 
-	imagesOut, err := client.DescribeImages(&ec2.DescribeImagesInput{
-		Owners: aws.StringSlice([]string{filter.owner}),
-		Filters: []*ec2.Filter{
-			{
-				Name:   aws.String("description"),
-				Values: aws.StringSlice([]string{filter.description}),
-			},
-			{
-				Name:   aws.String("virtualization-type"),
-				Values: aws.StringSlice([]string{"hvm"}),
-			},
-			{
-				Name:   aws.String("root-device-type"),
-				Values: aws.StringSlice([]string{"ebs"}),
-			},
-		},
-	})
-	if err != nil {
-		return "", err
-	}
+	DiskEncrypted           bool
+	DiskKMSKeyID            string
+	DiskDeleteOnTermination bool
+	DataVolumes             []DataVolumeConfig
 
-	if len(imagesOut.Images) == 0 {
-		return "", fmt.Errorf("could not find Image for '%s'", os)
-	}
+	LaunchTemplate *LaunchTemplateConfig
+	PlacementGroup string
+	Tenancy        string
+	HostID         string
+	HostAffinity   string
 
-	image := imagesOut.Images[0]
-	for _, v := range imagesOut.Images {
-		itime, _ := time.Parse(time.RFC3339, *image.CreationDate)
-		vtime, _ := time.Parse(time.RFC3339, *v.CreationDate)
-		if vtime.After(itime) {
-			image = v
-		}
-	}
+	MetadataOptions       *MetadataOptions
+	DisableAPITermination bool
+	ShutdownBehavior      string
 
-	return *image.ImageId, nil
+	SpotInstanceConfig *SpotInstanceConfig
 }
 
 func getDefaultRootDevicePath(os providerconfig.OperatingSystem) (string, error) {
@@ -190,114 +250,358 @@ func getDefaultRootDevicePath(os providerconfig.OperatingSystem) (string, error)
 	return "", fmt.Errorf("no default root path found for %s operating system", os)
 }
 
-func (p *provider) getConfig(s v1alpha1.ProviderConfig) (*Config, *providerconfig.Config, error) {
+func (p *provider) getConfig(s v1alpha1.ProviderConfig) (*Config, *providerconfig.Config, *RawConfig, error) {
 	if s.Value == nil {
-		return nil, nil, fmt.Errorf("machine.spec.providerconfig.value is nil")
+		return nil, nil, nil, fmt.Errorf("machine.spec.providerconfig.value is nil")
 	}
 	pconfig := providerconfig.Config{}
 	err := json.Unmarshal(s.Value.Raw, &pconfig)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 	rawConfig := RawConfig{}
 	err = json.Unmarshal(pconfig.CloudProviderSpec.Raw, &rawConfig)
+	if err != nil {
+		return nil, nil, nil, err
+	}
 	c := Config{}
 	c.AccessKeyID, err = p.configVarResolver.GetConfigVarStringValueOrEnv(rawConfig.AccessKeyID, "AWS_ACCESS_KEY_ID")
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to get the value of \"accessKeyId\" field, error = %v", err)
+		return nil, nil, nil, fmt.Errorf("failed to get the value of \"accessKeyId\" field, error = %v", err)
 	}
 	c.SecretAccessKey, err = p.configVarResolver.GetConfigVarStringValueOrEnv(rawConfig.SecretAccessKey, "AWS_SECRET_ACCESS_KEY")
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to get the value of \"secretAccessKey\" field, error = %v", err)
+		return nil, nil, nil, fmt.Errorf("failed to get the value of \"secretAccessKey\" field, error = %v", err)
+	}
+	c.AssumeRoleARN, err = p.configVarResolver.GetConfigVarStringValue(rawConfig.AssumeRoleARN)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to get the value of \"assumeRoleARN\" field, error = %v", err)
+	}
+	c.AssumeRoleExternalID, err = p.configVarResolver.GetConfigVarStringValue(rawConfig.AssumeRoleExternalID)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to get the value of \"assumeRoleExternalID\" field, error = %v", err)
+	}
+	c.WebIdentityTokenFile, err = p.configVarResolver.GetConfigVarStringValue(rawConfig.WebIdentityTokenFile)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to get the value of \"webIdentityTokenFile\" field, error = %v", err)
+	}
+	c.CloudProviderRoleARN, err = p.configVarResolver.GetConfigVarStringValue(rawConfig.CloudProviderRoleARN)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to get the value of \"cloudProviderRoleARN\" field, error = %v", err)
 	}
 	c.Region, err = p.configVarResolver.GetConfigVarStringValue(rawConfig.Region)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 	c.VpcID, err = p.configVarResolver.GetConfigVarStringValue(rawConfig.VpcID)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 	c.SubnetID, err = p.configVarResolver.GetConfigVarStringValue(rawConfig.SubnetID)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 	c.AvailabilityZone, err = p.configVarResolver.GetConfigVarStringValue(rawConfig.AvailabilityZone)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 	for _, securityGroupIDRaw := range rawConfig.SecurityGroupIDs {
 		securityGroupID, err := p.configVarResolver.GetConfigVarStringValue(securityGroupIDRaw)
 		if err != nil {
-			return nil, nil, err
+			return nil, nil, nil, err
 		}
 		c.SecurityGroupIDs = append(c.SecurityGroupIDs, securityGroupID)
 	}
 	c.InstanceProfile, err = p.configVarResolver.GetConfigVarStringValue(rawConfig.InstanceProfile)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
+	}
+	c.SubnetSelector = rawConfig.SubnetSelector
+	c.SecurityGroupSelector = rawConfig.SecurityGroupSelector
+	c.AssignPublicIP = rawConfig.AssignPublicIP
+	c.SecondaryPrivateIPCount = rawConfig.SecondaryPrivateIPCount
+	c.IPv6AddressCount = rawConfig.IPv6AddressCount
+	for _, rawInterface := range rawConfig.AdditionalNetworkInterfaces {
+		nic, err := p.getNetworkInterfaceConfig(rawInterface)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		c.AdditionalNetworkInterfaces = append(c.AdditionalNetworkInterfaces, *nic)
 	}
 	c.InstanceType, err = p.configVarResolver.GetConfigVarStringValue(rawConfig.InstanceType)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 	c.AMI, err = p.configVarResolver.GetConfigVarStringValue(rawConfig.AMI)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
+	}
+	c.AMIParameter, err = p.configVarResolver.GetConfigVarStringValue(rawConfig.AMIParameter)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to get the value of \"amiParameter\" field, error = %v", err)
+	}
+	c.AMIFilter = rawConfig.AMIFilter
+	c.Architecture, err = p.configVarResolver.GetConfigVarStringValue(rawConfig.Architecture)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to get the value of \"architecture\" field, error = %v", err)
 	}
 	c.DiskSize = rawConfig.DiskSize
 	c.DiskType, err = p.configVarResolver.GetConfigVarStringValue(rawConfig.DiskType)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 	c.Tags = rawConfig.Tags
+	c.DiskEncrypted = rawConfig.DiskEncrypted
+	c.DiskKMSKeyID, err = p.configVarResolver.GetConfigVarStringValue(rawConfig.DiskKMSKeyID)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to get the value of \"diskKMSKeyId\" field, error = %v", err)
+	}
+	c.DiskDeleteOnTermination = true
+	if rawConfig.DiskDeleteOnTermination != nil {
+		c.DiskDeleteOnTermination = *rawConfig.DiskDeleteOnTermination
+	}
+	for _, rawVolume := range rawConfig.DataVolumes {
+		volume, err := p.getDataVolumeConfig(rawVolume)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		c.DataVolumes = append(c.DataVolumes, *volume)
+	}
+	c.LaunchTemplate, err = p.getLaunchTemplateConfig(rawConfig.LaunchTemplate)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	c.PlacementGroup, err = p.configVarResolver.GetConfigVarStringValue(rawConfig.PlacementGroup)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to get the value of \"placementGroup\" field, error = %v", err)
+	}
+	c.Tenancy, err = p.configVarResolver.GetConfigVarStringValue(rawConfig.Tenancy)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to get the value of \"tenancy\" field, error = %v", err)
+	}
+	c.HostID, err = p.configVarResolver.GetConfigVarStringValue(rawConfig.HostID)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to get the value of \"hostId\" field, error = %v", err)
+	}
+	c.HostAffinity, err = p.configVarResolver.GetConfigVarStringValue(rawConfig.HostAffinity)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to get the value of \"hostAffinity\" field, error = %v", err)
+	}
+	c.MetadataOptions = rawConfig.MetadataOptions
+	c.DisableAPITermination = rawConfig.DisableAPITermination
+	c.ShutdownBehavior, err = p.configVarResolver.GetConfigVarStringValue(rawConfig.ShutdownBehavior)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to get the value of \"shutdownBehavior\" field, error = %v", err)
+	}
+	if rawConfig.SpotInstanceConfig != nil {
+		c.SpotInstanceConfig = &SpotInstanceConfig{
+			OnDemandFallbackAfter: rawConfig.SpotInstanceConfig.OnDemandFallbackAfter,
+		}
+		c.SpotInstanceConfig.MaxPrice, err = p.configVarResolver.GetConfigVarStringValue(rawConfig.SpotInstanceConfig.MaxPrice)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to get the value of \"spotInstanceConfig.maxPrice\" field, error = %v", err)
+		}
+	}
 
-	return &c, &pconfig, err
+	return &c, &pconfig, &rawConfig, err
 }
 
-func getSession(id, secret, token, region string) (*session.Session, error) {
+func getSession(c *Config) (*session.Session, error) {
 	config := aws.NewConfig()
-	config = config.WithRegion(region)
-	config = config.WithCredentials(credentials.NewStaticCredentials(id, secret, token))
+	config = config.WithRegion(c.Region)
+	creds, err := getCredentials(c)
+	if err != nil {
+		return nil, err
+	}
+	config = config.WithCredentials(creds)
 	config = config.WithMaxRetries(maxRetries)
 	return session.NewSession(config)
 }
 
-func getIAMclient(id, secret, region string) (*iam.IAM, error) {
-	sess, err := getSession(id, secret, "", region)
+func getIAMclient(c *Config) (*iam.IAM, error) {
+	sess, err := getSession(c)
 	if err != nil {
 		return nil, awsErrorToTerminalError(err, "failed to get aws session")
 	}
 	return iam.New(sess), nil
 }
 
-func getEC2client(id, secret, region string) (*ec2.EC2, error) {
-	sess, err := getSession(id, secret, "", region)
+func getSSMclient(c *Config) (*ssm.SSM, error) {
+	sess, err := getSession(c)
+	if err != nil {
+		return nil, awsErrorToTerminalError(err, "failed to get aws session")
+	}
+	return ssm.New(sess), nil
+}
+
+func getEC2client(c *Config) (*ec2.EC2, error) {
+	sess, err := getSession(c)
 	if err != nil {
 		return nil, awsErrorToTerminalError(err, "failed to get aws session")
 	}
 	return ec2.New(sess), nil
 }
 
+func setProviderConfig(rawConfig RawConfig, s v1alpha1.ProviderConfig) (*runtime.RawExtension, error) {
+	if s.Value == nil {
+		return nil, fmt.Errorf("machine.spec.providerconfig.value is nil")
+	}
+	pconfig := providerconfig.Config{}
+	err := json.Unmarshal(s.Value.Raw, &pconfig)
+	if err != nil {
+		return nil, err
+	}
+	rawCloudProviderSpec, err := json.Marshal(rawConfig)
+	if err != nil {
+		return nil, err
+	}
+	pconfig.CloudProviderSpec = runtime.RawExtension{Raw: rawCloudProviderSpec}
+	rawPconfig, err := json.Marshal(pconfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return &runtime.RawExtension{Raw: rawPconfig}, nil
+}
+
+// AddDefaults resolves the subnet and security group selectors. The availability zone and vpc
+// get taken from the subnet when they are not set.
 func (p *provider) AddDefaults(spec v1alpha1.MachineSpec) (v1alpha1.MachineSpec, bool, error) {
-	return spec, false, nil
+	var changed bool
+
+	c, _, rawConfig, err := p.getConfig(spec.ProviderConfig)
+	if err != nil {
+		return spec, changed, cloudprovidererrors.TerminalError{
+			Reason:  common.InvalidConfigurationMachineError,
+			Message: fmt.Sprintf("Failed to parse MachineSpec, due to %v", err),
+		}
+	}
+
+	needsSubnet := c.SubnetID == "" && len(c.SubnetSelector) > 0
+	needsSubnetDetails := c.SubnetID != "" && (c.AvailabilityZone == "" || c.VpcID == "")
+	needsSecurityGroups := len(c.SecurityGroupIDs) == 0 && c.SecurityGroupSelector != nil
+	if !needsSubnet && !needsSubnetDetails && !needsSecurityGroups {
+		return spec, changed, nil
+	}
+
+	client, err := getEC2client(c)
+	if err != nil {
+		return spec, changed, err
+	}
+
+	var subnet *ec2.Subnet
+	if needsSubnet {
+		glog.V(4).Infof("Trying to select a subnet for machine '%s'...", spec.Name)
+		if subnet, err = findSubnet(client, c); err != nil {
+			return spec, changed, err
+		}
+		c.SubnetID = aws.StringValue(subnet.SubnetId)
+		glog.V(4).Infof("Selected subnet '%s' with %d free ips for machine '%s'", c.SubnetID, aws.Int64Value(subnet.AvailableIpAddressCount), spec.Name)
+		rawConfig.SubnetID.Value = c.SubnetID
+		changed = true
+	} else if needsSubnetDetails {
+		if subnet, err = getSubnet(client, c.SubnetID); err != nil {
+			return spec, changed, err
+		}
+	}
+
+	if subnet != nil {
+		if c.AvailabilityZone == "" {
+			c.AvailabilityZone = aws.StringValue(subnet.AvailabilityZone)
+			glog.V(4).Infof("Defaulted availability zone for machine '%s' to '%s'", spec.Name, c.AvailabilityZone)
+			rawConfig.AvailabilityZone.Value = c.AvailabilityZone
+			changed = true
+		}
+		if c.VpcID == "" {
+			c.VpcID = aws.StringValue(subnet.VpcId)
+			glog.V(4).Infof("Defaulted vpc for machine '%s' to '%s'", spec.Name, c.VpcID)
+			rawConfig.VpcID.Value = c.VpcID
+			changed = true
+		}
+	}
+
+	if needsSecurityGroups {
+		glog.V(4).Infof("Trying to select the security groups for machine '%s'...", spec.Name)
+		securityGroupIDs, err := findSecurityGroups(client, c.SecurityGroupSelector, c.VpcID)
+		if err != nil {
+			return spec, changed, err
+		}
+		glog.V(4).Infof("Selected security groups %v for machine '%s'", securityGroupIDs, spec.Name)
+		rawConfig.SecurityGroupIDs = nil
+		for _, id := range securityGroupIDs {
+			rawConfig.SecurityGroupIDs = append(rawConfig.SecurityGroupIDs, providerconfig.ConfigVarString{Value: id})
+		}
+		changed = true
+	}
+
+	if !changed {
+		return spec, changed, nil
+	}
+	spec.ProviderConfig.Value, err = setProviderConfig(*rawConfig, spec.ProviderConfig)
+	if err != nil {
+		return spec, changed, fmt.Errorf("error marshaling providerconfig: %v", err)
+	}
+	return spec, changed, nil
 }
 
 func (p *provider) Validate(spec v1alpha1.MachineSpec) error {
-	config, pc, err := p.getConfig(spec.ProviderConfig)
+	config, pc, _, err := p.getConfig(spec.ProviderConfig)
 	if err != nil {
 		return fmt.Errorf("failed to parse config: %v", err)
 	}
 
-	if _, osSupported := amiFilters[pc.OperatingSystem]; !osSupported {
+	if _, osSupported := defaultAMIs[pc.OperatingSystem]; !osSupported {
 		return fmt.Errorf("unsupported os %s", pc.OperatingSystem)
 	}
 
-	if !volumeTypes.Has(config.DiskType) {
+	if (config.LaunchTemplate == nil || config.DiskType != "") && !volumeTypes.Has(config.DiskType) {
 		return fmt.Errorf("invalid volume type %s specified. Supported: %s", config.DiskType, volumeTypes)
 	}
 
-	ec2Client, err := getEC2client(config.AccessKeyID, config.SecretAccessKey, config.Region)
+	if err := validateAMI(config); err != nil {
+		return err
+	}
+
+	if err := validateDataVolumes(config.DataVolumes); err != nil {
+		return err
+	}
+
+	if err := validateNetworkInterfaces(config); err != nil {
+		return err
+	}
+
+	if err := validatePlacement(config); err != nil {
+		return err
+	}
+
+	if err := validateInstanceOptions(config); err != nil {
+		return err
+	}
+
+	if err := validateCredentials(config); err != nil {
+		return err
+	}
+
+	if err := validateSelectors(config); err != nil {
+		return err
+	}
+
+	if ids := getClusterIDs(config.Tags); len(ids) > 1 {
+		return fmt.Errorf("the %s and %s<id> tags refer to different clusters %v", clusterTag, clusterIDTagPrefix, ids)
+	}
+
+	if config.SpotInstanceConfig != nil {
+		if config.SpotInstanceConfig.MaxPrice != "" {
+			if _, err := strconv.ParseFloat(config.SpotInstanceConfig.MaxPrice, 64); err != nil {
+				return fmt.Errorf("invalid spot max price %q specified: %v", config.SpotInstanceConfig.MaxPrice, err)
+			}
+		}
+		if config.SpotInstanceConfig.OnDemandFallbackAfter < 0 {
+			return fmt.Errorf("spot on-demand fallback must not be negative")
+		}
+	}
+
+	ec2Client, err := getEC2client(config)
 	if err != nil {
 		return fmt.Errorf("failed to create ec2 client: %v", err)
 	}
@@ -310,13 +614,38 @@ func (p *provider) Validate(spec v1alpha1.MachineSpec) error {
 		}
 	}
 
-	if _, err := getVpc(ec2Client, config.VpcID); err != nil {
-		return fmt.Errorf("invalid vpc %q specified: %v", config.VpcID, err)
+	instanceType := config.InstanceType
+	if config.LaunchTemplate != nil {
+		data, err := getLaunchTemplateData(ec2Client, config.LaunchTemplate)
+		if err != nil {
+			return fmt.Errorf("invalid launch template %s with version %s specified: %v", config.LaunchTemplate.ID, config.LaunchTemplate.Version, err)
+		}
+		if instanceType == "" {
+			instanceType = aws.StringValue(data.InstanceType)
+		}
+	}
+
+	if err := validateMountedDataVolumes(ec2Client, config.DataVolumes, instanceType); err != nil {
+		return err
 	}
 
-	_, err = ec2Client.DescribeAvailabilityZones(&ec2.DescribeAvailabilityZonesInput{ZoneNames: aws.StringSlice([]string{config.AvailabilityZone})})
-	if err != nil {
-		return fmt.Errorf("invalid zone %q specified: %v", config.AvailabilityZone, err)
+	if config.PlacementGroup != "" {
+		if err := validatePlacementGroup(ec2Client, config.PlacementGroup); err != nil {
+			return fmt.Errorf("invalid placement group %q specified: %v", config.PlacementGroup, err)
+		}
+	}
+
+	if config.LaunchTemplate == nil || config.VpcID != "" {
+		if _, err := getVpc(ec2Client, config.VpcID); err != nil {
+			return fmt.Errorf("invalid vpc %q specified: %v", config.VpcID, err)
+		}
+	}
+
+	if config.LaunchTemplate == nil || config.AvailabilityZone != "" {
+		_, err = ec2Client.DescribeAvailabilityZones(&ec2.DescribeAvailabilityZonesInput{ZoneNames: aws.StringSlice([]string{config.AvailabilityZone})})
+		if err != nil {
+			return fmt.Errorf("invalid zone %q specified: %v", config.AvailabilityZone, err)
+		}
 	}
 
 	_, err = ec2Client.DescribeRegions(&ec2.DescribeRegionsInput{RegionNames: aws.StringSlice([]string{config.Region})})
@@ -333,7 +662,24 @@ func (p *provider) Validate(spec v1alpha1.MachineSpec) error {
 		}
 	}
 
-	iamClient, err := getIAMclient(config.AccessKeyID, config.SecretAccessKey, config.Region)
+	for _, nic := range config.AdditionalNetworkInterfaces {
+		_, err := ec2Client.DescribeSubnets(&ec2.DescribeSubnetsInput{
+			SubnetIds: aws.StringSlice([]string{nic.SubnetID}),
+		})
+		if err != nil {
+			return fmt.Errorf("invalid subnet %q specified for additional network interface: %v", nic.SubnetID, err)
+		}
+		if len(nic.SecurityGroupIDs) > 0 {
+			_, err := ec2Client.DescribeSecurityGroups(&ec2.DescribeSecurityGroupsInput{
+				GroupIds: aws.StringSlice(nic.SecurityGroupIDs),
+			})
+			if err != nil {
+				return fmt.Errorf("failed to validate security group id's of additional network interface: %v", err)
+			}
+		}
+	}
+
+	iamClient, err := getIAMclient(config)
 	if err != nil {
 		return fmt.Errorf("failed to create iam client: %v", err)
 	}
@@ -504,7 +850,7 @@ func ensureDefaultInstanceProfileExists(client *iam.IAM) error {
 }
 
 func (p *provider) Create(machine *v1alpha1.Machine, update cloud.MachineUpdater, userdata string) (instance.Instance, error) {
-	config, pc, err := p.getConfig(machine.Spec.ProviderConfig)
+	config, pc, _, err := p.getConfig(machine.Spec.ProviderConfig)
 	if err != nil {
 		return nil, cloudprovidererrors.TerminalError{
 			Reason:  common.InvalidConfigurationMachineError,
@@ -512,18 +858,21 @@ func (p *provider) Create(machine *v1alpha1.Machine, update cloud.MachineUpdater
 		}
 	}
 
-	ec2Client, err := getEC2client(config.AccessKeyID, config.SecretAccessKey, config.Region)
+	ec2Client, err := getEC2client(config)
 	if err != nil {
 		return nil, err
 	}
 
-	iamClient, err := getIAMclient(config.AccessKeyID, config.SecretAccessKey, config.Region)
+	iamClient, err := getIAMclient(config)
 	if err != nil {
 		return nil, err
 	}
 
+	// Settings which are not configured get taken from the launch template instead of the defaults
+	usesLaunchTemplate := config.LaunchTemplate != nil
+
 	instanceProfileName := config.InstanceProfile
-	if instanceProfileName == "" {
+	if instanceProfileName == "" && !usesLaunchTemplate {
 		err = ensureDefaultInstanceProfileExists(iamClient)
 		if err != nil {
 			return nil, err
@@ -531,13 +880,12 @@ func (p *provider) Create(machine *v1alpha1.Machine, update cloud.MachineUpdater
 		instanceProfileName = defaultInstanceProfileName
 	}
 
-	vpc, err := getVpc(ec2Client, config.VpcID)
-	if err != nil {
-		return nil, err
-	}
-
 	securityGroupIDs := config.SecurityGroupIDs
-	if len(securityGroupIDs) == 0 {
+	if len(securityGroupIDs) == 0 && !usesLaunchTemplate {
+		vpc, err := getVpc(ec2Client, config.VpcID)
+		if err != nil {
+			return nil, err
+		}
 		sgID, err := ensureDefaultSecurityGroupExists(ec2Client, vpc)
 		if err != nil {
 			return nil, err
@@ -551,8 +899,8 @@ func (p *provider) Create(machine *v1alpha1.Machine, update cloud.MachineUpdater
 	}
 
 	amiID := config.AMI
-	if amiID == "" {
-		if amiID, err = getDefaultAMIID(ec2Client, pc.OperatingSystem); err != nil {
+	if amiID == "" && !usesLaunchTemplate {
+		if amiID, err = getAMIID(config, pc.OperatingSystem, ec2Client); err != nil {
 			if err != nil {
 				return nil, cloudprovidererrors.TerminalError{
 					Reason:  common.InvalidConfigurationMachineError,
@@ -581,35 +929,11 @@ func (p *provider) Create(machine *v1alpha1.Machine, update cloud.MachineUpdater
 	}
 
 	instanceRequest := &ec2.RunInstancesInput{
-		ImageId: aws.String(amiID),
-		BlockDeviceMappings: []*ec2.BlockDeviceMapping{
-			{
-				DeviceName: aws.String(rootDevicePath),
-				Ebs: &ec2.EbsBlockDevice{
-					VolumeSize:          aws.Int64(config.DiskSize),
-					DeleteOnTermination: aws.Bool(true),
-					VolumeType:          aws.String(config.DiskType),
-				},
-			},
-		},
-		MaxCount:     aws.Int64(1),
-		MinCount:     aws.Int64(1),
-		InstanceType: aws.String(config.InstanceType),
-		UserData:     aws.String(base64.StdEncoding.EncodeToString([]byte(userdata))),
-		Placement: &ec2.Placement{
-			AvailabilityZone: aws.String(config.AvailabilityZone),
-		},
-		NetworkInterfaces: []*ec2.InstanceNetworkInterfaceSpecification{
-			{
-				DeviceIndex:              aws.Int64(0), // eth0
-				AssociatePublicIpAddress: aws.Bool(true),
-				DeleteOnTermination:      aws.Bool(true),
-				SubnetId:                 aws.String(config.SubnetID),
-			},
-		},
-		IamInstanceProfile: &ec2.IamInstanceProfileSpecification{
-			Name: aws.String(instanceProfileName),
-		},
+		BlockDeviceMappings: getBlockDeviceMappings(config, rootDevicePath),
+		MaxCount:            aws.Int64(1),
+		MinCount:            aws.Int64(1),
+		UserData:            aws.String(base64.StdEncoding.EncodeToString([]byte(userdata))),
+		Placement:           getPlacement(config),
 		TagSpecifications: []*ec2.TagSpecification{
 			{
 				ResourceType: aws.String(ec2.ResourceTypeInstance),
@@ -617,29 +941,126 @@ func (p *provider) Create(machine *v1alpha1.Machine, update cloud.MachineUpdater
 			},
 		},
 	}
+	if amiID != "" {
+		instanceRequest.ImageId = aws.String(amiID)
+	}
+	if config.InstanceType != "" {
+		instanceRequest.InstanceType = aws.String(config.InstanceType)
+	}
+	if instanceProfileName != "" {
+		instanceRequest.IamInstanceProfile = &ec2.IamInstanceProfileSpecification{
+			Name: aws.String(instanceProfileName),
+		}
+	}
+	if usesLaunchTemplate && config.SubnetID == "" {
+		// The network interfaces of the launch template get used
+		if len(securityGroupIDs) > 0 {
+			instanceRequest.SecurityGroupIds = aws.StringSlice(securityGroupIDs)
+		}
+	} else {
+		instanceRequest.NetworkInterfaces = getNetworkInterfaceSpecifications(config, securityGroupIDs)
+	}
+	setInstanceOptions(config, instanceRequest)
+	if usesLaunchTemplate {
+		instanceRequest.LaunchTemplate = &ec2.LaunchTemplateSpecification{
+			LaunchTemplateId: aws.String(config.LaunchTemplate.ID),
+			Version:          aws.String(config.LaunchTemplate.Version),
+		}
+	}
 
-	runOut, err := ec2Client.RunInstances(instanceRequest)
+	purchaseType := purchaseTypeOnDemand
+	if useSpotInstance(config.SpotInstanceConfig, machine) {
+		purchaseType = purchaseTypeSpot
+		instanceRequest.InstanceMarketOptions = spotMarketOptions(config.SpotInstanceConfig)
+	}
+
+	machine, runOut, err := runInstance(ec2Client, instanceRequest, machine, update, purchaseType)
+	if err != nil && purchaseType == purchaseTypeSpot && isSpotCapacityError(err) {
+		glog.V(2).Infof("No spot capacity available for machine %s: %v", machine.Name, err)
+		machine, err = update(machine, func(m *v1alpha1.Machine) {
+			if m.Annotations == nil {
+				m.Annotations = map[string]string{}
+			}
+			m.Annotations[spotCapacityFailuresAnnotation] = strconv.Itoa(getSpotCapacityFailures(m) + 1)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to record spot capacity failure: %v", err)
+		}
+		if useSpotInstance(config.SpotInstanceConfig, machine) {
+			return nil, fmt.Errorf("no spot capacity available, %d of %d attempts failed", getSpotCapacityFailures(machine), config.SpotInstanceConfig.OnDemandFallbackAfter)
+		}
+
+		glog.V(2).Infof("Falling back to an on-demand instance for machine %s", machine.Name)
+		purchaseType = purchaseTypeOnDemand
+		instanceRequest.InstanceMarketOptions = nil
+		machine, runOut, err = runInstance(ec2Client, instanceRequest, machine, update, purchaseType)
+	}
 	if err != nil {
 		return nil, awsErrorToTerminalError(err, "failed create instance at aws")
 	}
 	awsInstance := &awsInstance{instance: runOut.Instances[0]}
 
-	// Change to our security group
-	_, err = ec2Client.ModifyInstanceAttribute(&ec2.ModifyInstanceAttributeInput{
-		InstanceId: runOut.Instances[0].InstanceId,
-		Groups:     aws.StringSlice(securityGroupIDs),
-	})
-	if err != nil {
-		delErr := p.Delete(machine, update)
-		if delErr != nil {
-			return nil, awsErrorToTerminalError(err, fmt.Sprintf("failed to attach instance %s to security group %s & delete the created instance", aws.StringValue(runOut.Instances[0].InstanceId), defaultSecurityGroupName))
+	if machine.Annotations[purchaseTypeAnnotation] != purchaseType {
+		machine, err = update(machine, func(m *v1alpha1.Machine) {
+			if m.Annotations == nil {
+				m.Annotations = map[string]string{}
+			}
+			m.Annotations[purchaseTypeAnnotation] = purchaseType
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to record purchase type of instance %s: %v", awsInstance.ID(), err)
 		}
-		return nil, awsErrorToTerminalError(err, fmt.Sprintf("failed to attach instance %s to security group %s", aws.StringValue(runOut.Instances[0].InstanceId), defaultSecurityGroupName))
 	}
 
 	return awsInstance, nil
 }
 
+// getClientToken returns the idempotency token for launching an instance for the machine.
+// Retries after a crash or timeout use the same token, so they don't launch a second instance.
+func getClientToken(machine *v1alpha1.Machine, purchaseType string) string {
+	token := string(machine.UID)
+	if purchaseType == purchaseTypeSpot {
+		// A fallback to an on-demand instance must not use the token of the spot request
+		token += "-spot"
+	}
+	if generation := machine.Annotations[launchGenerationAnnotation]; generation != "" {
+		token += "-" + generation
+	}
+	return token
+}
+
+// runInstance launches the instance using the client token of the machine.
+// When the token belongs to an instance which got terminated already, a new generation
+// of the token gets recorded on the machine and the instance gets launched again.
+func runInstance(client *ec2.EC2, input *ec2.RunInstancesInput, machine *v1alpha1.Machine, update cloud.MachineUpdater, purchaseType string) (*v1alpha1.Machine, *ec2.Reservation, error) {
+	input.ClientToken = aws.String(getClientToken(machine, purchaseType))
+	runOut, err := client.RunInstances(input)
+	if err != nil {
+		return machine, nil, err
+	}
+
+	state := runOut.Instances[0].State
+	if state == nil || (aws.StringValue(state.Name) != ec2.InstanceStateNameTerminated && aws.StringValue(state.Name) != ec2.InstanceStateNameShuttingDown) {
+		return machine, runOut, nil
+	}
+
+	glog.V(2).Infof("Instance %s of client token %s got terminated already, launching a new one", aws.StringValue(runOut.Instances[0].InstanceId), aws.StringValue(input.ClientToken))
+	machine, err = update(machine, func(m *v1alpha1.Machine) {
+		if m.Annotations == nil {
+			m.Annotations = map[string]string{}
+		}
+		generation, _ := strconv.Atoi(m.Annotations[launchGenerationAnnotation])
+		m.Annotations[launchGenerationAnnotation] = strconv.Itoa(generation + 1)
+	})
+	if err != nil {
+		return machine, nil, fmt.Errorf("failed to update launch generation: %v", err)
+	}
+
+	input.ClientToken = aws.String(getClientToken(machine, purchaseType))
+	runOut, err = client.RunInstances(input)
+	return machine, runOut, err
+}
+
 func (p *provider) Delete(machine *v1alpha1.Machine, _ cloud.MachineUpdater) error {
 	instance, err := p.Get(machine)
 	if err != nil {
@@ -649,7 +1070,7 @@ func (p *provider) Delete(machine *v1alpha1.Machine, _ cloud.MachineUpdater) err
 		return err
 	}
 
-	config, _, err := p.getConfig(machine.Spec.ProviderConfig)
+	config, _, _, err := p.getConfig(machine.Spec.ProviderConfig)
 	if err != nil {
 		return cloudprovidererrors.TerminalError{
 			Reason:  common.InvalidConfigurationMachineError,
@@ -657,11 +1078,15 @@ func (p *provider) Delete(machine *v1alpha1.Machine, _ cloud.MachineUpdater) err
 		}
 	}
 
-	ec2Client, err := getEC2client(config.AccessKeyID, config.SecretAccessKey, config.Region)
+	ec2Client, err := getEC2client(config)
 	if err != nil {
 		return err
 	}
 
+	if err := disableTerminationProtection(ec2Client, instance.ID()); err != nil {
+		return err
+	}
+
 	tOut, err := ec2Client.TerminateInstances(&ec2.TerminateInstancesInput{
 		InstanceIds: aws.StringSlice([]string{instance.ID()}),
 	})
@@ -677,7 +1102,7 @@ func (p *provider) Delete(machine *v1alpha1.Machine, _ cloud.MachineUpdater) err
 }
 
 func (p *provider) Get(machine *v1alpha1.Machine) (instance.Instance, error) {
-	config, _, err := p.getConfig(machine.Spec.ProviderConfig)
+	config, _, _, err := p.getConfig(machine.Spec.ProviderConfig)
 	if err != nil {
 		return nil, cloudprovidererrors.TerminalError{
 			Reason:  common.InvalidConfigurationMachineError,
@@ -685,7 +1110,7 @@ func (p *provider) Get(machine *v1alpha1.Machine) (instance.Instance, error) {
 		}
 	}
 
-	ec2Client, err := getEC2client(config.AccessKeyID, config.SecretAccessKey, config.Region)
+	ec2Client, err := getEC2client(config)
 	if err != nil {
 		return nil, err
 	}
@@ -703,7 +1128,8 @@ func (p *provider) Get(machine *v1alpha1.Machine) (instance.Instance, error) {
 	}
 
 	// We might have multiple instances (Maybe some old, terminated ones)
-	// Thus we need to find the instance which is not in the terminated state
+	// Thus we need to find the instances which are not in the terminated state
+	var instances []*ec2.Instance
 	for _, reservation := range inOut.Reservations {
 		for _, i := range reservation.Instances {
 			if i.State == nil || i.State.Name == nil {
@@ -714,28 +1140,147 @@ func (p *provider) Get(machine *v1alpha1.Machine) (instance.Instance, error) {
 				continue
 			}
 
-			return &awsInstance{
-				instance: i,
-			}, nil
+			instances = append(instances, i)
+		}
+	}
+
+	if len(instances) == 0 {
+		return nil, cloudprovidererrors.ErrInstanceNotFound
+	}
+
+	// Multiple instances mean a launch got repeated, e.g. after a crash of the controller.
+	// We keep the oldest active one and terminate the others.
+	sortInstances(instances)
+	if len(instances) > 1 {
+		if err := terminateDuplicates(ec2Client, machine, instances[1:]); err != nil {
+			return nil, err
 		}
 	}
 
-	return nil, cloudprovidererrors.ErrInstanceNotFound
+	return &awsInstance{
+		instance:    instances[0],
+		interrupted: isSpotInterrupted(ec2Client, instances[0]),
+	}, nil
+}
+
+// inactiveInstanceStates are the states of instances which are about to go away or don't run anymore
+var inactiveInstanceStates = sets.NewString(
+	ec2.InstanceStateNameShuttingDown,
+	ec2.InstanceStateNameStopping,
+	ec2.InstanceStateNameStopped,
+)
+
+// sortInstances orders the instances of a machine by their launch time, the active instances first.
+// An inactive instance only gets used when no active one is left.
+func sortInstances(instances []*ec2.Instance) {
+	sort.SliceStable(instances, func(i, j int) bool {
+		iInactive := inactiveInstanceStates.Has(aws.StringValue(instances[i].State.Name))
+		jInactive := inactiveInstanceStates.Has(aws.StringValue(instances[j].State.Name))
+		if iInactive != jInactive {
+			return jInactive
+		}
+		return aws.TimeValue(instances[i].LaunchTime).Before(aws.TimeValue(instances[j].LaunchTime))
+	})
+}
+
+func terminateDuplicates(client *ec2.EC2, machine *v1alpha1.Machine, duplicates []*ec2.Instance) error {
+	var ids []string
+	for _, i := range duplicates {
+		if aws.StringValue(i.State.Name) == ec2.InstanceStateNameShuttingDown {
+			continue
+		}
+		if err := disableTerminationProtection(client, aws.StringValue(i.InstanceId)); err != nil {
+			return err
+		}
+		ids = append(ids, aws.StringValue(i.InstanceId))
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	glog.V(2).Infof("Terminating duplicate instances %v of machine %s", ids, machine.Name)
+	if _, err := client.TerminateInstances(&ec2.TerminateInstancesInput{InstanceIds: aws.StringSlice(ids)}); err != nil {
+		return awsErrorToTerminalError(err, fmt.Sprintf("failed to terminate duplicate instances %v", ids))
+	}
+	recordEvent(machine, corev1.EventTypeWarning, "DuplicateInstances", fmt.Sprintf("Terminated duplicate instances %s", strings.Join(ids, ", ")))
+	return nil
 }
 
 func (p *provider) GetCloudConfig(spec v1alpha1.MachineSpec) (config string, name string, err error) {
-	return "", "aws", nil
+	c, _, _, err := p.getConfig(spec.ProviderConfig)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to parse config: %v", err)
+	}
+
+	cc := &CloudConfig{
+		Global: GlobalOpts{
+			Zone:                c.AvailabilityZone,
+			RoleARN:             c.CloudProviderRoleARN,
+			KubernetesClusterID: getClusterID(c.Tags),
+		},
+	}
+
+	s, err := CloudConfigToString(cc)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to convert the cloud-config to string: %v", err)
+	}
+
+	return s, "aws", nil
+}
+
+// getClusterID returns the id of the cluster from the "kubernetes.io/cluster/<id>" or the legacy "KubernetesCluster" tag.
+// Validate makes sure the tags don't refer to different clusters.
+func getClusterID(tags map[string]string) string {
+	ids := getClusterIDs(tags)
+	if len(ids) == 0 {
+		return ""
+	}
+	return ids[0]
+}
+
+// getClusterIDs returns the sorted ids of all clusters the tags refer to
+func getClusterIDs(tags map[string]string) []string {
+	ids := sets.NewString()
+	for key := range tags {
+		if strings.HasPrefix(key, clusterIDTagPrefix) && len(key) > len(clusterIDTagPrefix) {
+			ids.Insert(strings.TrimPrefix(key, clusterIDTagPrefix))
+		}
+	}
+	if id := tags[clusterTag]; id != "" {
+		ids.Insert(id)
+	}
+	return ids.List()
 }
 
 func (p *provider) MachineMetricsLabels(machine *v1alpha1.Machine) (map[string]string, error) {
 	labels := make(map[string]string)
 
-	c, _, err := p.getConfig(machine.Spec.ProviderConfig)
+	c, _, _, err := p.getConfig(machine.Spec.ProviderConfig)
 	if err == nil {
 		labels["size"] = c.InstanceType
 		labels["region"] = c.Region
 		labels["az"] = c.AvailabilityZone
 		labels["ami"] = c.AMI
+
+		purchaseType := machine.Annotations[purchaseTypeAnnotation]
+		if purchaseType == "" {
+			purchaseType = purchaseTypeOnDemand
+			if c.SpotInstanceConfig != nil {
+				purchaseType = purchaseTypeSpot
+			}
+		}
+		labels["purchaseType"] = purchaseType
+
+		tenancy := c.Tenancy
+		if tenancy == "" {
+			tenancy = ec2.TenancyDefault
+		}
+		labels["tenancy"] = tenancy
+		labels["placementGroup"] = c.PlacementGroup
+		if c.LaunchTemplate != nil {
+			labels["launchTemplate"] = c.LaunchTemplate.ID
+			labels["launchTemplateVersion"] = c.LaunchTemplate.Version
+		}
 	}
 
 	return labels, err
@@ -750,7 +1295,7 @@ func (p *provider) MigrateUID(machine *v1alpha1.Machine, new types.UID) error {
 		return fmt.Errorf("failed to get instance: %v", err)
 	}
 
-	config, _, err := p.getConfig(machine.Spec.ProviderConfig)
+	config, _, _, err := p.getConfig(machine.Spec.ProviderConfig)
 	if err != nil {
 		return cloudprovidererrors.TerminalError{
 			Reason:  common.InvalidConfigurationMachineError,
@@ -758,7 +1303,7 @@ func (p *provider) MigrateUID(machine *v1alpha1.Machine, new types.UID) error {
 		}
 	}
 
-	ec2Client, err := getEC2client(config.AccessKeyID, config.SecretAccessKey, config.Region)
+	ec2Client, err := getEC2client(config)
 	if err != nil {
 		return fmt.Errorf("failed to get EC2 client: %v", err)
 	}
@@ -775,6 +1320,8 @@ func (p *provider) MigrateUID(machine *v1alpha1.Machine, new types.UID) error {
 
 type awsInstance struct {
 	instance *ec2.Instance
+	// interrupted is set when the spot instance gets or got interrupted
+	interrupted bool
 }
 
 func (d *awsInstance) Name() string {
@@ -786,15 +1333,25 @@ func (d *awsInstance) ID() string {
 }
 
 func (d *awsInstance) Addresses() []string {
-	return []string{
+	addresses := []string{
 		aws.StringValue(d.instance.PublicIpAddress),
 		aws.StringValue(d.instance.PublicDnsName),
 		aws.StringValue(d.instance.PrivateIpAddress),
 		aws.StringValue(d.instance.PrivateDnsName),
 	}
+	primary := sets.NewString(addresses...)
+	for _, address := range getInterfaceAddresses(d.instance) {
+		if !primary.Has(address) {
+			addresses = append(addresses, address)
+		}
+	}
+	return addresses
 }
 
 func (d *awsInstance) Status() instance.Status {
+	if d.interrupted {
+		return instance.StatusInterrupted
+	}
 	switch *d.instance.State.Name {
 	case ec2.InstanceStateNameRunning:
 		return instance.StatusRunning
@@ -0,0 +1,109 @@
+package aws
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+func TestSortInstances(t *testing.T) {
+	launchTime := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	newInstance := func(id, state string, age time.Duration) *ec2.Instance {
+		return &ec2.Instance{
+			InstanceId: aws.String(id),
+			State:      &ec2.InstanceState{Name: aws.String(state)},
+			LaunchTime: aws.Time(launchTime.Add(-age)),
+		}
+	}
+
+	tests := []struct {
+		name      string
+		instances []*ec2.Instance
+		expected  []string
+	}{
+		{
+			name: "oldest first",
+			instances: []*ec2.Instance{
+				newInstance("i-new", ec2.InstanceStateNameRunning, time.Minute),
+				newInstance("i-old", ec2.InstanceStateNameRunning, time.Hour),
+			},
+			expected: []string{"i-old", "i-new"},
+		},
+		{
+			name: "active before inactive",
+			instances: []*ec2.Instance{
+				newInstance("i-shutting-down", ec2.InstanceStateNameShuttingDown, 3*time.Hour),
+				newInstance("i-stopped", ec2.InstanceStateNameStopped, 2*time.Hour),
+				newInstance("i-stopping", ec2.InstanceStateNameStopping, time.Hour),
+				newInstance("i-pending", ec2.InstanceStateNamePending, time.Minute),
+				newInstance("i-running", ec2.InstanceStateNameRunning, 30*time.Minute),
+			},
+			expected: []string{"i-running", "i-pending", "i-shutting-down", "i-stopped", "i-stopping"},
+		},
+		{
+			name: "only inactive",
+			instances: []*ec2.Instance{
+				newInstance("i-stopped", ec2.InstanceStateNameStopped, time.Minute),
+				newInstance("i-shutting-down", ec2.InstanceStateNameShuttingDown, time.Hour),
+			},
+			expected: []string{"i-shutting-down", "i-stopped"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sortInstances(test.instances)
+
+			var ids []string
+			for _, i := range test.instances {
+				ids = append(ids, aws.StringValue(i.InstanceId))
+			}
+			if len(ids) != len(test.expected) {
+				t.Fatalf("expected %v, got %v", test.expected, ids)
+			}
+			for i := range ids {
+				if ids[i] != test.expected[i] {
+					t.Fatalf("expected %v, got %v", test.expected, ids)
+				}
+			}
+		})
+	}
+}
+
+func TestGetClusterIDs(t *testing.T) {
+	tests := []struct {
+		name     string
+		tags     map[string]string
+		expected []string
+	}{
+		{name: "no tags", tags: nil, expected: nil},
+		{name: "legacy tag", tags: map[string]string{"KubernetesCluster": "my-cluster"}, expected: []string{"my-cluster"}},
+		{name: "cluster id tag", tags: map[string]string{"kubernetes.io/cluster/my-cluster": "owned"}, expected: []string{"my-cluster"}},
+		{
+			name:     "matching tags",
+			tags:     map[string]string{"KubernetesCluster": "my-cluster", "kubernetes.io/cluster/my-cluster": "owned"},
+			expected: []string{"my-cluster"},
+		},
+		{
+			name:     "conflicting tags",
+			tags:     map[string]string{"KubernetesCluster": "my-cluster", "kubernetes.io/cluster/other-cluster": "owned"},
+			expected: []string{"my-cluster", "other-cluster"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ids := getClusterIDs(test.tags)
+			if len(ids) != len(test.expected) {
+				t.Fatalf("expected %v, got %v", test.expected, ids)
+			}
+			for i := range ids {
+				if ids[i] != test.expected[i] {
+					t.Fatalf("expected %v, got %v", test.expected, ids)
+				}
+			}
+		})
+	}
+}
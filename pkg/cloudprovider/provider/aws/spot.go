@@ -0,0 +1,122 @@
+package aws
+
+import (
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/golang/glog"
+
+	"github.com/kubermatic/machine-controller/pkg/providerconfig"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+)
+
+const (
+	purchaseTypeSpot     = "spot"
+	purchaseTypeOnDemand = "on-demand"
+
+	// purchaseTypeAnnotation records on the machine how its instance got purchased
+	purchaseTypeAnnotation = "machine-controller/aws-purchase-type"
+	// spotCapacityFailuresAnnotation counts the failed attempts to launch a spot instance for the machine
+	spotCapacityFailuresAnnotation = "machine-controller/aws-spot-capacity-failures"
+
+	spotInstanceTerminationReason = "Server.SpotInstanceTermination"
+)
+
+var (
+	// spotCapacityErrorCodes are returned by RunInstances when no spot capacity is available for the given price
+	spotCapacityErrorCodes = sets.NewString(
+		"InsufficientInstanceCapacity",
+		"SpotMaxPriceTooLow",
+		"MaxSpotInstanceCountExceeded",
+		"InsufficientCapacity",
+	)
+
+	// spotInterruptionStatusCodes are the status codes of spot requests whose instance gets or got interrupted
+	spotInterruptionStatusCodes = sets.NewString(
+		"marked-for-termination",
+		"marked-for-stop",
+		"instance-terminated-by-price",
+		"instance-terminated-no-capacity",
+		"instance-terminated-capacity-oversubscribed",
+		"instance-stopped-by-price",
+		"instance-stopped-no-capacity",
+	)
+)
+
+type SpotInstanceRawConfig struct {
+	// MaxPrice is the maximum hourly price in USD, defaults to the on-demand price
+	MaxPrice providerconfig.ConfigVarString `json:"maxPrice"`
+	// OnDemandFallbackAfter is the number of capacity failures after which an on-demand instance gets launched.
+	// 0 disables the fallback.
+	OnDemandFallbackAfter int `json:"onDemandFallbackAfter"`
+}
+
+type SpotInstanceConfig struct {
+	MaxPrice              string
+	OnDemandFallbackAfter int
+}
+
+func spotMarketOptions(c *SpotInstanceConfig) *ec2.InstanceMarketOptionsRequest {
+	options := &ec2.SpotMarketOptions{
+		InstanceInterruptionBehavior: aws.String(ec2.InstanceInterruptionBehaviorTerminate),
+		SpotInstanceType:             aws.String(ec2.SpotInstanceTypeOneTime),
+	}
+	if c.MaxPrice != "" {
+		options.MaxPrice = aws.String(c.MaxPrice)
+	}
+	return &ec2.InstanceMarketOptionsRequest{
+		MarketType:  aws.String(ec2.MarketTypeSpot),
+		SpotOptions: options,
+	}
+}
+
+func isSpotCapacityError(err error) bool {
+	aerr, ok := err.(awserr.Error)
+	return ok && spotCapacityErrorCodes.Has(aerr.Code())
+}
+
+func getSpotCapacityFailures(machine *v1alpha1.Machine) int {
+	failures, _ := strconv.Atoi(machine.Annotations[spotCapacityFailuresAnnotation])
+	return failures
+}
+
+// useSpotInstance returns if a spot instance should be launched for the machine
+func useSpotInstance(c *SpotInstanceConfig, machine *v1alpha1.Machine) bool {
+	if c == nil {
+		return false
+	}
+	return c.OnDemandFallbackAfter == 0 || getSpotCapacityFailures(machine) < c.OnDemandFallbackAfter
+}
+
+// isSpotInterrupted returns if the spot instance gets or got interrupted by aws
+func isSpotInterrupted(client *ec2.EC2, i *ec2.Instance) bool {
+	if aws.StringValue(i.InstanceLifecycle) != ec2.InstanceLifecycleTypeSpot {
+		return false
+	}
+	if i.StateReason != nil && aws.StringValue(i.StateReason.Code) == spotInstanceTerminationReason {
+		return true
+	}
+	if i.SpotInstanceRequestId == nil {
+		return false
+	}
+
+	// The spot request already gets marked two minutes before the instance gets interrupted
+	out, err := client.DescribeSpotInstanceRequests(&ec2.DescribeSpotInstanceRequestsInput{
+		SpotInstanceRequestIds: []*string{i.SpotInstanceRequestId},
+	})
+	if err != nil {
+		glog.V(2).Infof("failed to get spot request %s of instance %s: %v", aws.StringValue(i.SpotInstanceRequestId), aws.StringValue(i.InstanceId), err)
+		return false
+	}
+	for _, request := range out.SpotInstanceRequests {
+		if request.Status != nil && spotInterruptionStatusCodes.Has(aws.StringValue(request.Status.Code)) {
+			return true
+		}
+	}
+	return false
+}
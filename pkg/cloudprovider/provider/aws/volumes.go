@@ -0,0 +1,253 @@
+package aws
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+
+	"github.com/kubermatic/machine-controller/pkg/providerconfig"
+	"github.com/kubermatic/machine-controller/pkg/userdata/cloud"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+)
+
+const (
+	defaultDataVolumeType       = ec2.VolumeTypeGp2
+	defaultDataVolumeFilesystem = "ext4"
+)
+
+var (
+	// dataVolumeDeviceNameRegex matches the device names recommended by aws for EBS volumes
+	dataVolumeDeviceNameRegex = regexp.MustCompile(`^/dev/(sd|xvd)[f-p]$`)
+
+	dataVolumeFilesystems = sets.NewString("ext4", "xfs")
+)
+
+type DataVolumeRawConfig struct {
+	// DeviceName is the name of the device the volume gets attached as, e.g. /dev/xvdf
+	DeviceName providerconfig.ConfigVarString `json:"deviceName"`
+	Size       int64                          `json:"size"`
+	Type       providerconfig.ConfigVarString `json:"type"`
+	// IOPS is required for io1 volumes
+	IOPS int64 `json:"iops,omitempty"`
+	// Encrypted volumes use the default aws/ebs key, unless a KMSKeyID is set
+	Encrypted bool                           `json:"encrypted,omitempty"`
+	KMSKeyID  providerconfig.ConfigVarString `json:"kmsKeyId,omitempty"`
+	// DeleteOnTermination defaults to true
+	DeleteOnTermination *bool `json:"deleteOnTermination,omitempty"`
+
+	// MountPath is the directory the volume gets mounted to, e.g. /var/lib/docker.
+	// The volume stays unformatted when it is empty.
+	MountPath  string `json:"mountPath,omitempty"`
+	Filesystem string `json:"filesystem,omitempty"`
+}
+
+type DataVolumeConfig struct {
+	DeviceName          string
+	Size                int64
+	Type                string
+	IOPS                int64
+	Encrypted           bool
+	KMSKeyID            string
+	DeleteOnTermination bool
+	MountPath           string
+	Filesystem          string
+}
+
+func (p *provider) getDataVolumeConfig(raw DataVolumeRawConfig) (*DataVolumeConfig, error) {
+	var err error
+	c := DataVolumeConfig{
+		Size:                raw.Size,
+		IOPS:                raw.IOPS,
+		Encrypted:           raw.Encrypted,
+		DeleteOnTermination: true,
+		MountPath:           raw.MountPath,
+		Filesystem:          raw.Filesystem,
+	}
+	c.DeviceName, err = p.configVarResolver.GetConfigVarStringValue(raw.DeviceName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the value of \"dataVolumes.deviceName\" field, error = %v", err)
+	}
+	c.Type, err = p.configVarResolver.GetConfigVarStringValue(raw.Type)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the value of \"dataVolumes.type\" field, error = %v", err)
+	}
+	if c.Type == "" {
+		c.Type = defaultDataVolumeType
+	}
+	c.KMSKeyID, err = p.configVarResolver.GetConfigVarStringValue(raw.KMSKeyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the value of \"dataVolumes.kmsKeyId\" field, error = %v", err)
+	}
+	if raw.DeleteOnTermination != nil {
+		c.DeleteOnTermination = *raw.DeleteOnTermination
+	}
+	if c.MountPath != "" && c.Filesystem == "" {
+		c.Filesystem = defaultDataVolumeFilesystem
+	}
+	return &c, nil
+}
+
+func validateDataVolumes(volumes []DataVolumeConfig) error {
+	deviceNames := sets.NewString()
+	mountPaths := sets.NewString()
+	for _, v := range volumes {
+		if !dataVolumeDeviceNameRegex.MatchString(v.DeviceName) {
+			return fmt.Errorf("invalid device name %q specified for data volume, must match %s", v.DeviceName, dataVolumeDeviceNameRegex)
+		}
+		if deviceNames.Has(v.DeviceName) {
+			return fmt.Errorf("device name %s is used by multiple data volumes", v.DeviceName)
+		}
+		deviceNames.Insert(v.DeviceName)
+
+		if v.Size <= 0 {
+			return fmt.Errorf("size of data volume %s must be greater than 0", v.DeviceName)
+		}
+		if !volumeTypes.Has(v.Type) {
+			return fmt.Errorf("invalid volume type %s specified for data volume %s. Supported: %s", v.Type, v.DeviceName, volumeTypes)
+		}
+		if v.Type == ec2.VolumeTypeIo1 && v.IOPS <= 0 {
+			return fmt.Errorf("iops must be specified for io1 data volume %s", v.DeviceName)
+		}
+		if v.Type != ec2.VolumeTypeIo1 && v.IOPS != 0 {
+			return fmt.Errorf("iops can only be specified for io1 volumes, data volume %s is of type %s", v.DeviceName, v.Type)
+		}
+
+		if v.MountPath == "" {
+			continue
+		}
+		if !path.IsAbs(v.MountPath) || path.Clean(v.MountPath) == "/" {
+			return fmt.Errorf("invalid mount path %q specified for data volume %s", v.MountPath, v.DeviceName)
+		}
+		if mountPaths.Has(path.Clean(v.MountPath)) {
+			return fmt.Errorf("mount path %s is used by multiple data volumes", v.MountPath)
+		}
+		mountPaths.Insert(path.Clean(v.MountPath))
+		if !dataVolumeFilesystems.Has(v.Filesystem) {
+			return fmt.Errorf("invalid filesystem %s specified for data volume %s. Supported: %s", v.Filesystem, v.DeviceName, dataVolumeFilesystems)
+		}
+	}
+	return nil
+}
+
+// validateMountedDataVolumes rejects data volumes with a mount path on Nitro based instance types.
+// Nitro exposes EBS volumes as NVMe devices (/dev/nvme1n1, ...), their names don't match the device name
+// of the volume, which the userdata uses to format and mount it.
+func validateMountedDataVolumes(client *ec2.EC2, volumes []DataVolumeConfig, instanceType string) error {
+	var mounted bool
+	for _, v := range volumes {
+		if v.MountPath != "" {
+			mounted = true
+		}
+	}
+	if !mounted || instanceType == "" {
+		return nil
+	}
+
+	out, err := client.DescribeInstanceTypes(&ec2.DescribeInstanceTypesInput{
+		InstanceTypes: aws.StringSlice([]string{instanceType}),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get instance type %s: %v", instanceType, err)
+	}
+	for _, t := range out.InstanceTypes {
+		if aws.StringValue(t.Hypervisor) == ec2.InstanceTypeHypervisorNitro {
+			return fmt.Errorf("data volumes with a mountPath are not supported on the Nitro based instance type %s", instanceType)
+		}
+	}
+	return nil
+}
+
+func getBlockDeviceMappings(c *Config, rootDevicePath string) []*ec2.BlockDeviceMapping {
+	var mappings []*ec2.BlockDeviceMapping
+	if root := getRootBlockDevice(c); root != nil {
+		mappings = append(mappings, &ec2.BlockDeviceMapping{
+			DeviceName: aws.String(rootDevicePath),
+			Ebs:        root,
+		})
+	}
+	for _, v := range c.DataVolumes {
+		volume := &ec2.EbsBlockDevice{
+			VolumeSize:          aws.Int64(v.Size),
+			DeleteOnTermination: aws.Bool(v.DeleteOnTermination),
+			VolumeType:          aws.String(v.Type),
+		}
+		if v.IOPS != 0 {
+			volume.Iops = aws.Int64(v.IOPS)
+		}
+		setEncryption(volume, v.Encrypted, v.KMSKeyID)
+
+		mappings = append(mappings, &ec2.BlockDeviceMapping{
+			DeviceName: aws.String(v.DeviceName),
+			Ebs:        volume,
+		})
+	}
+	return mappings
+}
+
+// getRootBlockDevice returns nil when a launch template is used and the root disk is not overridden
+func getRootBlockDevice(c *Config) *ec2.EbsBlockDevice {
+	if c.LaunchTemplate == nil {
+		root := &ec2.EbsBlockDevice{
+			VolumeSize:          aws.Int64(c.DiskSize),
+			DeleteOnTermination: aws.Bool(c.DiskDeleteOnTermination),
+			VolumeType:          aws.String(c.DiskType),
+		}
+		setEncryption(root, c.DiskEncrypted, c.DiskKMSKeyID)
+		return root
+	}
+
+	// Only the configured fields override the root disk of the launch template
+	if c.DiskSize == 0 && c.DiskType == "" && !c.DiskEncrypted && c.DiskKMSKeyID == "" {
+		return nil
+	}
+	root := &ec2.EbsBlockDevice{
+		DeleteOnTermination: aws.Bool(c.DiskDeleteOnTermination),
+	}
+	if c.DiskSize != 0 {
+		root.VolumeSize = aws.Int64(c.DiskSize)
+	}
+	if c.DiskType != "" {
+		root.VolumeType = aws.String(c.DiskType)
+	}
+	setEncryption(root, c.DiskEncrypted, c.DiskKMSKeyID)
+	return root
+}
+
+// setEncryption encrypts the volume with the given key or the default aws/ebs key.
+// A KMS key implies the encryption.
+func setEncryption(volume *ec2.EbsBlockDevice, encrypted bool, kmsKeyID string) {
+	if !encrypted && kmsKeyID == "" {
+		return
+	}
+	volume.Encrypted = aws.Bool(true)
+	if kmsKeyID != "" {
+		volume.KmsKeyId = aws.String(kmsKeyID)
+	}
+}
+
+// GetDataDisks returns the data volumes with a mount path, they get formatted and mounted by the userdata
+func (p *provider) GetDataDisks(spec v1alpha1.MachineSpec) ([]cloud.DataDisk, error) {
+	c, _, _, err := p.getConfig(spec.ProviderConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config: %v", err)
+	}
+
+	var disks []cloud.DataDisk
+	for _, v := range c.DataVolumes {
+		if v.MountPath == "" {
+			continue
+		}
+		disks = append(disks, cloud.DataDisk{
+			Device:     v.DeviceName,
+			Filesystem: v.Filesystem,
+			MountPath:  path.Clean(v.MountPath),
+		})
+	}
+	return disks, nil
+}
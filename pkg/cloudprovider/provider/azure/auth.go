@@ -0,0 +1,147 @@
+package azure
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2019-07-01/compute"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/adal"
+	"github.com/Azure/go-autorest/autorest/azure"
+	"github.com/Azure/go-autorest/autorest/azure/auth"
+	"golang.org/x/crypto/pkcs12"
+
+	"github.com/kubermatic/machine-controller/pkg/userdata/cloud"
+
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+)
+
+// clientCertificatePath is the path of the client certificate on the machines, the kubelets read it from there
+const clientCertificatePath = "/etc/kubernetes/azure-client-certificate.pfx"
+
+// userAssignedIdentityIDRegex matches the ids of user-assigned identities
+var userAssignedIdentityIDRegex = regexp.MustCompile(`(?i)^/subscriptions/[^/]+/resourceGroups/[^/]+/providers/Microsoft\.ManagedIdentity/userAssignedIdentities/[^/]+$`)
+
+// getAuthorizer returns an authorizer for the managed identity, the client certificate or the client secret,
+// in that order
+func getAuthorizer(c *config) (autorest.Authorizer, error) {
+	if c.UseManagedIdentity {
+		msiConfig := auth.NewMSIConfig()
+		msiConfig.ClientID = c.UserAssignedIdentityID
+		return msiConfig.Authorizer()
+	}
+	if c.ClientCertificate != "" {
+		return getClientCertificateAuthorizer(c)
+	}
+	return auth.NewClientCredentialsConfig(c.ClientID, c.ClientSecret, c.TenantID).Authorizer()
+}
+
+func getClientCertificateAuthorizer(c *config) (autorest.Authorizer, error) {
+	certificate, privateKey, err := decodeClientCertificate(c)
+	if err != nil {
+		return nil, err
+	}
+
+	oauthConfig, err := adal.NewOAuthConfig(azure.PublicCloud.ActiveDirectoryEndpoint, c.TenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create oauth config: %v", err)
+	}
+	token, err := adal.NewServicePrincipalTokenFromCertificate(*oauthConfig, c.ClientID, certificate, privateKey, azure.PublicCloud.ResourceManagerEndpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get oauth token from client certificate: %v", err)
+	}
+	return autorest.NewBearerAuthorizer(token), nil
+}
+
+// decodeClientCertificate decodes the base64 encoded PKCS#12 bundle of the client certificate
+func decodeClientCertificate(c *config) (*x509.Certificate, *rsa.PrivateKey, error) {
+	pfx, err := base64.StdEncoding.DecodeString(c.ClientCertificate)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decode the client certificate: %v", err)
+	}
+	privateKey, certificate, err := pkcs12.Decode(pfx, c.ClientCertificatePassword)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decode the PKCS#12 bundle of the client certificate: %v", err)
+	}
+	rsaPrivateKey, ok := privateKey.(*rsa.PrivateKey)
+	if !ok {
+		return nil, nil, errors.New("the client certificate must contain an RSA private key")
+	}
+	return certificate, rsaPrivateKey, nil
+}
+
+func validateCredentials(c *config) error {
+	credentials := 0
+	for _, set := range []bool{c.ClientSecret != "", c.ClientCertificate != "", c.UseManagedIdentity} {
+		if set {
+			credentials++
+		}
+	}
+	if credentials == 0 {
+		return errors.New("one of clientSecret, clientCertificate or useManagedIdentity is required")
+	}
+	if credentials > 1 {
+		return errors.New("only one of clientSecret, clientCertificate and useManagedIdentity can be specified")
+	}
+
+	if c.UseManagedIdentity {
+		// the kubelets use the userAssignedIdentityID as well, so it has to be the identity of the VMs
+		if (c.UserAssignedIdentityID == "") != (c.VMIdentityID == "") {
+			return errors.New("userAssignedIdentityID and vmIdentityID have to be specified together")
+		}
+		if c.VMIdentityID != "" && !userAssignedIdentityIDRegex.MatchString(c.VMIdentityID) {
+			return fmt.Errorf("vmIdentityID %q is not the id of a user-assigned identity", c.VMIdentityID)
+		}
+		return nil
+	}
+	if c.UserAssignedIdentityID != "" || c.VMIdentityID != "" {
+		return errors.New("userAssignedIdentityID and vmIdentityID require useManagedIdentity")
+	}
+	if c.ClientID == "" {
+		return errors.New("clientID is missing")
+	}
+	if c.ClientCertificate != "" {
+		if _, _, err := decodeClientCertificate(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getVMIdentity returns the managed identity of the VM, the kubelets authenticate with it.
+// VMs get a system-assigned identity unless the id of a user-assigned identity is configured.
+func getVMIdentity(c *config) *compute.VirtualMachineIdentity {
+	if !c.UseManagedIdentity {
+		return nil
+	}
+	if c.VMIdentityID == "" {
+		return &compute.VirtualMachineIdentity{Type: compute.ResourceIdentityTypeSystemAssigned}
+	}
+	return &compute.VirtualMachineIdentity{
+		Type: compute.ResourceIdentityTypeUserAssigned,
+		UserAssignedIdentities: map[string]*compute.VirtualMachineIdentityUserAssignedIdentitiesValue{
+			c.VMIdentityID: {},
+		},
+	}
+}
+
+// GetCloudConfigFiles returns the client certificate, the cloud config refers to it
+func (p *provider) GetCloudConfigFiles(spec v1alpha1.MachineSpec) ([]cloud.CloudConfigFile, error) {
+	c, _, err := p.getConfig(spec.ProviderConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config: %v", err)
+	}
+	if c.UseManagedIdentity || c.ClientCertificate == "" {
+		return nil, nil
+	}
+
+	pfx, err := base64.StdEncoding.DecodeString(c.ClientCertificate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode the client certificate: %v", err)
+	}
+	return []cloud.CloudConfigFile{{Path: clientCertificatePath, Content: string(pfx)}}, nil
+}
@@ -12,12 +12,19 @@ type CloudConfig struct {
 	AADClientID     string `json:"aadClientId"`
 	AADClientSecret string `json:"aadClientSecret"`
 
+	AADClientCertPath           string `json:"aadClientCertPath,omitempty"`
+	AADClientCertPassword       string `json:"aadClientCertPassword,omitempty"`
+	UseManagedIdentityExtension bool   `json:"useManagedIdentityExtension,omitempty"`
+	UserAssignedIdentityID      string `json:"userAssignedIdentityID,omitempty"`
+
 	ResourceGroup  string `json:"resourceGroup"`
 	Location       string `json:"location"`
 	VNetName       string `json:"vnetName"`
 	SubnetName     string `json:"subnetName"`
 	RouteTableName string `json:"routeTableName"`
 
+	SecurityGroupName string `json:"securityGroupName,omitempty"`
+
 	UseInstanceMetadata bool `json:"useInstanceMetadata"`
 }
 
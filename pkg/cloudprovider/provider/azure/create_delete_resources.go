@@ -4,7 +4,7 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2018-04-01/compute"
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2019-07-01/compute"
 	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2018-04-01/network"
 	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/golang/glog"
@@ -41,7 +41,7 @@ func deleteInterfacesByMachineUID(ctx context.Context, c *config, machineUID typ
 				return err
 			}
 
-			if err = future.WaitForCompletion(ctx, ifClient.Client); err != nil {
+			if err = future.WaitForCompletionRef(ctx, ifClient.Client); err != nil {
 				return err
 			}
 		}
@@ -80,7 +80,7 @@ func deleteIPAddressesByMachineUID(ctx context.Context, c *config, machineUID ty
 				return err
 			}
 
-			if err = future.WaitForCompletion(ctx, ipClient.Client); err != nil {
+			if err = future.WaitForCompletionRef(ctx, ipClient.Client); err != nil {
 				return err
 			}
 		}
@@ -116,7 +116,7 @@ func deleteVMsByMachineUID(ctx context.Context, c *config, machineUID types.UID)
 				return err
 			}
 
-			if err = future.WaitForCompletion(ctx, vmClient.Client); err != nil {
+			if err = future.WaitForCompletionRef(ctx, vmClient.Client); err != nil {
 				return err
 			}
 		}
@@ -142,7 +142,7 @@ func deleteDisksByMachineUID(ctx context.Context, c *config, machineUID types.UI
 			return fmt.Errorf("failed to delete disk %s: %v", *disk.Name, err)
 		}
 
-		if err = future.WaitForCompletion(ctx, disksClient.Client); err != nil {
+		if err = future.WaitForCompletionRef(ctx, disksClient.Client); err != nil {
 			return fmt.Errorf("failed to wait for deletion of disk %s: %v", *disk.Name, err)
 		}
 	}
@@ -188,14 +188,19 @@ func createOrUpdatePublicIPAddress(ctx context.Context, ipName string, machineUI
 			PublicIPAddressVersion:   network.IPv4,
 			PublicIPAllocationMethod: network.Static,
 		},
-		Tags: map[string]*string{machineUIDTag: to.StringPtr(string(machineUID))},
+		Tags:  map[string]*string{machineUIDTag: to.StringPtr(string(machineUID))},
+		Zones: getZones(c),
+	}
+	if c.Zone != "" {
+		// zonal public IPs are only available with the standard sku
+		ipParams.Sku = &network.PublicIPAddressSku{Name: network.PublicIPAddressSkuNameStandard}
 	}
 	future, err := ipClient.CreateOrUpdate(ctx, c.ResourceGroup, ipName, ipParams)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create public IP address: %v", err)
 	}
 
-	err = future.WaitForCompletion(ctx, ipClient.Client)
+	err = future.WaitForCompletionRef(ctx, ipClient.Client)
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve public IP address creation result: %v", err)
 	}
@@ -223,12 +228,16 @@ func getPublicIPAddress(ctx context.Context, ipName string, resourceGroup string
 }
 
 func getSubnet(ctx context.Context, c *config) (network.Subnet, error) {
+	return getSubnetByName(ctx, c, c.SubnetName)
+}
+
+func getSubnetByName(ctx context.Context, c *config, subnetName string) (network.Subnet, error) {
 	subnetsClient, err := getSubnetsClient(c)
 	if err != nil {
 		return network.Subnet{}, fmt.Errorf("failed to create subnets client: %v", err)
 	}
 
-	return subnetsClient.Get(ctx, c.ResourceGroup, c.VNetName, c.SubnetName, "")
+	return subnetsClient.Get(ctx, c.ResourceGroup, c.VNetName, subnetName, "")
 }
 
 func getVirtualNetwork(ctx context.Context, c *config) (network.VirtualNetwork, error) {
@@ -240,41 +249,51 @@ func getVirtualNetwork(ctx context.Context, c *config) (network.VirtualNetwork,
 	return virtualNetworksClient.Get(ctx, c.ResourceGroup, c.VNetName, "")
 }
 
-func createOrUpdateNetworkInterface(ctx context.Context, ifName string, machineUID types.UID, config *config, publicIP *network.PublicIPAddress) (network.Interface, error) {
+func createOrUpdateNetworkInterface(ctx context.Context, ifName string, machineUID types.UID, config *config, ifConfig interfaceConfig, publicIP *network.PublicIPAddress, securityGroup *network.SecurityGroup) (network.Interface, error) {
 	ifClient, err := getInterfacesClient(config)
 	if err != nil {
 		return network.Interface{}, fmt.Errorf("failed to create interfaces client: %v", err)
 	}
 
-	subnet, err := getSubnet(ctx, config)
+	subnet, err := getSubnetByName(ctx, config, ifConfig.SubnetName)
 	if err != nil {
 		return network.Interface{}, fmt.Errorf("failed to fetch subnet: %v", err)
 	}
 
+	ipConfig := &network.InterfaceIPConfigurationPropertiesFormat{
+		Subnet:                    &subnet,
+		PrivateIPAllocationMethod: network.Dynamic,
+		PublicIPAddress:           publicIP,
+	}
+	if ifConfig.PrivateIPAddress != "" {
+		ipConfig.PrivateIPAllocationMethod = network.Static
+		ipConfig.PrivateIPAddress = to.StringPtr(ifConfig.PrivateIPAddress)
+	}
+
 	ifSpec := network.Interface{
 		Name:     to.StringPtr(ifName),
 		Location: &config.Location,
 		InterfacePropertiesFormat: &network.InterfacePropertiesFormat{
 			IPConfigurations: &[]network.InterfaceIPConfiguration{
 				{
-					Name: to.StringPtr("ip-config-1"),
-					InterfaceIPConfigurationPropertiesFormat: &network.InterfaceIPConfigurationPropertiesFormat{
-						Subnet:                    &subnet,
-						PrivateIPAllocationMethod: network.Dynamic,
-						PublicIPAddress:           publicIP,
-					},
+					Name:                                     to.StringPtr("ip-config-1"),
+					InterfaceIPConfigurationPropertiesFormat: ipConfig,
 				},
 			},
+			EnableAcceleratedNetworking: to.BoolPtr(config.EnableAcceleratedNetworking),
 		},
 		Tags: map[string]*string{machineUIDTag: to.StringPtr(string(machineUID))},
 	}
+	if securityGroup != nil {
+		ifSpec.NetworkSecurityGroup = &network.SecurityGroup{ID: securityGroup.ID}
+	}
 	glog.Infof("Creating/Updating public network interface %q", ifName)
 	future, err := ifClient.CreateOrUpdate(ctx, config.ResourceGroup, ifName, ifSpec)
 	if err != nil {
 		return network.Interface{}, fmt.Errorf("failed to create interface: %v", err)
 	}
 
-	err = future.WaitForCompletion(ctx, ifClient.Client)
+	err = future.WaitForCompletionRef(ctx, ifClient.Client)
 	if err != nil {
 		return network.Interface{}, fmt.Errorf("failed to get interface creation response: %v", err)
 	}
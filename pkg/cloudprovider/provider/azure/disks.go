@@ -0,0 +1,294 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2019-07-01/compute"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/golang/glog"
+
+	"github.com/kubermatic/machine-controller/pkg/providerconfig"
+	"github.com/kubermatic/machine-controller/pkg/userdata/cloud"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+)
+
+const (
+	defaultDataDiskSKU        = compute.StorageAccountTypesStandardLRS
+	defaultDataDiskFilesystem = "ext4"
+
+	maxDataDiskLUN = 63
+)
+
+var (
+	diskSKUs = sets.NewString(
+		string(compute.StorageAccountTypesStandardLRS),
+		string(compute.StorageAccountTypesPremiumLRS),
+		string(compute.StorageAccountTypesStandardSSDLRS),
+	)
+	diskCachingTypes = sets.NewString(
+		string(compute.CachingTypesNone),
+		string(compute.CachingTypesReadOnly),
+		string(compute.CachingTypesReadWrite),
+	)
+	dataDiskFilesystems = sets.NewString("ext4", "xfs")
+)
+
+type DataDiskRawConfig struct {
+	// Size in GB
+	Size int32 `json:"size"`
+	// SKU is one of Standard_LRS, StandardSSD_LRS or Premium_LRS, defaults to Standard_LRS
+	SKU providerconfig.ConfigVarString `json:"sku,omitempty"`
+	// Caching is one of None, ReadOnly or ReadWrite
+	Caching providerconfig.ConfigVarString `json:"caching,omitempty"`
+	// LUN defaults to the index of the disk
+	LUN *int32 `json:"lun,omitempty"`
+
+	// MountPath is the directory the disk gets mounted to, e.g. /var/lib/docker.
+	// The disk stays unformatted when it is empty.
+	MountPath  string `json:"mountPath,omitempty"`
+	Filesystem string `json:"filesystem,omitempty"`
+}
+
+type dataDiskConfig struct {
+	Size       int32
+	SKU        string
+	Caching    string
+	LUN        int32
+	MountPath  string
+	Filesystem string
+}
+
+func (p *provider) getDataDiskConfig(raw DataDiskRawConfig, index int) (*dataDiskConfig, error) {
+	var err error
+	c := dataDiskConfig{
+		Size:       raw.Size,
+		LUN:        int32(index),
+		MountPath:  raw.MountPath,
+		Filesystem: raw.Filesystem,
+	}
+	c.SKU, err = p.configVarResolver.GetConfigVarStringValue(raw.SKU)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the value of \"dataDisks.sku\" field, error = %v", err)
+	}
+	if c.SKU == "" {
+		c.SKU = string(defaultDataDiskSKU)
+	}
+	c.Caching, err = p.configVarResolver.GetConfigVarStringValue(raw.Caching)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the value of \"dataDisks.caching\" field, error = %v", err)
+	}
+	if raw.LUN != nil {
+		c.LUN = *raw.LUN
+	}
+	if c.MountPath != "" && c.Filesystem == "" {
+		c.Filesystem = defaultDataDiskFilesystem
+	}
+	return &c, nil
+}
+
+func validateDisks(c *config) error {
+	if c.OSDiskSize < 0 {
+		return fmt.Errorf("osDiskSize must not be negative")
+	}
+	if c.OSDiskSKU != "" && !diskSKUs.Has(c.OSDiskSKU) {
+		return fmt.Errorf("invalid os disk sku %s specified. Supported: %s", c.OSDiskSKU, diskSKUs)
+	}
+
+	luns := sets.NewInt()
+	mountPaths := sets.NewString()
+	for _, d := range c.DataDisks {
+		if d.LUN < 0 || d.LUN > maxDataDiskLUN {
+			return fmt.Errorf("lun %d of data disk must be between 0 and %d", d.LUN, maxDataDiskLUN)
+		}
+		if luns.Has(int(d.LUN)) {
+			return fmt.Errorf("lun %d is used by multiple data disks", d.LUN)
+		}
+		luns.Insert(int(d.LUN))
+
+		if d.Size <= 0 {
+			return fmt.Errorf("size of data disk %d must be greater than 0", d.LUN)
+		}
+		if !diskSKUs.Has(d.SKU) {
+			return fmt.Errorf("invalid sku %s specified for data disk %d. Supported: %s", d.SKU, d.LUN, diskSKUs)
+		}
+		if d.Caching != "" && !diskCachingTypes.Has(d.Caching) {
+			return fmt.Errorf("invalid caching %s specified for data disk %d. Supported: %s", d.Caching, d.LUN, diskCachingTypes)
+		}
+
+		if d.MountPath == "" {
+			continue
+		}
+		if !path.IsAbs(d.MountPath) || path.Clean(d.MountPath) == "/" {
+			return fmt.Errorf("invalid mount path %q specified for data disk %d", d.MountPath, d.LUN)
+		}
+		if mountPaths.Has(path.Clean(d.MountPath)) {
+			return fmt.Errorf("mount path %s is used by multiple data disks", d.MountPath)
+		}
+		mountPaths.Insert(path.Clean(d.MountPath))
+		if !dataDiskFilesystems.Has(d.Filesystem) {
+			return fmt.Errorf("invalid filesystem %s specified for data disk %d. Supported: %s", d.Filesystem, d.LUN, dataDiskFilesystems)
+		}
+	}
+	return nil
+}
+
+func getOSDiskName(machineName string) string {
+	return machineName + "-os-disk"
+}
+
+func getDataDiskName(machineName string, lun int32) string {
+	return fmt.Sprintf("%s-data-disk-%d", machineName, lun)
+}
+
+func getOSDisk(c *config, machineName string) *compute.OSDisk {
+	osDisk := &compute.OSDisk{
+		Name:         to.StringPtr(getOSDiskName(machineName)),
+		CreateOption: compute.DiskCreateOptionTypesFromImage,
+	}
+	if c.OSDiskSize != 0 {
+		osDisk.DiskSizeGB = to.Int32Ptr(c.OSDiskSize)
+	}
+	if c.OSDiskSKU != "" {
+		osDisk.ManagedDisk = &compute.ManagedDiskParameters{StorageAccountType: compute.StorageAccountTypes(c.OSDiskSKU)}
+	}
+	return osDisk
+}
+
+// createOrUpdateDataDisks creates the data disks tagged with the machine's UID, so they get removed
+// by deleteDisksByMachineUID. Disks which get created together with the VM don't get tagged.
+func createOrUpdateDataDisks(ctx context.Context, c *config, machineName string, machineUID types.UID) ([]compute.DataDisk, error) {
+	if len(c.DataDisks) == 0 {
+		return nil, nil
+	}
+
+	disksClient, err := getDisksClient(c)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get disks client: %v", err)
+	}
+
+	var dataDisks []compute.DataDisk
+	for _, d := range c.DataDisks {
+		diskName := getDataDiskName(machineName, d.LUN)
+		glog.Infof("Creating/Updating data disk %q", diskName)
+		future, err := disksClient.CreateOrUpdate(ctx, c.ResourceGroup, diskName, compute.Disk{
+			Location: to.StringPtr(c.Location),
+			Sku:      &compute.DiskSku{Name: compute.DiskStorageAccountTypes(d.SKU)},
+			DiskProperties: &compute.DiskProperties{
+				CreationData: &compute.CreationData{CreateOption: compute.Empty},
+				DiskSizeGB:   to.Int32Ptr(d.Size),
+			},
+			Tags:  getDiskTags(c, machineUID),
+			Zones: getZones(c),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create data disk %s: %v", diskName, err)
+		}
+		if err = future.WaitForCompletionRef(ctx, disksClient.Client); err != nil {
+			return nil, fmt.Errorf("failed to wait for creation of data disk %s: %v", diskName, err)
+		}
+		disk, err := future.Result(*disksClient)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get creation result of data disk %s: %v", diskName, err)
+		}
+
+		dataDisk := compute.DataDisk{
+			Lun:          to.Int32Ptr(d.LUN),
+			Name:         to.StringPtr(diskName),
+			CreateOption: compute.DiskCreateOptionTypesAttach,
+			ManagedDisk:  &compute.ManagedDiskParameters{ID: disk.ID},
+		}
+		if d.Caching != "" {
+			dataDisk.Caching = compute.CachingTypes(d.Caching)
+		}
+		dataDisks = append(dataDisks, dataDisk)
+	}
+	return dataDisks, nil
+}
+
+// tagOSDisk tags the os disk with the machine's UID after the VM got created, so it gets removed
+// by deleteDisksByMachineUID
+func tagOSDisk(ctx context.Context, c *config, machineName string, machineUID types.UID) error {
+	disksClient, err := getDisksClient(c)
+	if err != nil {
+		return fmt.Errorf("failed to get disks client: %v", err)
+	}
+
+	diskName := getOSDiskName(machineName)
+	future, err := disksClient.Update(ctx, c.ResourceGroup, diskName, compute.DiskUpdate{Tags: getDiskTags(c, machineUID)})
+	if err != nil {
+		return fmt.Errorf("failed to tag os disk %s: %v", diskName, err)
+	}
+	if err = future.WaitForCompletionRef(ctx, disksClient.Client); err != nil {
+		return fmt.Errorf("failed to wait for tagging of os disk %s: %v", diskName, err)
+	}
+	return nil
+}
+
+// deleteOSDisk removes the os disk by its name. The disk doesn't carry the machine's UID tag
+// when the controller stopped between the creation of the VM and tagOSDisk.
+// Disks tagged with the UID of another machine are left alone.
+func deleteOSDisk(ctx context.Context, c *config, machineName string, machineUID types.UID) error {
+	disksClient, err := getDisksClient(c)
+	if err != nil {
+		return fmt.Errorf("failed to get disks client: %v", err)
+	}
+
+	diskName := getOSDiskName(machineName)
+	disk, err := disksClient.Get(ctx, c.ResourceGroup, diskName)
+	if err != nil {
+		if isNotFound(disk.Response) {
+			return nil
+		}
+		return fmt.Errorf("failed to get os disk %s: %v", diskName, err)
+	}
+	if uid := disk.Tags[machineUIDTag]; uid != nil && *uid != string(machineUID) {
+		glog.Infof("Not deleting os disk %s, it belongs to machine %s", diskName, *uid)
+		return nil
+	}
+
+	future, err := disksClient.Delete(ctx, c.ResourceGroup, diskName)
+	if err != nil {
+		return fmt.Errorf("failed to delete os disk %s: %v", diskName, err)
+	}
+	if err = future.WaitForCompletionRef(ctx, disksClient.Client); err != nil {
+		return fmt.Errorf("failed to wait for deletion of os disk %s: %v", diskName, err)
+	}
+	return nil
+}
+
+func getDiskTags(c *config, machineUID types.UID) map[string]*string {
+	tags := make(map[string]*string, len(c.Tags)+1)
+	for k, v := range c.Tags {
+		tags[k] = to.StringPtr(v)
+	}
+	tags[machineUIDTag] = to.StringPtr(string(machineUID))
+	return tags
+}
+
+// GetDataDisks returns the data disks with a mount path, they get formatted and mounted by the userdata.
+// The devices are the symlinks the Azure Linux agent creates per LUN.
+func (p *provider) GetDataDisks(spec v1alpha1.MachineSpec) ([]cloud.DataDisk, error) {
+	c, _, err := p.getConfig(spec.ProviderConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config: %v", err)
+	}
+
+	var disks []cloud.DataDisk
+	for _, d := range c.DataDisks {
+		if d.MountPath == "" {
+			continue
+		}
+		disks = append(disks, cloud.DataDisk{
+			Device:     fmt.Sprintf("/dev/disk/azure/scsi1/lun%d", d.LUN),
+			Filesystem: d.Filesystem,
+			MountPath:  path.Clean(d.MountPath),
+		})
+	}
+	return disks, nil
+}
@@ -3,15 +3,15 @@ package azure
 import (
 	"fmt"
 
-	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2018-04-01/compute"
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2019-07-01/compute"
+	"github.com/Azure/azure-sdk-for-go/services/marketplaceordering/mgmt/2015-06-01/marketplaceordering"
 	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2018-04-01/network"
-	"github.com/Azure/go-autorest/autorest/azure/auth"
 )
 
 func getIPClient(c *config) (*network.PublicIPAddressesClient, error) {
 	var err error
 	ipClient := network.NewPublicIPAddressesClient(c.SubscriptionID)
-	ipClient.Authorizer, err = auth.NewClientCredentialsConfig(c.ClientID, c.ClientSecret, c.TenantID).Authorizer()
+	ipClient.Authorizer, err = getAuthorizer(c)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create authorizer: %s", err.Error())
 	}
@@ -22,7 +22,7 @@ func getIPClient(c *config) (*network.PublicIPAddressesClient, error) {
 func getSubnetsClient(c *config) (*network.SubnetsClient, error) {
 	var err error
 	subnetClient := network.NewSubnetsClient(c.SubscriptionID)
-	subnetClient.Authorizer, err = auth.NewClientCredentialsConfig(c.ClientID, c.ClientSecret, c.TenantID).Authorizer()
+	subnetClient.Authorizer, err = getAuthorizer(c)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create authorizer: %s", err.Error())
 	}
@@ -33,7 +33,7 @@ func getSubnetsClient(c *config) (*network.SubnetsClient, error) {
 func getVirtualNetworksClient(c *config) (*network.VirtualNetworksClient, error) {
 	var err error
 	virtualNetworksClient := network.NewVirtualNetworksClient(c.SubscriptionID)
-	virtualNetworksClient.Authorizer, err = auth.NewClientCredentialsConfig(c.ClientID, c.ClientSecret, c.TenantID).Authorizer()
+	virtualNetworksClient.Authorizer, err = getAuthorizer(c)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create authorizer: %v", err)
 	}
@@ -43,7 +43,7 @@ func getVirtualNetworksClient(c *config) (*network.VirtualNetworksClient, error)
 func getVMClient(c *config) (*compute.VirtualMachinesClient, error) {
 	var err error
 	vmClient := compute.NewVirtualMachinesClient(c.SubscriptionID)
-	vmClient.Authorizer, err = auth.NewClientCredentialsConfig(c.ClientID, c.ClientSecret, c.TenantID).Authorizer()
+	vmClient.Authorizer, err = getAuthorizer(c)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create authorizer: %s", err.Error())
 	}
@@ -54,7 +54,7 @@ func getVMClient(c *config) (*compute.VirtualMachinesClient, error) {
 func getInterfacesClient(c *config) (*network.InterfacesClient, error) {
 	var err error
 	ifClient := network.NewInterfacesClient(c.SubscriptionID)
-	ifClient.Authorizer, err = auth.NewClientCredentialsConfig(c.ClientID, c.ClientSecret, c.TenantID).Authorizer()
+	ifClient.Authorizer, err = getAuthorizer(c)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create authorizer: %s", err.Error())
 	}
@@ -62,13 +62,68 @@ func getInterfacesClient(c *config) (*network.InterfacesClient, error) {
 	return &ifClient, err
 }
 
+func getSecurityGroupsClient(c *config) (*network.SecurityGroupsClient, error) {
+	var err error
+	securityGroupsClient := network.NewSecurityGroupsClient(c.SubscriptionID)
+	securityGroupsClient.Authorizer, err = getAuthorizer(c)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create authorizer: %v", err)
+	}
+
+	return &securityGroupsClient, nil
+}
+
 func getDisksClient(c *config) (*compute.DisksClient, error) {
 	var err error
 	disksClient := compute.NewDisksClient(c.SubscriptionID)
-	disksClient.Authorizer, err = auth.NewClientCredentialsConfig(c.ClientID, c.ClientSecret, c.TenantID).Authorizer()
+	disksClient.Authorizer, err = getAuthorizer(c)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create authorizer: %s", err.Error())
 	}
 
 	return &disksClient, err
 }
+
+func getVMImagesClient(c *config) (*compute.VirtualMachineImagesClient, error) {
+	var err error
+	imagesClient := compute.NewVirtualMachineImagesClient(c.SubscriptionID)
+	imagesClient.Authorizer, err = getAuthorizer(c)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create authorizer: %s", err.Error())
+	}
+
+	return &imagesClient, nil
+}
+
+func getImagesClient(c *config) (*compute.ImagesClient, error) {
+	var err error
+	imagesClient := compute.NewImagesClient(c.SubscriptionID)
+	imagesClient.Authorizer, err = getAuthorizer(c)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create authorizer: %s", err.Error())
+	}
+
+	return &imagesClient, nil
+}
+
+func getGalleryImageVersionsClient(c *config, subscriptionID string) (*compute.GalleryImageVersionsClient, error) {
+	var err error
+	versionsClient := compute.NewGalleryImageVersionsClient(subscriptionID)
+	versionsClient.Authorizer, err = getAuthorizer(c)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create authorizer: %s", err.Error())
+	}
+
+	return &versionsClient, nil
+}
+
+func getMarketplaceAgreementsClient(c *config) (*marketplaceordering.MarketplaceAgreementsClient, error) {
+	var err error
+	agreementsClient := marketplaceordering.NewMarketplaceAgreementsClient(c.SubscriptionID)
+	agreementsClient.Authorizer, err = getAuthorizer(c)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create authorizer: %s", err.Error())
+	}
+
+	return &agreementsClient, nil
+}
@@ -0,0 +1,229 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2019-07-01/compute"
+	"github.com/Azure/azure-sdk-for-go/services/marketplaceordering/mgmt/2015-06-01/marketplaceordering"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/golang/glog"
+
+	"github.com/kubermatic/machine-controller/pkg/providerconfig"
+)
+
+const defaultImageVersion = "latest"
+
+var (
+	// managedImageIDRegex matches the ids of managed images
+	managedImageIDRegex = regexp.MustCompile(`(?i)^/subscriptions/[^/]+/resourceGroups/([^/]+)/providers/Microsoft\.Compute/images/([^/]+)$`)
+	// galleryImageVersionIDRegex matches the ids of image versions in a shared image gallery
+	galleryImageVersionIDRegex = regexp.MustCompile(`(?i)^/subscriptions/([^/]+)/resourceGroups/([^/]+)/providers/Microsoft\.Compute/galleries/([^/]+)/images/([^/]+)/versions/([^/]+)$`)
+)
+
+// ImageReferenceRawConfig references a marketplace image
+type ImageReferenceRawConfig struct {
+	Publisher providerconfig.ConfigVarString `json:"publisher"`
+	Offer     providerconfig.ConfigVarString `json:"offer"`
+	SKU       providerconfig.ConfigVarString `json:"sku"`
+	// Version defaults to latest
+	Version providerconfig.ConfigVarString `json:"version,omitempty"`
+}
+
+// ImagePlanRawConfig is the purchase plan of a marketplace image, or of an image which is based on one
+type ImagePlanRawConfig struct {
+	Name      providerconfig.ConfigVarString `json:"name"`
+	Publisher providerconfig.ConfigVarString `json:"publisher"`
+	Product   providerconfig.ConfigVarString `json:"product"`
+	// AcceptTerms accepts the marketplace terms of the plan for the subscription before the VM gets created
+	AcceptTerms bool `json:"acceptTerms,omitempty"`
+}
+
+type imageReferenceConfig struct {
+	Publisher string
+	Offer     string
+	SKU       string
+	Version   string
+}
+
+type imagePlanConfig struct {
+	Name        string
+	Publisher   string
+	Product     string
+	AcceptTerms bool
+}
+
+func (p *provider) getImageReferenceConfig(raw *ImageReferenceRawConfig) (*imageReferenceConfig, error) {
+	if raw == nil {
+		return nil, nil
+	}
+
+	var err error
+	c := imageReferenceConfig{}
+	c.Publisher, err = p.configVarResolver.GetConfigVarStringValue(raw.Publisher)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the value of \"imageReference.publisher\" field, error = %v", err)
+	}
+	c.Offer, err = p.configVarResolver.GetConfigVarStringValue(raw.Offer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the value of \"imageReference.offer\" field, error = %v", err)
+	}
+	c.SKU, err = p.configVarResolver.GetConfigVarStringValue(raw.SKU)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the value of \"imageReference.sku\" field, error = %v", err)
+	}
+	c.Version, err = p.configVarResolver.GetConfigVarStringValue(raw.Version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the value of \"imageReference.version\" field, error = %v", err)
+	}
+	if c.Version == "" {
+		c.Version = defaultImageVersion
+	}
+	return &c, nil
+}
+
+func (p *provider) getImagePlanConfig(raw *ImagePlanRawConfig) (*imagePlanConfig, error) {
+	if raw == nil {
+		return nil, nil
+	}
+
+	var err error
+	c := imagePlanConfig{AcceptTerms: raw.AcceptTerms}
+	c.Name, err = p.configVarResolver.GetConfigVarStringValue(raw.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the value of \"imagePlan.name\" field, error = %v", err)
+	}
+	c.Publisher, err = p.configVarResolver.GetConfigVarStringValue(raw.Publisher)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the value of \"imagePlan.publisher\" field, error = %v", err)
+	}
+	c.Product, err = p.configVarResolver.GetConfigVarStringValue(raw.Product)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the value of \"imagePlan.product\" field, error = %v", err)
+	}
+	return &c, nil
+}
+
+// getImageReference returns the configured image or the default image of the operating system
+func getImageReference(c *config, os providerconfig.OperatingSystem) (*compute.ImageReference, error) {
+	if c.ImageID != "" {
+		return &compute.ImageReference{ID: to.StringPtr(c.ImageID)}, nil
+	}
+	if ref := c.ImageReference; ref != nil {
+		return &compute.ImageReference{
+			Publisher: to.StringPtr(ref.Publisher),
+			Offer:     to.StringPtr(ref.Offer),
+			Sku:       to.StringPtr(ref.SKU),
+			Version:   to.StringPtr(ref.Version),
+		}, nil
+	}
+	return getOSImageReference(os)
+}
+
+func getImagePlan(c *config) *compute.Plan {
+	if c.ImagePlan == nil {
+		return nil
+	}
+	return &compute.Plan{
+		Name:      to.StringPtr(c.ImagePlan.Name),
+		Publisher: to.StringPtr(c.ImagePlan.Publisher),
+		Product:   to.StringPtr(c.ImagePlan.Product),
+	}
+}
+
+func validateImage(c *config, os providerconfig.OperatingSystem) error {
+	if c.ImageID != "" && c.ImageReference != nil {
+		return fmt.Errorf("only one of imageID and imageReference can be specified")
+	}
+	if c.ImageID != "" && !managedImageIDRegex.MatchString(c.ImageID) && !galleryImageVersionIDRegex.MatchString(c.ImageID) {
+		return fmt.Errorf("imageID %q is neither the id of a managed image nor of a shared image gallery version", c.ImageID)
+	}
+	if ref := c.ImageReference; ref != nil && (ref.Publisher == "" || ref.Offer == "" || ref.SKU == "") {
+		return fmt.Errorf("the image reference needs a publisher, offer and sku")
+	}
+	if plan := c.ImagePlan; plan != nil && (plan.Name == "" || plan.Publisher == "" || plan.Product == "") {
+		return fmt.Errorf("the image plan needs a name, publisher and product")
+	}
+	if c.ImageID == "" && c.ImageReference == nil {
+		if _, err := getOSImageReference(os); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkImageExists verifies that the marketplace image, managed image or shared image gallery version exists
+func checkImageExists(ctx context.Context, c *config) error {
+	if ref := c.ImageReference; ref != nil {
+		imagesClient, err := getVMImagesClient(c)
+		if err != nil {
+			return fmt.Errorf("failed to create vm images client: %v", err)
+		}
+		if ref.Version != defaultImageVersion {
+			_, err = imagesClient.Get(ctx, c.Location, ref.Publisher, ref.Offer, ref.SKU, ref.Version)
+			return err
+		}
+		images, err := imagesClient.List(ctx, c.Location, ref.Publisher, ref.Offer, ref.SKU, "", to.Int32Ptr(1), "")
+		if err != nil {
+			return err
+		}
+		if images.Value == nil || len(*images.Value) == 0 {
+			return fmt.Errorf("no versions found for image %s:%s:%s", ref.Publisher, ref.Offer, ref.SKU)
+		}
+		return nil
+	}
+
+	if matches := managedImageIDRegex.FindStringSubmatch(c.ImageID); matches != nil {
+		imagesClient, err := getImagesClient(c)
+		if err != nil {
+			return fmt.Errorf("failed to create images client: %v", err)
+		}
+		_, err = imagesClient.Get(ctx, matches[1], matches[2], "")
+		return err
+	}
+
+	if matches := galleryImageVersionIDRegex.FindStringSubmatch(c.ImageID); matches != nil {
+		// Galleries get shared across subscriptions, so the client uses the subscription of the gallery
+		versionsClient, err := getGalleryImageVersionsClient(c, matches[1])
+		if err != nil {
+			return fmt.Errorf("failed to create gallery image versions client: %v", err)
+		}
+		_, err = versionsClient.Get(ctx, matches[2], matches[3], matches[4], matches[5], "")
+		return err
+	}
+	return nil
+}
+
+// acceptImagePlanTerms accepts the marketplace terms of the image plan, unless they already got accepted
+func acceptImagePlanTerms(ctx context.Context, c *config) error {
+	plan := c.ImagePlan
+	if plan == nil || !plan.AcceptTerms {
+		return nil
+	}
+
+	agreementsClient, err := getMarketplaceAgreementsClient(c)
+	if err != nil {
+		return fmt.Errorf("failed to create marketplace agreements client: %v", err)
+	}
+
+	terms, err := agreementsClient.Get(ctx, plan.Publisher, plan.Product, plan.Name)
+	if err != nil {
+		return fmt.Errorf("failed to get the terms of plan %s: %v", plan.Name, err)
+	}
+	if terms.AgreementProperties == nil {
+		return fmt.Errorf("the terms of plan %s have no properties", plan.Name)
+	}
+	if to.Bool(terms.Accepted) {
+		return nil
+	}
+
+	glog.Infof("Accepting the marketplace terms of plan %s/%s/%s", plan.Publisher, plan.Product, plan.Name)
+	terms.Accepted = to.BoolPtr(true)
+	if _, err := agreementsClient.Create(ctx, plan.Publisher, plan.Product, plan.Name, marketplaceordering.AgreementTerms{
+		AgreementProperties: terms.AgreementProperties,
+	}); err != nil {
+		return fmt.Errorf("failed to accept the terms of plan %s: %v", plan.Name, err)
+	}
+	return nil
+}
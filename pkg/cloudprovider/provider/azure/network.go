@@ -0,0 +1,249 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2019-07-01/compute"
+	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2018-04-01/network"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/golang/glog"
+
+	kuberneteshelper "github.com/kubermatic/machine-controller/pkg/kubernetes"
+	"github.com/kubermatic/machine-controller/pkg/providerconfig"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+)
+
+// machineDeploymentTag marks the network security groups managed per MachineDeployment
+const machineDeploymentTag = "Machine-Deployment"
+
+// InterfaceRawConfig is an additional network interface of the VM
+type InterfaceRawConfig struct {
+	// SubnetName is a subnet in the virtual network of the VM
+	SubnetName providerconfig.ConfigVarString `json:"subnetName"`
+	// PrivateIPAddress is allocated statically from the subnet, a dynamic address gets allocated without it
+	PrivateIPAddress providerconfig.ConfigVarString `json:"privateIPAddress,omitempty"`
+}
+
+type interfaceConfig struct {
+	SubnetName       string
+	PrivateIPAddress string
+}
+
+func (p *provider) getInterfaceConfig(raw InterfaceRawConfig) (*interfaceConfig, error) {
+	var err error
+	c := interfaceConfig{}
+	c.SubnetName, err = p.configVarResolver.GetConfigVarStringValue(raw.SubnetName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the value of \"additionalInterfaces.subnetName\" field, error = %v", err)
+	}
+	c.PrivateIPAddress, err = p.configVarResolver.GetConfigVarStringValue(raw.PrivateIPAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the value of \"additionalInterfaces.privateIPAddress\" field, error = %v", err)
+	}
+	return &c, nil
+}
+
+// getInterfaces returns the primary network interface followed by the additional ones
+func getInterfaces(c *config) []interfaceConfig {
+	primary := interfaceConfig{SubnetName: c.SubnetName, PrivateIPAddress: c.PrivateIPAddress}
+	return append([]interfaceConfig{primary}, c.AdditionalInterfaces...)
+}
+
+func getInterfaceName(machineName string, index int) string {
+	if index == 0 {
+		return machineName + "-netiface"
+	}
+	return fmt.Sprintf("%s-netiface-%d", machineName, index)
+}
+
+// getSecurityGroupName returns the name of the network security group managed for the MachineDeployment of the machine.
+// Machines without a MachineDeployment get their own group.
+func getSecurityGroupName(c *config, machine *v1alpha1.Machine) string {
+	return kuberneteshelper.MachineDeploymentResourceName(kuberneteshelper.ClusterID(c.Tags), machine, "nsg")
+}
+
+// getSecurityGroupTag returns the value of the machineDeploymentTag of the network security group managed for
+// the MachineDeployment of the machine
+func getSecurityGroupTag(machine *v1alpha1.Machine) string {
+	return fmt.Sprintf("%s/%s", machine.Namespace, kuberneteshelper.MachineDeploymentOwner(machine))
+}
+
+func validateNetwork(c *config) error {
+	if c.SecurityGroupName != "" && c.ManageSecurityGroup {
+		return fmt.Errorf("securityGroupName and manageSecurityGroup can't be combined")
+	}
+
+	privateIPs := sets.NewString()
+	for i, iface := range getInterfaces(c) {
+		if iface.SubnetName == "" {
+			return fmt.Errorf("subnetName of additional interface %d is missing", i)
+		}
+		if iface.PrivateIPAddress == "" {
+			continue
+		}
+		if ip := net.ParseIP(iface.PrivateIPAddress); ip == nil || ip.To4() == nil {
+			return fmt.Errorf("private IP address %q is not a valid IPv4 address", iface.PrivateIPAddress)
+		}
+		if privateIPs.Has(iface.PrivateIPAddress) {
+			return fmt.Errorf("private IP address %s is used by multiple interfaces", iface.PrivateIPAddress)
+		}
+		privateIPs.Insert(iface.PrivateIPAddress)
+	}
+	return nil
+}
+
+// checkNetwork verifies that the subnets and the network security group exist, and that the static
+// private IP addresses belong to their subnets
+func checkNetwork(ctx context.Context, c *config) error {
+	for _, iface := range getInterfaces(c) {
+		subnet, err := getSubnetByName(ctx, c, iface.SubnetName)
+		if err != nil {
+			return fmt.Errorf("failed to get subnet %s: %v", iface.SubnetName, err)
+		}
+		if iface.PrivateIPAddress == "" {
+			continue
+		}
+		if subnet.SubnetPropertiesFormat == nil || subnet.AddressPrefix == nil {
+			return fmt.Errorf("subnet %s has no address prefix", iface.SubnetName)
+		}
+		_, prefix, err := net.ParseCIDR(*subnet.AddressPrefix)
+		if err != nil {
+			return fmt.Errorf("failed to parse the address prefix of subnet %s: %v", iface.SubnetName, err)
+		}
+		if !prefix.Contains(net.ParseIP(iface.PrivateIPAddress)) {
+			return fmt.Errorf("private IP address %s is not in subnet %s (%s)", iface.PrivateIPAddress, iface.SubnetName, prefix)
+		}
+	}
+
+	if c.SecurityGroupName != "" {
+		securityGroupsClient, err := getSecurityGroupsClient(c)
+		if err != nil {
+			return fmt.Errorf("failed to create security groups client: %v", err)
+		}
+		if _, err := securityGroupsClient.Get(ctx, c.ResourceGroup, c.SecurityGroupName, ""); err != nil {
+			return fmt.Errorf("failed to get security group %s: %v", c.SecurityGroupName, err)
+		}
+	}
+	return nil
+}
+
+// getSecurityGroup returns the network security group the network interfaces get associated with.
+// The group managed for the MachineDeployment gets created if it doesn't exist yet.
+func getSecurityGroup(ctx context.Context, c *config, machine *v1alpha1.Machine) (*network.SecurityGroup, error) {
+	if c.SecurityGroupName == "" && !c.ManageSecurityGroup {
+		return nil, nil
+	}
+
+	securityGroupsClient, err := getSecurityGroupsClient(c)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create security groups client: %v", err)
+	}
+
+	if c.SecurityGroupName != "" {
+		securityGroup, err := securityGroupsClient.Get(ctx, c.ResourceGroup, c.SecurityGroupName, "")
+		if err != nil {
+			return nil, fmt.Errorf("failed to get security group %s: %v", c.SecurityGroupName, err)
+		}
+		return &securityGroup, nil
+	}
+
+	name := getSecurityGroupName(c, machine)
+	securityGroup, err := securityGroupsClient.Get(ctx, c.ResourceGroup, name, "")
+	if err == nil {
+		return &securityGroup, nil
+	}
+	if !isNotFound(securityGroup.Response) {
+		return nil, fmt.Errorf("failed to get security group %s: %v", name, err)
+	}
+
+	// the group only gets created once, updating it would remove the rules which got added later on
+	tags := make(map[string]*string, len(c.Tags)+1)
+	for k, v := range c.Tags {
+		tags[k] = to.StringPtr(v)
+	}
+	tags[machineDeploymentTag] = to.StringPtr(getSecurityGroupTag(machine))
+
+	glog.Infof("Creating security group %q", name)
+	future, err := securityGroupsClient.CreateOrUpdate(ctx, c.ResourceGroup, name, network.SecurityGroup{
+		Location:                      to.StringPtr(c.Location),
+		SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{},
+		Tags:                          tags,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create security group %s: %v", name, err)
+	}
+	if err = future.WaitForCompletionRef(ctx, securityGroupsClient.Client); err != nil {
+		return nil, fmt.Errorf("failed to wait for creation of security group %s: %v", name, err)
+	}
+	securityGroup, err = future.Result(*securityGroupsClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get creation result of security group %s: %v", name, err)
+	}
+	return &securityGroup, nil
+}
+
+// deleteSecurityGroupIfUnused removes the network security group managed for the MachineDeployment
+// of the machine, once no network interfaces are associated with it anymore
+func deleteSecurityGroupIfUnused(ctx context.Context, c *config, machine *v1alpha1.Machine) error {
+	if !c.ManageSecurityGroup {
+		return nil
+	}
+
+	securityGroupsClient, err := getSecurityGroupsClient(c)
+	if err != nil {
+		return fmt.Errorf("failed to create security groups client: %v", err)
+	}
+
+	name := getSecurityGroupName(c, machine)
+	securityGroup, err := securityGroupsClient.Get(ctx, c.ResourceGroup, name, "")
+	if err != nil {
+		if isNotFound(securityGroup.Response) {
+			return nil
+		}
+		return fmt.Errorf("failed to get security group %s: %v", name, err)
+	}
+	if tag := securityGroup.Tags[machineDeploymentTag]; tag == nil || *tag != getSecurityGroupTag(machine) {
+		glog.Warningf("Not deleting security group %q, it isn't managed by the machine-controller for %s", name, getSecurityGroupTag(machine))
+		return nil
+	}
+	if props := securityGroup.SecurityGroupPropertiesFormat; props != nil {
+		if (props.NetworkInterfaces != nil && len(*props.NetworkInterfaces) > 0) || (props.Subnets != nil && len(*props.Subnets) > 0) {
+			glog.Infof("Not deleting security group %q, it is still in use", name)
+			return nil
+		}
+	}
+
+	glog.Infof("Deleting security group %q", name)
+	future, err := securityGroupsClient.Delete(ctx, c.ResourceGroup, name)
+	if err != nil {
+		return fmt.Errorf("failed to delete security group %s: %v", name, err)
+	}
+	if err = future.WaitForCompletionRef(ctx, securityGroupsClient.Client); err != nil {
+		return fmt.Errorf("failed to wait for deletion of security group %s: %v", name, err)
+	}
+	return nil
+}
+
+// getNetworkInterfaceReferences returns the references of the network interfaces for the VM,
+// the first one is the primary interface
+func getNetworkInterfaceReferences(ifaces []network.Interface) *[]compute.NetworkInterfaceReference {
+	refs := make([]compute.NetworkInterfaceReference, 0, len(ifaces))
+	for i, iface := range ifaces {
+		refs = append(refs, compute.NetworkInterfaceReference{
+			ID:                                  iface.ID,
+			NetworkInterfaceReferenceProperties: &compute.NetworkInterfaceReferenceProperties{Primary: to.BoolPtr(i == 0)},
+		})
+	}
+	return &refs
+}
+
+func isNotFound(resp autorest.Response) bool {
+	return resp.Response != nil && resp.StatusCode == http.StatusNotFound
+}
@@ -8,7 +8,7 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2018-04-01/compute"
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2019-07-01/compute"
 	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2018-04-01/network"
 	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/golang/glog"
@@ -30,10 +30,11 @@ const (
 	machineUIDTag = "Machine-UID"
 	adminUserName = "kubermatic"
 
-	finalizerPublicIP = "kubermatic.io/cleanup-azure-public-ip"
-	finalizerNIC      = "kubermatic.io/cleanup-azure-nic"
-	finalizerDisks    = "kubermatic.io/cleanup-azure-disks"
-	finalizerVM       = "kubermatic.io/cleanup-azure-vm"
+	finalizerPublicIP      = "kubermatic.io/cleanup-azure-public-ip"
+	finalizerNIC           = "kubermatic.io/cleanup-azure-nic"
+	finalizerSecurityGroup = "kubermatic.io/cleanup-azure-security-group"
+	finalizerDisks         = "kubermatic.io/cleanup-azure-disks"
+	finalizerVM            = "kubermatic.io/cleanup-azure-vm"
 )
 
 type provider struct {
@@ -47,6 +48,17 @@ type RawConfig struct {
 	ClientID       providerconfig.ConfigVarString `json:"clientID"`
 	ClientSecret   providerconfig.ConfigVarString `json:"clientSecret"`
 
+	// ClientCertificate is the base64 encoded PKCS#12 bundle of the service principal, used instead of the ClientSecret
+	ClientCertificate         providerconfig.ConfigVarString `json:"clientCertificate,omitempty"`
+	ClientCertificatePassword providerconfig.ConfigVarString `json:"clientCertificatePassword,omitempty"`
+	// UseManagedIdentity authenticates with the managed identity of the VM instead of a service principal
+	UseManagedIdentity providerconfig.ConfigVarBool `json:"useManagedIdentity,omitempty"`
+	// UserAssignedIdentityID is the client id of a user-assigned identity, the system-assigned identity gets used without it
+	UserAssignedIdentityID providerconfig.ConfigVarString `json:"userAssignedIdentityID,omitempty"`
+	// VMIdentityID is the resource id of the user-assigned identity which gets assigned to the VMs,
+	// they get a system-assigned identity without it
+	VMIdentityID providerconfig.ConfigVarString `json:"vmIdentityID,omitempty"`
+
 	Location        providerconfig.ConfigVarString `json:"location"`
 	ResourceGroup   providerconfig.ConfigVarString `json:"resourceGroup"`
 	VMSize          providerconfig.ConfigVarString `json:"vmSize"`
@@ -54,9 +66,37 @@ type RawConfig struct {
 	SubnetName      providerconfig.ConfigVarString `json:"subnetName"`
 	RouteTableName  providerconfig.ConfigVarString `json:"routeTableName"`
 	AvailabilitySet providerconfig.ConfigVarString `json:"availabilitySet"`
+	// Zone is the availability zone of the VM and its public IP, can't be combined with an AvailabilitySet
+	Zone providerconfig.ConfigVarString `json:"zone,omitempty"`
+	// SpotConfig creates a spot VM, which can get evicted by Azure
+	SpotConfig *SpotRawConfig `json:"spotConfig,omitempty"`
 
 	AssignPublicIP providerconfig.ConfigVarBool `json:"assignPublicIP"`
 	Tags           map[string]string            `json:"tags"`
+
+	// SecurityGroupName is an existing network security group, which gets associated with the network interfaces
+	SecurityGroupName providerconfig.ConfigVarString `json:"securityGroupName,omitempty"`
+	// ManageSecurityGroup associates the network interfaces with a network security group per MachineDeployment,
+	// which gets created with the first machine and deleted with the last one
+	ManageSecurityGroup         providerconfig.ConfigVarBool `json:"manageSecurityGroup,omitempty"`
+	EnableAcceleratedNetworking providerconfig.ConfigVarBool `json:"enableAcceleratedNetworking,omitempty"`
+	// PrivateIPAddress of the primary network interface is allocated statically from the subnet,
+	// a dynamic address gets allocated without it
+	PrivateIPAddress     providerconfig.ConfigVarString `json:"privateIPAddress,omitempty"`
+	AdditionalInterfaces []InterfaceRawConfig           `json:"additionalInterfaces,omitempty"`
+
+	// OSDiskSize in GB, defaults to the size of the image
+	OSDiskSize int32 `json:"osDiskSize,omitempty"`
+	// OSDiskSKU is one of Standard_LRS, StandardSSD_LRS or Premium_LRS
+	OSDiskSKU providerconfig.ConfigVarString `json:"osDiskSKU,omitempty"`
+	DataDisks []DataDiskRawConfig            `json:"dataDisks,omitempty"`
+
+	// ImageID is the id of a managed image or of an image version in a shared image gallery
+	ImageID providerconfig.ConfigVarString `json:"imageID,omitempty"`
+	// ImageReference references a marketplace image. The default image of the
+	// operating system gets used when neither it nor the ImageID is set.
+	ImageReference *ImageReferenceRawConfig `json:"imageReference,omitempty"`
+	ImagePlan      *ImagePlanRawConfig      `json:"imagePlan,omitempty"`
 }
 
 type config struct {
@@ -65,6 +105,12 @@ type config struct {
 	ClientID       string
 	ClientSecret   string
 
+	ClientCertificate         string
+	ClientCertificatePassword string
+	UseManagedIdentity        bool
+	UserAssignedIdentityID    string
+	VMIdentityID              string
+
 	Location        string
 	ResourceGroup   string
 	VMSize          string
@@ -72,9 +118,25 @@ type config struct {
 	SubnetName      string
 	RouteTableName  string
 	AvailabilitySet string
+	Zone            string
+	SpotConfig      *spotConfig
 
 	AssignPublicIP bool
 	Tags           map[string]string
+
+	SecurityGroupName           string
+	ManageSecurityGroup         bool
+	EnableAcceleratedNetworking bool
+	PrivateIPAddress            string
+	AdditionalInterfaces        []interfaceConfig
+
+	OSDiskSize int32
+	OSDiskSKU  string
+	DataDisks  []dataDiskConfig
+
+	ImageID        string
+	ImageReference *imageReferenceConfig
+	ImagePlan      *imagePlanConfig
 }
 
 type azureVM struct {
@@ -173,6 +235,31 @@ func (p *provider) getConfig(s v1alpha1.ProviderConfig) (*config, *providerconfi
 		return nil, nil, fmt.Errorf("failed to get the value of \"clientSecret\" field, error = %v", err)
 	}
 
+	c.ClientCertificate, err = p.configVarResolver.GetConfigVarStringValue(rawCfg.ClientCertificate)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get the value of \"clientCertificate\" field, error = %v", err)
+	}
+
+	c.ClientCertificatePassword, err = p.configVarResolver.GetConfigVarStringValue(rawCfg.ClientCertificatePassword)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get the value of \"clientCertificatePassword\" field, error = %v", err)
+	}
+
+	c.UseManagedIdentity, err = p.configVarResolver.GetConfigVarBoolValue(rawCfg.UseManagedIdentity)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get the value of \"useManagedIdentity\" field, error = %v", err)
+	}
+
+	c.UserAssignedIdentityID, err = p.configVarResolver.GetConfigVarStringValue(rawCfg.UserAssignedIdentityID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get the value of \"userAssignedIdentityID\" field, error = %v", err)
+	}
+
+	c.VMIdentityID, err = p.configVarResolver.GetConfigVarStringValue(rawCfg.VMIdentityID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get the value of \"vmIdentityID\" field, error = %v", err)
+	}
+
 	c.ResourceGroup, err = p.configVarResolver.GetConfigVarStringValue(rawCfg.ResourceGroup)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to get the value of \"resourceGroup\" field, error = %v", err)
@@ -213,8 +300,73 @@ func (p *provider) getConfig(s v1alpha1.ProviderConfig) (*config, *providerconfi
 		return nil, nil, fmt.Errorf("failed to get the value of \"availabilitySet\" field, error = %v", err)
 	}
 
+	c.Zone, err = p.configVarResolver.GetConfigVarStringValue(rawCfg.Zone)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get the value of \"zone\" field, error = %v", err)
+	}
+
+	c.SpotConfig, err = p.getSpotConfig(rawCfg.SpotConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	c.Tags = rawCfg.Tags
 
+	c.SecurityGroupName, err = p.configVarResolver.GetConfigVarStringValue(rawCfg.SecurityGroupName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get the value of \"securityGroupName\" field, error = %v", err)
+	}
+
+	c.ManageSecurityGroup, err = p.configVarResolver.GetConfigVarBoolValue(rawCfg.ManageSecurityGroup)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get the value of \"manageSecurityGroup\" field, error = %v", err)
+	}
+
+	c.EnableAcceleratedNetworking, err = p.configVarResolver.GetConfigVarBoolValue(rawCfg.EnableAcceleratedNetworking)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get the value of \"enableAcceleratedNetworking\" field, error = %v", err)
+	}
+
+	c.PrivateIPAddress, err = p.configVarResolver.GetConfigVarStringValue(rawCfg.PrivateIPAddress)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get the value of \"privateIPAddress\" field, error = %v", err)
+	}
+
+	for _, rawIface := range rawCfg.AdditionalInterfaces {
+		iface, err := p.getInterfaceConfig(rawIface)
+		if err != nil {
+			return nil, nil, err
+		}
+		c.AdditionalInterfaces = append(c.AdditionalInterfaces, *iface)
+	}
+
+	c.OSDiskSize = rawCfg.OSDiskSize
+	c.OSDiskSKU, err = p.configVarResolver.GetConfigVarStringValue(rawCfg.OSDiskSKU)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get the value of \"osDiskSKU\" field, error = %v", err)
+	}
+
+	for i, rawDisk := range rawCfg.DataDisks {
+		disk, err := p.getDataDiskConfig(rawDisk, i)
+		if err != nil {
+			return nil, nil, err
+		}
+		c.DataDisks = append(c.DataDisks, *disk)
+	}
+
+	c.ImageID, err = p.configVarResolver.GetConfigVarStringValue(rawCfg.ImageID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get the value of \"imageID\" field, error = %v", err)
+	}
+	c.ImageReference, err = p.getImageReferenceConfig(rawCfg.ImageReference)
+	if err != nil {
+		return nil, nil, err
+	}
+	c.ImagePlan, err = p.getImagePlanConfig(rawCfg.ImagePlan)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	return &c, &pconfig, nil
 }
 
@@ -333,18 +485,22 @@ func (p *provider) Create(machine *v1alpha1.Machine, update cloud.MachineUpdater
 		return nil, fmt.Errorf("failed to create VM client: %v", err)
 	}
 
-	osRef, err := getOSImageReference(providerCfg.OperatingSystem)
+	osRef, err := getImageReference(config, providerCfg.OperatingSystem)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := acceptImagePlanTerms(context.TODO(), config); err != nil {
+		return nil, err
+	}
+
 	// We genete a random SSH key, since Azure won't let us create a VM without an SSH key or a password
 	key, err := ssh.NewKey()
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate ssh key: %v", err)
 	}
 
-	ifaceName := machine.Spec.Name + "-netiface"
+	ifaceName := getInterfaceName(machine.Spec.Name, 0)
 	publicIPName := ifaceName + "-pubip"
 	var publicIP *network.PublicIPAddress
 	if config.AssignPublicIP {
@@ -361,6 +517,18 @@ func (p *provider) Create(machine *v1alpha1.Machine, update cloud.MachineUpdater
 		}
 	}
 
+	if config.ManageSecurityGroup && !kuberneteshelper.HasFinalizer(machine, finalizerSecurityGroup) {
+		if machine, err = update(machine, func(updatedMachine *v1alpha1.Machine) {
+			updatedMachine.Finalizers = append(updatedMachine.Finalizers, finalizerSecurityGroup)
+		}); err != nil {
+			return nil, err
+		}
+	}
+	securityGroup, err := getSecurityGroup(context.TODO(), config, machine)
+	if err != nil {
+		return nil, err
+	}
+
 	if !kuberneteshelper.HasFinalizer(machine, finalizerNIC) {
 		if machine, err = update(machine, func(updatedMachine *v1alpha1.Machine) {
 			updatedMachine.Finalizers = append(updatedMachine.Finalizers, finalizerNIC)
@@ -368,9 +536,18 @@ func (p *provider) Create(machine *v1alpha1.Machine, update cloud.MachineUpdater
 			return nil, err
 		}
 	}
-	iface, err := createOrUpdateNetworkInterface(context.TODO(), ifaceName, machine.UID, config, publicIP)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate main network interface: %v", err)
+	var ifaces []network.Interface
+	for i, ifConfig := range getInterfaces(config) {
+		// only the primary network interface gets the public IP
+		var ifPublicIP *network.PublicIPAddress
+		if i == 0 {
+			ifPublicIP = publicIP
+		}
+		iface, err := createOrUpdateNetworkInterface(context.TODO(), getInterfaceName(machine.Spec.Name, i), machine.UID, config, ifConfig, ifPublicIP, securityGroup)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate network interface %d: %v", i, err)
+		}
+		ifaces = append(ifaces, iface)
 	}
 
 	tags := make(map[string]*string, len(config.Tags)+1)
@@ -379,18 +556,31 @@ func (p *provider) Create(machine *v1alpha1.Machine, update cloud.MachineUpdater
 	}
 	tags[machineUIDTag] = to.StringPtr(string(machine.UID))
 
+	if !kuberneteshelper.HasFinalizer(machine, finalizerDisks) {
+		if machine, err = update(machine, func(updatedMachine *v1alpha1.Machine) {
+			updatedMachine.Finalizers = append(updatedMachine.Finalizers, finalizerDisks)
+		}); err != nil {
+			return nil, err
+		}
+	}
+	dataDisks, err := createOrUpdateDataDisks(context.TODO(), config, machine.Spec.Name, machine.UID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create data disks: %v", err)
+	}
+
+	storageProfile := &compute.StorageProfile{
+		ImageReference: osRef,
+		OsDisk:         getOSDisk(config, machine.Spec.Name),
+	}
+	if len(dataDisks) > 0 {
+		storageProfile.DataDisks = &dataDisks
+	}
+
 	vmSpec := compute.VirtualMachine{
 		Location: &config.Location,
 		VirtualMachineProperties: &compute.VirtualMachineProperties{
 			HardwareProfile: &compute.HardwareProfile{VMSize: compute.VirtualMachineSizeTypes(config.VMSize)},
-			NetworkProfile: &compute.NetworkProfile{
-				NetworkInterfaces: &[]compute.NetworkInterfaceReference{
-					{
-						ID:                                  iface.ID,
-						NetworkInterfaceReferenceProperties: &compute.NetworkInterfaceReferenceProperties{Primary: to.BoolPtr(true)},
-					},
-				},
-			},
+			NetworkProfile:  &compute.NetworkProfile{NetworkInterfaces: getNetworkInterfaceReferences(ifaces)},
 			OsProfile: &compute.OSProfile{
 				AdminUsername: to.StringPtr(adminUserName),
 				ComputerName:  &machine.Spec.Name,
@@ -407,10 +597,14 @@ func (p *provider) Create(machine *v1alpha1.Machine, update cloud.MachineUpdater
 				},
 				CustomData: to.StringPtr(base64.StdEncoding.EncodeToString([]byte(userdata))),
 			},
-			StorageProfile: &compute.StorageProfile{ImageReference: osRef},
+			StorageProfile: storageProfile,
 		},
-		Tags: tags,
+		Plan:     getImagePlan(config),
+		Identity: getVMIdentity(config),
+		Tags:     tags,
+		Zones:    getZones(config),
 	}
+	setSpotProfile(config, vmSpec.VirtualMachineProperties)
 
 	if config.AvailabilitySet != "" {
 		// Azure expects the full path to the resource
@@ -419,13 +613,6 @@ func (p *provider) Create(machine *v1alpha1.Machine, update cloud.MachineUpdater
 	}
 
 	glog.Infof("Creating machine %q", machine.Spec.Name)
-	if !kuberneteshelper.HasFinalizer(machine, finalizerDisks) {
-		if machine, err = update(machine, func(updatedMachine *v1alpha1.Machine) {
-			updatedMachine.Finalizers = append(updatedMachine.Finalizers, finalizerDisks)
-		}); err != nil {
-			return nil, err
-		}
-	}
 	if !kuberneteshelper.HasFinalizer(machine, finalizerVM) {
 		if machine, err = update(machine, func(updatedMachine *v1alpha1.Machine) {
 			updatedMachine.Finalizers = append(updatedMachine.Finalizers, finalizerVM)
@@ -438,7 +625,7 @@ func (p *provider) Create(machine *v1alpha1.Machine, update cloud.MachineUpdater
 		return nil, fmt.Errorf("trying to create a VM: %v", err)
 	}
 
-	err = future.WaitForCompletion(context.TODO(), vmClient.Client)
+	err = future.WaitForCompletionRef(context.TODO(), vmClient.Client)
 	if err != nil {
 		return nil, fmt.Errorf("waiting for operation returned: %v", err.Error())
 	}
@@ -448,6 +635,10 @@ func (p *provider) Create(machine *v1alpha1.Machine, update cloud.MachineUpdater
 		return nil, fmt.Errorf("decoding result: %v", err.Error())
 	}
 
+	if err := tagOSDisk(context.TODO(), config, machine.Spec.Name, machine.UID); err != nil {
+		return nil, err
+	}
+
 	// get the actual VM object filled in with additional data
 	vm, err = vmClient.Get(context.TODO(), config.ResourceGroup, machine.Spec.Name, "")
 	if err != nil {
@@ -459,7 +650,7 @@ func (p *provider) Create(machine *v1alpha1.Machine, update cloud.MachineUpdater
 		return nil, fmt.Errorf("failed to retrieve IP addresses for VM %q: %v", machine.Spec.Name, err.Error())
 	}
 
-	status, err := getVMStatus(context.TODO(), config, machine.Spec.Name)
+	status, err := getVMStatus(context.TODO(), config, &vm)
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve status for VM %q: %v", machine.Spec.Name, err.Error())
 	}
@@ -493,6 +684,9 @@ func (p *provider) Delete(machine *v1alpha1.Machine, update cloud.MachineUpdater
 	if err = deleteDisksByMachineUID(context.TODO(), config, machine.UID); err != nil {
 		return fmt.Errorf("failed to remove disks of machine %q: %v", machine.Name, err)
 	}
+	if err = deleteOSDisk(context.TODO(), config, machine.Spec.Name, machine.UID); err != nil {
+		return fmt.Errorf("failed to remove os disk of machine %q: %v", machine.Name, err)
+	}
 	if machine, err = update(machine, func(updatedMachine *v1alpha1.Machine) {
 		updatedMachine.Finalizers = kuberneteshelper.RemoveFinalizer(updatedMachine.Finalizers, finalizerDisks)
 	}); err != nil {
@@ -509,6 +703,18 @@ func (p *provider) Delete(machine *v1alpha1.Machine, update cloud.MachineUpdater
 		return err
 	}
 
+	if kuberneteshelper.HasFinalizer(machine, finalizerSecurityGroup) {
+		glog.Infof("deleting security group of VM %q", machine.Name)
+		if err = deleteSecurityGroupIfUnused(context.TODO(), config, machine); err != nil {
+			return fmt.Errorf("failed to remove security group of machine %q: %v", machine.Name, err)
+		}
+		if machine, err = update(machine, func(updatedMachine *v1alpha1.Machine) {
+			updatedMachine.Finalizers = kuberneteshelper.RemoveFinalizer(updatedMachine.Finalizers, finalizerSecurityGroup)
+		}); err != nil {
+			return err
+		}
+	}
+
 	glog.Infof("deleting public IP addresses of VM %q", machine.Name)
 	if err = deleteIPAddressesByMachineUID(context.TODO(), config, machine.UID); err != nil {
 		return fmt.Errorf("failed to remove public IP addresses of machine %q: %v", machine.Name, err)
@@ -551,7 +757,8 @@ func getVMByUID(ctx context.Context, c *config, uid types.UID) (*compute.Virtual
 	return nil, cloudprovidererrors.ErrInstanceNotFound
 }
 
-func getVMStatus(ctx context.Context, c *config, vmName string) (instance.Status, error) {
+func getVMStatus(ctx context.Context, c *config, vm *compute.VirtualMachine) (instance.Status, error) {
+	vmName := to.String(vm.Name)
 	vmClient, err := getVMClient(c)
 	if err != nil {
 		return instance.StatusUnknown, err
@@ -600,6 +807,12 @@ func getVMStatus(ctx context.Context, c *config, vmName string) (instance.Status
 		return instance.StatusRunning, nil
 	case "PowerState/starting":
 		return instance.StatusCreating, nil
+	case "PowerState/deallocating", "PowerState/deallocated":
+		// spot VMs with the Deallocate eviction policy get deallocated when Azure evicts them
+		if isSpotVM(vm) {
+			return instance.StatusInterrupted, nil
+		}
+		return instance.StatusUnknown, nil
 	default:
 		glog.Warningf("unknown Azure power status %q", *powerStatus.Code)
 		return instance.StatusUnknown, nil
@@ -626,7 +839,7 @@ func (p *provider) Get(machine *v1alpha1.Machine) (instance.Instance, error) {
 		return nil, fmt.Errorf("failed to retrieve IP addresses for VM %v: %v", vm.Name, err)
 	}
 
-	status, err := getVMStatus(context.TODO(), config, machine.Spec.Name)
+	status, err := getVMStatus(context.TODO(), config, vm)
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve status for VM %v: %v", vm.Name, err)
 	}
@@ -635,6 +848,26 @@ func (p *provider) Get(machine *v1alpha1.Machine) (instance.Instance, error) {
 }
 
 func (p *provider) GetCloudConfig(spec v1alpha1.MachineSpec) (config string, name string, err error) {
+	return p.getCloudConfig(spec, nil)
+}
+
+// machineProvider renders the cloud config for a single machine
+type machineProvider struct {
+	*provider
+	machine *v1alpha1.Machine
+}
+
+// ForMachine returns the provider the userdata of the machine gets rendered with. The cloud config contains
+// the network security group managed for the MachineDeployment of the machine.
+func (p *provider) ForMachine(machine *v1alpha1.Machine) cloud.Provider {
+	return &machineProvider{provider: p, machine: machine}
+}
+
+func (p *machineProvider) GetCloudConfig(spec v1alpha1.MachineSpec) (config string, name string, err error) {
+	return p.getCloudConfig(spec, p.machine)
+}
+
+func (p *provider) getCloudConfig(spec v1alpha1.MachineSpec, machine *v1alpha1.Machine) (config string, name string, err error) {
 	c, _, err := p.getConfig(spec.ProviderConfig)
 	if err != nil {
 		return "", "", fmt.Errorf("failed to parse config: %v", err)
@@ -651,8 +884,20 @@ func (p *provider) GetCloudConfig(spec v1alpha1.MachineSpec) (config string, nam
 		VNetName:            c.VNetName,
 		SubnetName:          c.SubnetName,
 		RouteTableName:      c.RouteTableName,
+		SecurityGroupName:   c.SecurityGroupName,
 		UseInstanceMetadata: true,
 	}
+	if c.ManageSecurityGroup && machine != nil {
+		cc.SecurityGroupName = getSecurityGroupName(c, machine)
+	}
+	if c.UseManagedIdentity {
+		cc.AADClientID = ""
+		cc.UseManagedIdentityExtension = true
+		cc.UserAssignedIdentityID = c.UserAssignedIdentityID
+	} else if c.ClientCertificate != "" {
+		cc.AADClientCertPath = clientCertificatePath
+		cc.AADClientCertPassword = c.ClientCertificatePassword
+	}
 
 	s, err := CloudConfigToString(cc)
 	if err != nil {
@@ -676,12 +921,8 @@ func (p *provider) Validate(spec v1alpha1.MachineSpec) error {
 		return errors.New("tenantID is missing")
 	}
 
-	if c.ClientID == "" {
-		return errors.New("clientID is missing")
-	}
-
-	if c.ClientSecret == "" {
-		return errors.New("clientSecret is missing")
+	if err := validateCredentials(c); err != nil {
+		return err
 	}
 
 	if c.ResourceGroup == "" {
@@ -700,6 +941,22 @@ func (p *provider) Validate(spec v1alpha1.MachineSpec) error {
 		return errors.New("subnetName is missing")
 	}
 
+	if err := validateNetwork(c); err != nil {
+		return err
+	}
+
+	if err := validateDisks(c); err != nil {
+		return err
+	}
+
+	if err := validateImage(c, providerCfg.OperatingSystem); err != nil {
+		return err
+	}
+
+	if err := validateZoneAndSpot(c); err != nil {
+		return err
+	}
+
 	vmClient, err := getVMClient(c)
 	if err != nil {
 		return fmt.Errorf("failed to (create) vm client: %v", err.Error())
@@ -714,11 +971,14 @@ func (p *provider) Validate(spec v1alpha1.MachineSpec) error {
 		return fmt.Errorf("failed to get virtual network: %v", err)
 	}
 
-	if _, err := getSubnet(context.TODO(), c); err != nil {
-		return fmt.Errorf("failed to get subnet: %v", err)
+	if err := checkNetwork(context.TODO(), c); err != nil {
+		return err
+	}
+
+	if err := checkImageExists(context.TODO(), c); err != nil {
+		return fmt.Errorf("failed to get image: %v", err)
 	}
 
-	_, err = getOSImageReference(providerCfg.OperatingSystem)
 	return nil
 }
 
@@ -739,7 +999,7 @@ func (p *provider) MigrateUID(machine *v1alpha1.Machine, new types.UID) error {
 		return fmt.Errorf("failed to create VM client: %v", err)
 	}
 
-	ifaceName := machine.Spec.Name + "-netiface"
+	ifaceName := getInterfaceName(machine.Spec.Name, 0)
 	publicIPName := ifaceName + "-pubip"
 	var publicIP *network.PublicIPAddress
 
@@ -751,9 +1011,19 @@ func (p *provider) MigrateUID(machine *v1alpha1.Machine, new types.UID) error {
 	}
 
 	if kuberneteshelper.HasFinalizer(machine, finalizerNIC) {
-		_, err = createOrUpdateNetworkInterface(ctx, ifaceName, new, config, publicIP)
+		securityGroup, err := getSecurityGroup(ctx, config, machine)
 		if err != nil {
-			return fmt.Errorf("failed to update UID on main network interface: %v", err)
+			return err
+		}
+		for i, ifConfig := range getInterfaces(config) {
+			var ifPublicIP *network.PublicIPAddress
+			if i == 0 {
+				ifPublicIP = publicIP
+			}
+			_, err = createOrUpdateNetworkInterface(ctx, getInterfaceName(machine.Spec.Name, i), new, config, ifConfig, ifPublicIP, securityGroup)
+			if err != nil {
+				return fmt.Errorf("failed to update UID on network interface %d: %v", i, err)
+			}
 		}
 	}
 
@@ -774,7 +1044,7 @@ func (p *provider) MigrateUID(machine *v1alpha1.Machine, new types.UID) error {
 			if err != nil {
 				return fmt.Errorf("failed to update UID for disk %s: %v", *disk.Name, err)
 			}
-			if err := future.WaitForCompletion(ctx, disksClient.Client); err != nil {
+			if err := future.WaitForCompletionRef(ctx, disksClient.Client); err != nil {
 				return fmt.Errorf("failed waiting for completion of update UID operation for disk %s: %v", *disk.Name, err)
 			}
 		}
@@ -792,7 +1062,7 @@ func (p *provider) MigrateUID(machine *v1alpha1.Machine, new types.UID) error {
 		return fmt.Errorf("failed to update UID of the instance: %v", err)
 	}
 
-	if err := future.WaitForCompletion(ctx, vmClient.Client); err != nil {
+	if err := future.WaitForCompletionRef(ctx, vmClient.Client); err != nil {
 		return fmt.Errorf("error waiting for instance to have the updated UID: %v", err)
 	}
 
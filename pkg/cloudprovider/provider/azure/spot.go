@@ -0,0 +1,105 @@
+package azure
+
+import (
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2019-07-01/compute"
+	"github.com/Azure/go-autorest/autorest/to"
+
+	"github.com/kubermatic/machine-controller/pkg/providerconfig"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	// virtualMachinePriorityTypesSpot is supported by the api, but missing in the sdk
+	virtualMachinePriorityTypesSpot compute.VirtualMachinePriorityTypes = "Spot"
+
+	defaultEvictionPolicy = compute.Deallocate
+	// onDemandMaxPrice caps the price of a spot VM at the on-demand price, so it only gets evicted for capacity reasons
+	onDemandMaxPrice = -1
+)
+
+var (
+	evictionPolicies = sets.NewString(string(compute.Deallocate), string(compute.Delete))
+	zones            = sets.NewString("1", "2", "3")
+)
+
+type SpotRawConfig struct {
+	// EvictionPolicy is one of Deallocate or Delete, defaults to Deallocate
+	EvictionPolicy providerconfig.ConfigVarString `json:"evictionPolicy,omitempty"`
+	// MaxPrice is the maximum hourly price in USD, defaults to the on-demand price
+	MaxPrice *float64 `json:"maxPrice,omitempty"`
+}
+
+type spotConfig struct {
+	EvictionPolicy string
+	MaxPrice       float64
+}
+
+func (p *provider) getSpotConfig(raw *SpotRawConfig) (*spotConfig, error) {
+	if raw == nil {
+		return nil, nil
+	}
+
+	var err error
+	c := spotConfig{MaxPrice: onDemandMaxPrice}
+	c.EvictionPolicy, err = p.configVarResolver.GetConfigVarStringValue(raw.EvictionPolicy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the value of \"spotConfig.evictionPolicy\" field, error = %v", err)
+	}
+	if c.EvictionPolicy == "" {
+		c.EvictionPolicy = string(defaultEvictionPolicy)
+	}
+	if raw.MaxPrice != nil {
+		c.MaxPrice = *raw.MaxPrice
+	}
+	return &c, nil
+}
+
+func validateZoneAndSpot(c *config) error {
+	if c.Zone != "" {
+		if !zones.Has(c.Zone) {
+			return fmt.Errorf("invalid zone %s specified. Supported: %s", c.Zone, zones)
+		}
+		if c.AvailabilitySet != "" {
+			return fmt.Errorf("zone and availabilitySet can't be combined")
+		}
+	}
+
+	if c.SpotConfig == nil {
+		return nil
+	}
+	if !evictionPolicies.Has(c.SpotConfig.EvictionPolicy) {
+		return fmt.Errorf("invalid eviction policy %s specified. Supported: %s", c.SpotConfig.EvictionPolicy, evictionPolicies)
+	}
+	if c.SpotConfig.MaxPrice != onDemandMaxPrice && c.SpotConfig.MaxPrice <= 0 {
+		return fmt.Errorf("maxPrice must be greater than 0 or %d for the on-demand price", onDemandMaxPrice)
+	}
+	return nil
+}
+
+func getZones(c *config) *[]string {
+	if c.Zone == "" {
+		return nil
+	}
+	return &[]string{c.Zone}
+}
+
+// setSpotProfile turns the VM into a spot VM if configured
+func setSpotProfile(c *config, vmProperties *compute.VirtualMachineProperties) {
+	if c.SpotConfig == nil {
+		return
+	}
+	vmProperties.Priority = virtualMachinePriorityTypesSpot
+	vmProperties.EvictionPolicy = compute.VirtualMachineEvictionPolicyTypes(c.SpotConfig.EvictionPolicy)
+	vmProperties.BillingProfile = &compute.BillingProfile{MaxPrice: to.Float64Ptr(c.SpotConfig.MaxPrice)}
+}
+
+// isSpotVM returns true for spot and low priority VMs, which can get evicted by Azure
+func isSpotVM(vm *compute.VirtualMachine) bool {
+	if vm.VirtualMachineProperties == nil {
+		return false
+	}
+	return vm.Priority == virtualMachinePriorityTypesSpot || vm.Priority == compute.Low
+}
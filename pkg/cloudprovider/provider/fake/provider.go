@@ -3,37 +3,107 @@ package fake
 import (
 	"encoding/json"
 	"fmt"
+	"hash/fnv"
+	"net"
+	"time"
 
 	"github.com/golang/glog"
 
 	"github.com/kubermatic/machine-controller/pkg/cloudprovider/cloud"
+	cloudprovidererrors "github.com/kubermatic/machine-controller/pkg/cloudprovider/errors"
 	"github.com/kubermatic/machine-controller/pkg/cloudprovider/instance"
 	"github.com/kubermatic/machine-controller/pkg/providerconfig"
 
 	"k8s.io/apimachinery/pkg/types"
 
+	common "sigs.k8s.io/cluster-api/pkg/apis/cluster/common"
 	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
 )
 
+// now gets replaced in tests
+var now = time.Now
+
 type provider struct{}
 
+type Operation string
+
+const (
+	OperationCreate Operation = "create"
+	OperationGet    Operation = "get"
+	OperationDelete Operation = "delete"
+)
+
+type ErrorType string
+
+const (
+	// ErrorTypeTransient errors get retried by the controller
+	ErrorTypeTransient ErrorType = "transient"
+	// ErrorTypeThrottling errors look like the rate limit errors of cloud provider APIs
+	// and get retried by the controller
+	ErrorTypeThrottling ErrorType = "throttling"
+	// ErrorTypeTerminal errors require manual interaction
+	ErrorTypeTerminal ErrorType = "terminal"
+)
+
 type CloudProviderConfig struct {
 	PassValidation bool `json:"passValidation"`
+
+	// CreateLatency is the duration an instance stays in the creating state, e.g. "30s"
+	CreateLatency string `json:"createLatency,omitempty"`
+	// DeleteLatency is the duration an instance stays in the deleting state before it is gone
+	DeleteLatency string `json:"deleteLatency,omitempty"`
+
+	// StateConfigMap stores the instances in a ConfigMap instead of in memory,
+	// so they survive restarts of the machine-controller
+	StateConfigMap *ConfigMapReference `json:"stateConfigMap,omitempty"`
+
+	// Errors get injected into the calls of the provider
+	Errors []InjectedError `json:"errors,omitempty"`
+}
+
+type ConfigMapReference struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+// InjectedError makes calls of the provider fail.
+// Errors of the same operation get returned one after the other, so
+// a throttling error followed by a transient error can be simulated.
+type InjectedError struct {
+	Operation Operation `json:"operation"`
+	Type      ErrorType `json:"type"`
+	// Count is the number of calls per machine which fail. 0 means all calls fail.
+	Count int `json:"count,omitempty"`
+	// Reason of terminal errors, defaults to the CreateError or DeleteError
+	Reason common.MachineStatusError `json:"reason,omitempty"`
+}
+
+type Config struct {
+	PassValidation bool
+	CreateLatency  time.Duration
+	DeleteLatency  time.Duration
+	StateConfigMap *ConfigMapReference
+	Errors         []InjectedError
 }
 
-type CloudProviderInstance struct{}
+type CloudProviderInstance struct {
+	name      string
+	id        string
+	addresses []string
+	status    instance.Status
+}
 
 func (f CloudProviderInstance) Name() string {
-	return ""
+	return f.name
 }
 func (f CloudProviderInstance) ID() string {
-	return ""
+	return f.id
 }
 func (f CloudProviderInstance) Addresses() []string {
-	return nil
+	return f.addresses
 }
 func (f CloudProviderInstance) Status() instance.Status {
-	return instance.StatusUnknown
+	return f.status
 }
 
 // New returns a fake cloud provider
@@ -41,24 +111,149 @@ func New(_ *providerconfig.ConfigVarResolver) cloud.Provider {
 	return &provider{}
 }
 
+func getConfig(s v1alpha1.ProviderConfig) (*Config, error) {
+	if s.Value == nil {
+		return nil, fmt.Errorf("machine.spec.providerconfig.value is nil")
+	}
+	pconfig := providerconfig.Config{}
+	err := json.Unmarshal(s.Value.Raw, &pconfig)
+	if err != nil {
+		return nil, err
+	}
+
+	rawConfig := CloudProviderConfig{}
+	if err = json.Unmarshal(pconfig.CloudProviderSpec.Raw, &rawConfig); err != nil {
+		return nil, err
+	}
+
+	c := Config{
+		PassValidation: rawConfig.PassValidation,
+		StateConfigMap: rawConfig.StateConfigMap,
+		Errors:         rawConfig.Errors,
+	}
+	if rawConfig.CreateLatency != "" {
+		if c.CreateLatency, err = time.ParseDuration(rawConfig.CreateLatency); err != nil {
+			return nil, fmt.Errorf("failed to parse \"createLatency\" field, error = %v", err)
+		}
+	}
+	if rawConfig.DeleteLatency != "" {
+		if c.DeleteLatency, err = time.ParseDuration(rawConfig.DeleteLatency); err != nil {
+			return nil, fmt.Errorf("failed to parse \"deleteLatency\" field, error = %v", err)
+		}
+	}
+	for _, e := range c.Errors {
+		switch e.Operation {
+		case OperationCreate, OperationGet, OperationDelete:
+		default:
+			return nil, fmt.Errorf("invalid operation %q for injected error", e.Operation)
+		}
+		switch e.Type {
+		case ErrorTypeTransient, ErrorTypeThrottling, ErrorTypeTerminal:
+		default:
+			return nil, fmt.Errorf("invalid type %q for injected error", e.Type)
+		}
+	}
+	return &c, nil
+}
+
+// injectError counts the call and returns the injected error which is due for it, if any
+func injectError(c *Config, s store, uid types.UID, op Operation) error {
+	if len(c.Errors) == 0 {
+		return nil
+	}
+
+	state, err := s.get(uid)
+	if err != nil {
+		return err
+	}
+	if state == nil {
+		state = &machineState{}
+	}
+	if state.Calls == nil {
+		state.Calls = map[Operation]int{}
+	}
+	call := state.Calls[op]
+	state.Calls[op]++
+	if err := s.set(uid, state); err != nil {
+		return err
+	}
+
+	var failedCalls int
+	for _, e := range c.Errors {
+		if e.Operation != op {
+			continue
+		}
+		if e.Count != 0 && call >= failedCalls+e.Count {
+			failedCalls += e.Count
+			continue
+		}
+		return newInjectedError(e)
+	}
+	return nil
+}
+
+func newInjectedError(e InjectedError) error {
+	switch e.Type {
+	case ErrorTypeThrottling:
+		return fmt.Errorf("Throttling: rate exceeded for %s", e.Operation)
+	case ErrorTypeTerminal:
+		reason := e.Reason
+		if reason == "" {
+			reason = common.CreateMachineError
+			if e.Operation == OperationDelete {
+				reason = common.DeleteMachineError
+			}
+		}
+		return cloudprovidererrors.TerminalError{
+			Reason:  reason,
+			Message: fmt.Sprintf("injected terminal error for %s", e.Operation),
+		}
+	default:
+		return fmt.Errorf("injected transient error for %s", e.Operation)
+	}
+}
+
+// fakeAddress returns a stable address in 10.0.0.0/8 for the machine
+func fakeAddress(uid types.UID) string {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(uid))
+	sum := h.Sum32()
+	return net.IPv4(10, byte(sum>>16), byte(sum>>8), byte(sum)).String()
+}
+
+// toInstance returns the instance with the status for the current time.
+// It returns nil when the instance is gone.
+func toInstance(c *Config, i *instanceState) *CloudProviderInstance {
+	status := instance.StatusRunning
+	if i.DeletedAt != nil {
+		if now().Sub(*i.DeletedAt) >= c.DeleteLatency {
+			return nil
+		}
+		status = instance.StatusDeleting
+	} else if now().Sub(i.CreatedAt) < c.CreateLatency {
+		status = instance.StatusCreating
+	}
+
+	return &CloudProviderInstance{
+		name:      i.Name,
+		id:        i.ID,
+		addresses: i.Addresses,
+		status:    status,
+	}
+}
+
 func (p *provider) AddDefaults(spec v1alpha1.MachineSpec) (v1alpha1.MachineSpec, bool, error) {
 	return spec, false, nil
 }
 
 // Validate returns success or failure based according to its FakeCloudProviderConfig
 func (p *provider) Validate(machinespec v1alpha1.MachineSpec) error {
-	pconfig := providerconfig.Config{}
-	err := json.Unmarshal(machinespec.ProviderConfig.Value.Raw, &pconfig)
+	c, err := getConfig(machinespec.ProviderConfig)
 	if err != nil {
 		return err
 	}
 
-	fakeCloudProviderConfig := CloudProviderConfig{}
-	if err = json.Unmarshal(pconfig.CloudProviderSpec.Raw, &fakeCloudProviderConfig); err != nil {
-		return err
-	}
-
-	if fakeCloudProviderConfig.PassValidation {
+	if c.PassValidation {
 		glog.V(4).Infof("succeeding validation as requested")
 		return nil
 	}
@@ -68,7 +263,36 @@ func (p *provider) Validate(machinespec v1alpha1.MachineSpec) error {
 }
 
 func (p *provider) Get(machine *v1alpha1.Machine) (instance.Instance, error) {
-	return CloudProviderInstance{}, nil
+	c, err := getConfig(machine.Spec.ProviderConfig)
+	if err != nil {
+		return nil, cloudprovidererrors.TerminalError{
+			Reason:  common.InvalidConfigurationMachineError,
+			Message: fmt.Sprintf("Failed to parse MachineSpec, due to %v", err),
+		}
+	}
+	s := getStore(c)
+
+	if err := injectError(c, s, machine.UID, OperationGet); err != nil {
+		return nil, err
+	}
+
+	state, err := s.get(machine.UID)
+	if err != nil {
+		return nil, err
+	}
+	if state == nil || state.Instance == nil {
+		return nil, cloudprovidererrors.ErrInstanceNotFound
+	}
+
+	i := toInstance(c, state.Instance)
+	if i == nil {
+		glog.V(4).Infof("Deletion of fake instance %s finished", state.Instance.ID)
+		if err := s.delete(machine.UID); err != nil {
+			return nil, err
+		}
+		return nil, cloudprovidererrors.ErrInstanceNotFound
+	}
+	return i, nil
 }
 
 func (p *provider) GetCloudConfig(spec v1alpha1.MachineSpec) (string, string, error) {
@@ -76,16 +300,97 @@ func (p *provider) GetCloudConfig(spec v1alpha1.MachineSpec) (string, string, er
 }
 
 // Create creates a cloud instance according to the given machine
-func (p *provider) Create(_ *v1alpha1.Machine, _ cloud.MachineUpdater, _ string) (instance.Instance, error) {
-	return CloudProviderInstance{}, nil
+func (p *provider) Create(machine *v1alpha1.Machine, _ cloud.MachineUpdater, _ string) (instance.Instance, error) {
+	c, err := getConfig(machine.Spec.ProviderConfig)
+	if err != nil {
+		return nil, cloudprovidererrors.TerminalError{
+			Reason:  common.InvalidConfigurationMachineError,
+			Message: fmt.Sprintf("Failed to parse MachineSpec, due to %v", err),
+		}
+	}
+	s := getStore(c)
+
+	if err := injectError(c, s, machine.UID, OperationCreate); err != nil {
+		return nil, err
+	}
+
+	state, err := s.get(machine.UID)
+	if err != nil {
+		return nil, err
+	}
+	if state == nil {
+		state = &machineState{}
+	}
+	if state.Instance == nil {
+		state.Instance = &instanceState{
+			Name:      machine.Spec.Name,
+			ID:        "fake-" + string(machine.UID),
+			Addresses: []string{fakeAddress(machine.UID)},
+			CreatedAt: now(),
+		}
+		if err := s.set(machine.UID, state); err != nil {
+			return nil, err
+		}
+		glog.V(4).Infof("Created fake instance %s", state.Instance.ID)
+	}
+
+	return toInstance(c, state.Instance), nil
 }
 
-func (p *provider) Delete(_ *v1alpha1.Machine, _ cloud.MachineUpdater) error {
+// Delete marks the instance as deleted, it is gone after the DeleteLatency passed
+func (p *provider) Delete(machine *v1alpha1.Machine, _ cloud.MachineUpdater) error {
+	c, err := getConfig(machine.Spec.ProviderConfig)
+	if err != nil {
+		return cloudprovidererrors.TerminalError{
+			Reason:  common.InvalidConfigurationMachineError,
+			Message: fmt.Sprintf("Failed to parse MachineSpec, due to %v", err),
+		}
+	}
+	s := getStore(c)
+
+	if err := injectError(c, s, machine.UID, OperationDelete); err != nil {
+		return err
+	}
+
+	state, err := s.get(machine.UID)
+	if err != nil {
+		return err
+	}
+	if state == nil || state.Instance == nil {
+		return nil
+	}
+	if state.Instance.DeletedAt == nil {
+		deletedAt := now()
+		state.Instance.DeletedAt = &deletedAt
+		if err := s.set(machine.UID, state); err != nil {
+			return err
+		}
+		glog.V(4).Infof("Deleting fake instance %s", state.Instance.ID)
+	}
 	return nil
 }
 
 func (p *provider) MigrateUID(machine *v1alpha1.Machine, new types.UID) error {
-	return nil
+	c, err := getConfig(machine.Spec.ProviderConfig)
+	if err != nil {
+		return cloudprovidererrors.TerminalError{
+			Reason:  common.InvalidConfigurationMachineError,
+			Message: fmt.Sprintf("Failed to parse MachineSpec, due to %v", err),
+		}
+	}
+	s := getStore(c)
+
+	state, err := s.get(machine.UID)
+	if err != nil {
+		return err
+	}
+	if state == nil {
+		return nil
+	}
+	if err := s.set(new, state); err != nil {
+		return err
+	}
+	return s.delete(machine.UID)
 }
 
 func (p *provider) MachineMetricsLabels(machine *v1alpha1.Machine) (map[string]string, error) {
@@ -0,0 +1,142 @@
+package fake
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kubermatic/machine-controller/pkg/cloudprovider/cloud"
+	cloudprovidererrors "github.com/kubermatic/machine-controller/pkg/cloudprovider/errors"
+	"github.com/kubermatic/machine-controller/pkg/cloudprovider/instance"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+
+	common "sigs.k8s.io/cluster-api/pkg/apis/cluster/common"
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+)
+
+func newMachine(uid types.UID, spec string) *v1alpha1.Machine {
+	return &v1alpha1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "machine-" + string(uid),
+			UID:  uid,
+		},
+		Spec: v1alpha1.MachineSpec{
+			ObjectMeta: metav1.ObjectMeta{Name: "machine-" + string(uid)},
+			ProviderConfig: v1alpha1.ProviderConfig{
+				Value: &runtime.RawExtension{
+					Raw: []byte(fmt.Sprintf(`{"cloudProvider":"fake","cloudProviderSpec":%s}`, spec)),
+				},
+			},
+		},
+	}
+}
+
+func setTime(t time.Time) {
+	now = func() time.Time { return t }
+}
+
+func testLifecycle(t *testing.T, p cloud.Provider, machine *v1alpha1.Machine) {
+	start := time.Date(2018, 11, 1, 0, 0, 0, 0, time.UTC)
+	setTime(start)
+	defer func() { now = time.Now }()
+
+	if _, err := p.Get(machine); err != cloudprovidererrors.ErrInstanceNotFound {
+		t.Fatalf("expected ErrInstanceNotFound before creation, got %v", err)
+	}
+
+	created, err := p.Create(machine, nil, "")
+	if err != nil {
+		t.Fatalf("failed to create instance: %v", err)
+	}
+	if created.Status() != instance.StatusCreating || created.ID() != "fake-"+string(machine.UID) || len(created.Addresses()) != 1 {
+		t.Errorf("got unexpected instance %s with status %s and addresses %v", created.ID(), created.Status(), created.Addresses())
+	}
+
+	setTime(start.Add(time.Minute))
+	i, err := p.Get(machine)
+	if err != nil {
+		t.Fatalf("failed to get instance: %v", err)
+	}
+	if i.Status() != instance.StatusRunning {
+		t.Errorf("expected instance to be running after the create latency, got %s", i.Status())
+	}
+
+	if err := p.Delete(machine, nil); err != nil {
+		t.Fatalf("failed to delete instance: %v", err)
+	}
+	i, err = p.Get(machine)
+	if err != nil {
+		t.Fatalf("failed to get instance: %v", err)
+	}
+	if i.Status() != instance.StatusDeleting {
+		t.Errorf("expected instance to be deleting, got %s", i.Status())
+	}
+
+	setTime(start.Add(2 * time.Minute))
+	if _, err := p.Get(machine); err != cloudprovidererrors.ErrInstanceNotFound {
+		t.Errorf("expected ErrInstanceNotFound after the delete latency, got %v", err)
+	}
+	if err := p.Delete(machine, nil); err != nil {
+		t.Errorf("expected deleting a deleted instance to succeed, got %v", err)
+	}
+}
+
+func TestLifecycle(t *testing.T) {
+	spec := `{"createLatency":"30s","deleteLatency":"30s"}`
+
+	t.Run("memory", func(t *testing.T) {
+		testLifecycle(t, New(nil), newMachine("memory", spec))
+	})
+
+	t.Run("configmap", func(t *testing.T) {
+		kubeClient := kubefake.NewSimpleClientset()
+		SetClient(kubeClient)
+		defer SetClient(nil)
+
+		machine := newMachine("configmap", `{"createLatency":"30s","deleteLatency":"30s","stateConfigMap":{"namespace":"kube-system","name":"fake-provider"}}`)
+		testLifecycle(t, New(nil), machine)
+
+		cm, err := kubeClient.CoreV1().ConfigMaps("kube-system").Get("fake-provider", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("failed to get state ConfigMap: %v", err)
+		}
+		if len(cm.Data) != 0 {
+			t.Errorf("expected state to be removed after deletion, got %v", cm.Data)
+		}
+	})
+}
+
+func TestInjectedErrors(t *testing.T) {
+	p := New(nil)
+	machine := newMachine("errors", `{"errors":[
+		{"operation":"create","type":"throttling","count":2},
+		{"operation":"create","type":"transient","count":1},
+		{"operation":"delete","type":"terminal"}
+	]}`)
+
+	for n, expected := range []string{"Throttling", "Throttling", "transient"} {
+		_, err := p.Create(machine, nil, "")
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected call %d to fail with a %s error, got %v", n, expected, err)
+		}
+		if isTerminal, _, _ := cloudprovidererrors.IsTerminalError(err); isTerminal {
+			t.Errorf("expected call %d to fail with a non terminal error", n)
+		}
+	}
+
+	if _, err := p.Create(machine, nil, ""); err != nil {
+		t.Fatalf("expected create to succeed after the injected errors, got %v", err)
+	}
+
+	for n := 0; n < 3; n++ {
+		isTerminal, reason, _ := cloudprovidererrors.IsTerminalError(p.Delete(machine, nil))
+		if !isTerminal || reason != common.DeleteMachineError {
+			t.Errorf("expected delete %d to fail with a terminal DeleteError, got %s", n, reason)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package fake
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+var (
+	// The provider gets created for every call, so the state needs to live outside of it
+	memory = &memoryStore{machines: map[types.UID]machineState{}}
+
+	clientLock sync.RWMutex
+	// client is used for the ConfigMap store. The provider gets created
+	// with a ConfigVarResolver only, so the client has to be set once on startup.
+	client kubernetes.Interface
+)
+
+// SetClient sets the client which gets used to store the state in a ConfigMap
+func SetClient(c kubernetes.Interface) {
+	clientLock.Lock()
+	defer clientLock.Unlock()
+	client = c
+}
+
+type instanceState struct {
+	Name      string     `json:"name"`
+	ID        string     `json:"id"`
+	Addresses []string   `json:"addresses"`
+	CreatedAt time.Time  `json:"createdAt"`
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
+}
+
+type machineState struct {
+	// Instance is nil when no instance exists for the machine
+	Instance *instanceState `json:"instance,omitempty"`
+	// Calls counts the calls per operation, used to inject errors
+	Calls map[Operation]int `json:"calls,omitempty"`
+}
+
+type store interface {
+	// get returns nil when there is no state for the machine
+	get(uid types.UID) (*machineState, error)
+	set(uid types.UID, state *machineState) error
+	delete(uid types.UID) error
+}
+
+func getStore(c *Config) store {
+	if c.StateConfigMap == nil {
+		return memory
+	}
+
+	clientLock.RLock()
+	defer clientLock.RUnlock()
+	return &configMapStore{client: client, ref: *c.StateConfigMap}
+}
+
+type memoryStore struct {
+	lock     sync.Mutex
+	machines map[types.UID]machineState
+}
+
+func (s *memoryStore) get(uid types.UID) (*machineState, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	state, ok := s.machines[uid]
+	if !ok {
+		return nil, nil
+	}
+	// Copy the state, so callers can't modify the stored one
+	return copyState(&state)
+}
+
+func (s *memoryStore) set(uid types.UID, state *machineState) error {
+	stored, err := copyState(state)
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.machines[uid] = *stored
+	return nil
+}
+
+func (s *memoryStore) delete(uid types.UID) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.machines, uid)
+	return nil
+}
+
+func copyState(state *machineState) (*machineState, error) {
+	raw, err := json.Marshal(state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal state: %v", err)
+	}
+	copied := &machineState{}
+	if err := json.Unmarshal(raw, copied); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal state: %v", err)
+	}
+	return copied, nil
+}
+
+// configMapStore stores the state of every machine as JSON under its UID
+type configMapStore struct {
+	client kubernetes.Interface
+	ref    ConfigMapReference
+}
+
+func (s *configMapStore) getConfigMap() (*corev1.ConfigMap, error) {
+	if s.client == nil {
+		return nil, fmt.Errorf("no client for the state ConfigMap configured")
+	}
+
+	cm, err := s.client.CoreV1().ConfigMaps(s.ref.Namespace).Get(s.ref.Name, metav1.GetOptions{})
+	if err != nil {
+		if !kerrors.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to get state ConfigMap %s/%s: %v", s.ref.Namespace, s.ref.Name, err)
+		}
+		cm, err = s.client.CoreV1().ConfigMaps(s.ref.Namespace).Create(&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: s.ref.Namespace, Name: s.ref.Name},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create state ConfigMap %s/%s: %v", s.ref.Namespace, s.ref.Name, err)
+		}
+	}
+	if cm.Data == nil {
+		cm.Data = map[string]string{}
+	}
+	return cm, nil
+}
+
+func (s *configMapStore) get(uid types.UID) (*machineState, error) {
+	cm, err := s.getConfigMap()
+	if err != nil {
+		return nil, err
+	}
+
+	raw, ok := cm.Data[string(uid)]
+	if !ok {
+		return nil, nil
+	}
+	state := &machineState{}
+	if err := json.Unmarshal([]byte(raw), state); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal state of machine %s: %v", uid, err)
+	}
+	return state, nil
+}
+
+func (s *configMapStore) set(uid types.UID, state *machineState) error {
+	raw, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to marshal state of machine %s: %v", uid, err)
+	}
+
+	cm, err := s.getConfigMap()
+	if err != nil {
+		return err
+	}
+	cm.Data[string(uid)] = string(raw)
+	return s.update(cm)
+}
+
+func (s *configMapStore) delete(uid types.UID) error {
+	cm, err := s.getConfigMap()
+	if err != nil {
+		return err
+	}
+	if _, ok := cm.Data[string(uid)]; !ok {
+		return nil
+	}
+	delete(cm.Data, string(uid))
+	return s.update(cm)
+}
+
+// update fails on conflicts, the controller retries the call then
+func (s *configMapStore) update(cm *corev1.ConfigMap) error {
+	if _, err := s.client.CoreV1().ConfigMaps(s.ref.Namespace).Update(cm); err != nil {
+		return fmt.Errorf("failed to update state ConfigMap %s/%s: %v", s.ref.Namespace, s.ref.Name, err)
+	}
+	return nil
+}
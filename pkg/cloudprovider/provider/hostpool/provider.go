@@ -0,0 +1,344 @@
+package hostpool
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/golang/glog"
+
+	"github.com/kubermatic/machine-controller/pkg/client/clientset/versioned"
+	"github.com/kubermatic/machine-controller/pkg/cloudprovider/cloud"
+	cloudprovidererrors "github.com/kubermatic/machine-controller/pkg/cloudprovider/errors"
+	"github.com/kubermatic/machine-controller/pkg/cloudprovider/instance"
+	hostpoolv1alpha1 "github.com/kubermatic/machine-controller/pkg/hostpool/v1alpha1"
+	"github.com/kubermatic/machine-controller/pkg/providerconfig"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+
+	common "sigs.k8s.io/cluster-api/pkg/apis/cluster/common"
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+)
+
+var (
+	clientLock sync.RWMutex
+	// client is used to claim and release hosts. The provider gets created
+	// with a ConfigVarResolver only, so the client has to be set once on startup.
+	client versioned.Interface
+)
+
+// SetClient sets the client which gets used by the provider to access the Host resources
+func SetClient(c versioned.Interface) {
+	clientLock.Lock()
+	defer clientLock.Unlock()
+	client = c
+}
+
+func getClient() (versioned.Interface, error) {
+	clientLock.RLock()
+	defer clientLock.RUnlock()
+	if client == nil {
+		return nil, errors.New("no client for the host pool configured")
+	}
+	return client, nil
+}
+
+type provider struct {
+	configVarResolver *providerconfig.ConfigVarResolver
+}
+
+// New returns a static host pool provider
+func New(configVarResolver *providerconfig.ConfigVarResolver) cloud.Provider {
+	return &provider{configVarResolver: configVarResolver}
+}
+
+type RawConfig struct {
+	// HostSelector selects the hosts which can be claimed by the Machine
+	HostSelector metav1.LabelSelector `json:"hostSelector"`
+}
+
+type Config struct {
+	HostSelector labels.Selector
+}
+
+func (p *provider) getConfig(s v1alpha1.ProviderConfig) (*Config, *providerconfig.Config, error) {
+	if s.Value == nil {
+		return nil, nil, fmt.Errorf("machine.spec.providerconfig.value is nil")
+	}
+	pconfig := providerconfig.Config{}
+	err := json.Unmarshal(s.Value.Raw, &pconfig)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rawConfig := RawConfig{}
+	if err = json.Unmarshal(pconfig.CloudProviderSpec.Raw, &rawConfig); err != nil {
+		return nil, nil, err
+	}
+
+	c := Config{}
+	c.HostSelector, err = metav1.LabelSelectorAsSelector(&rawConfig.HostSelector)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse \"hostSelector\" field, error = %v", err)
+	}
+	return &c, &pconfig, nil
+}
+
+// getClaimedHost returns the host which got claimed by the machine with the given UID
+func getClaimedHost(client versioned.Interface, uid types.UID) (*hostpoolv1alpha1.Host, error) {
+	hosts, err := client.HostpoolV1alpha1().Hosts().List(metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list hosts: %v", err)
+	}
+	for i := range hosts.Items {
+		if hosts.Items[i].Status.MachineUID == uid {
+			return &hosts.Items[i], nil
+		}
+	}
+	return nil, cloudprovidererrors.ErrInstanceNotFound
+}
+
+// claimHost claims a free host matching the selector for the machine.
+// The claim is an update of the host status, as the update fails on conflicting
+// resource versions two machines never get the same host.
+func claimHost(client versioned.Interface, selector labels.Selector, machine *v1alpha1.Machine) (*hostpoolv1alpha1.Host, error) {
+	hosts, err := client.HostpoolV1alpha1().Hosts().List(metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list hosts: %v", err)
+	}
+	sort.Slice(hosts.Items, func(i, j int) bool {
+		return hosts.Items[i].Name < hosts.Items[j].Name
+	})
+
+	for _, host := range hosts.Items {
+		if host.Status.MachineUID != "" {
+			continue
+		}
+
+		host.Status = hostpoolv1alpha1.HostStatus{
+			Phase:       hostpoolv1alpha1.HostPhaseProvisioning,
+			MachineUID:  machine.UID,
+			MachineName: machine.Spec.Name,
+		}
+		claimed, err := client.HostpoolV1alpha1().Hosts().Update(&host)
+		if err != nil {
+			if kerrors.IsConflict(err) {
+				// Someone else modified the host in the meantime, try the next one
+				continue
+			}
+			return nil, fmt.Errorf("failed to claim host %s: %v", host.Name, err)
+		}
+		glog.V(2).Infof("Claimed host %s for machine %s", claimed.Name, machine.Spec.Name)
+		return claimed, nil
+	}
+
+	return nil, fmt.Errorf("no free host matching the selector %q found", selector.String())
+}
+
+func setHostPhase(client versioned.Interface, host *hostpoolv1alpha1.Host, phase hostpoolv1alpha1.HostPhase) (*hostpoolv1alpha1.Host, error) {
+	host = host.DeepCopy()
+	host.Status.Phase = phase
+	updated, err := client.HostpoolV1alpha1().Hosts().Update(host)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set phase of host %s to %q: %v", host.Name, phase, err)
+	}
+	return updated, nil
+}
+
+func (p *provider) AddDefaults(spec v1alpha1.MachineSpec) (v1alpha1.MachineSpec, bool, error) {
+	return spec, false, nil
+}
+
+func (p *provider) Validate(spec v1alpha1.MachineSpec) error {
+	c, pc, err := p.getConfig(spec.ProviderConfig)
+	if err != nil {
+		return fmt.Errorf("failed to parse config: %v", err)
+	}
+
+	if c.HostSelector.Empty() {
+		return errors.New("hostSelector is required, an empty selector would claim any host of the pool")
+	}
+
+	switch pc.OperatingSystem {
+	case providerconfig.OperatingSystemUbuntu, providerconfig.OperatingSystemCentOS:
+	default:
+		// The userdata gets applied using cloud-init, which is not available on Container Linux
+		return fmt.Errorf("invalid/not supported operating system specified %q: %v", pc.OperatingSystem, providerconfig.ErrOSNotSupported)
+	}
+
+	return nil
+}
+
+func (p *provider) Get(machine *v1alpha1.Machine) (instance.Instance, error) {
+	client, err := getClient()
+	if err != nil {
+		return nil, err
+	}
+
+	host, err := getClaimedHost(client, machine.UID)
+	if err != nil {
+		return nil, err
+	}
+
+	// A host which is still being provisioned gets reported as missing,
+	// so Create picks it up again and retries the provisioning
+	if host.Status.Phase == hostpoolv1alpha1.HostPhaseProvisioning {
+		return nil, cloudprovidererrors.ErrInstanceNotFound
+	}
+
+	return &hostInstance{host: host}, nil
+}
+
+func (p *provider) GetCloudConfig(spec v1alpha1.MachineSpec) (config string, name string, err error) {
+	return "", "", nil
+}
+
+func (p *provider) Create(machine *v1alpha1.Machine, _ cloud.MachineUpdater, userdata string) (instance.Instance, error) {
+	c, _, err := p.getConfig(machine.Spec.ProviderConfig)
+	if err != nil {
+		return nil, cloudprovidererrors.TerminalError{
+			Reason:  common.InvalidConfigurationMachineError,
+			Message: fmt.Sprintf("Failed to parse MachineSpec, due to %v", err),
+		}
+	}
+
+	client, err := getClient()
+	if err != nil {
+		return nil, err
+	}
+
+	// A previous attempt might have claimed a host already
+	host, err := getClaimedHost(client, machine.UID)
+	if err != nil {
+		if err != cloudprovidererrors.ErrInstanceNotFound {
+			return nil, err
+		}
+		host, err = claimHost(client, c.HostSelector, machine)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if host.Status.Phase != hostpoolv1alpha1.HostPhaseProvisioning {
+		return &hostInstance{host: host}, nil
+	}
+
+	sshConfig, err := p.getSSHConfig(host)
+	if err != nil {
+		return nil, err
+	}
+	if err := runScript(host, sshConfig, provisionScript(machine, userdata)); err != nil {
+		return nil, fmt.Errorf("failed to provision host %s: %v", host.Name, err)
+	}
+
+	host, err = setHostPhase(client, host, hostpoolv1alpha1.HostPhaseProvisioned)
+	if err != nil {
+		return nil, err
+	}
+	return &hostInstance{host: host}, nil
+}
+
+func (p *provider) Delete(machine *v1alpha1.Machine, _ cloud.MachineUpdater) error {
+	client, err := getClient()
+	if err != nil {
+		return err
+	}
+
+	host, err := getClaimedHost(client, machine.UID)
+	if err != nil {
+		if err == cloudprovidererrors.ErrInstanceNotFound {
+			return nil
+		}
+		return err
+	}
+
+	if host.Status.Phase != hostpoolv1alpha1.HostPhaseReleasing {
+		host, err = setHostPhase(client, host, hostpoolv1alpha1.HostPhaseReleasing)
+		if err != nil {
+			return err
+		}
+	}
+
+	sshConfig, err := p.getSSHConfig(host)
+	if err != nil {
+		return err
+	}
+	if err := runScript(host, sshConfig, wipeScript); err != nil {
+		return fmt.Errorf("failed to wipe host %s: %v", host.Name, err)
+	}
+
+	host = host.DeepCopy()
+	host.Status = hostpoolv1alpha1.HostStatus{}
+	if _, err := client.HostpoolV1alpha1().Hosts().Update(host); err != nil {
+		return fmt.Errorf("failed to release host %s: %v", host.Name, err)
+	}
+	glog.V(2).Infof("Released host %s of machine %s", host.Name, machine.Spec.Name)
+	return nil
+}
+
+func (p *provider) MigrateUID(machine *v1alpha1.Machine, new types.UID) error {
+	client, err := getClient()
+	if err != nil {
+		return err
+	}
+
+	host, err := getClaimedHost(client, machine.UID)
+	if err != nil {
+		if err == cloudprovidererrors.ErrInstanceNotFound {
+			return nil
+		}
+		return err
+	}
+
+	host = host.DeepCopy()
+	host.Status.MachineUID = new
+	if _, err := client.HostpoolV1alpha1().Hosts().Update(host); err != nil {
+		return fmt.Errorf("failed to update machine UID of host %s: %v", host.Name, err)
+	}
+	return nil
+}
+
+func (p *provider) MachineMetricsLabels(machine *v1alpha1.Machine) (map[string]string, error) {
+	labels := make(map[string]string)
+
+	c, _, err := p.getConfig(machine.Spec.ProviderConfig)
+	if err == nil {
+		labels["selector"] = c.HostSelector.String()
+	}
+
+	return labels, err
+}
+
+type hostInstance struct {
+	host *hostpoolv1alpha1.Host
+}
+
+func (h *hostInstance) Name() string {
+	return h.host.Name
+}
+
+func (h *hostInstance) ID() string {
+	return h.host.Name
+}
+
+func (h *hostInstance) Addresses() []string {
+	return []string{h.host.Spec.Address}
+}
+
+func (h *hostInstance) Status() instance.Status {
+	switch h.host.Status.Phase {
+	case hostpoolv1alpha1.HostPhaseProvisioning:
+		return instance.StatusCreating
+	case hostpoolv1alpha1.HostPhaseProvisioned:
+		return instance.StatusRunning
+	case hostpoolv1alpha1.HostPhaseReleasing:
+		return instance.StatusDeleting
+	default:
+		return instance.StatusUnknown
+	}
+}
@@ -0,0 +1,174 @@
+package hostpool
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+
+	hostpoolfake "github.com/kubermatic/machine-controller/pkg/client/clientset/versioned/fake"
+	cloudprovidererrors "github.com/kubermatic/machine-controller/pkg/cloudprovider/errors"
+	"github.com/kubermatic/machine-controller/pkg/cloudprovider/instance"
+	hostpoolv1alpha1 "github.com/kubermatic/machine-controller/pkg/hostpool/v1alpha1"
+	"github.com/kubermatic/machine-controller/pkg/providerconfig"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+)
+
+func newHost(name, pool string) *hostpoolv1alpha1.Host {
+	return &hostpoolv1alpha1.Host{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{"pool": pool},
+		},
+		Spec: hostpoolv1alpha1.HostSpec{
+			Address: name + ".example.com",
+			SSH: hostpoolv1alpha1.SSHSpec{
+				User:                            "root",
+				InsecureSkipHostKeyVerification: true,
+				PrivateKeySecretRef: hostpoolv1alpha1.SecretKeyReference{
+					Namespace: "kube-system",
+					Name:      "hostpool-ssh",
+					Key:       "privateKey",
+				},
+			},
+		},
+	}
+}
+
+func newMachine(uid types.UID, pool string) *v1alpha1.Machine {
+	return &v1alpha1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "machine-" + string(uid),
+			UID:  uid,
+		},
+		Spec: v1alpha1.MachineSpec{
+			ObjectMeta: metav1.ObjectMeta{Name: "machine-" + string(uid)},
+			ProviderConfig: v1alpha1.ProviderConfig{
+				Value: &runtime.RawExtension{
+					Raw: []byte(fmt.Sprintf(`{"cloudProvider":"hostpool","operatingSystem":"ubuntu","cloudProviderSpec":{"hostSelector":{"matchLabels":{"pool":"%s"}}}}`, pool)),
+				},
+			},
+		},
+	}
+}
+
+func newSSHSecret(t *testing.T) *corev1.Secret {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "hostpool-ssh"},
+		Data: map[string][]byte{
+			"privateKey": pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}),
+		},
+	}
+}
+
+func TestProvider(t *testing.T) {
+	SetClient(hostpoolfake.NewSimpleClientset(
+		newHost("host-a", "workers"),
+		newHost("host-b", "workers"),
+		newHost("host-c", "other"),
+	))
+	defer SetClient(nil)
+
+	var scripts []string
+	runScript = func(host *hostpoolv1alpha1.Host, config *ssh.ClientConfig, script string) error {
+		scripts = append(scripts, host.Name+": "+script)
+		return nil
+	}
+	defer func() { runScript = runSSHScript }()
+
+	p := New(providerconfig.NewConfigVarResolver(kubefake.NewSimpleClientset(newSSHSecret(t))))
+
+	first, err := p.Create(newMachine("first", "workers"), nil, "#cloud-config")
+	if err != nil {
+		t.Fatalf("failed to create first machine: %v", err)
+	}
+	if first.Name() != "host-a" || first.Status() != instance.StatusRunning {
+		t.Errorf("expected first machine to be running on host-a, got %s with status %s", first.Name(), first.Status())
+	}
+	if len(scripts) != 1 || !strings.HasPrefix(scripts[0], "host-a: ") || !strings.Contains(scripts[0], "instance-id: first") {
+		t.Errorf("expected host-a to be provisioned, got %v", scripts)
+	}
+
+	second, err := p.Create(newMachine("second", "workers"), nil, "#cloud-config")
+	if err != nil {
+		t.Fatalf("failed to create second machine: %v", err)
+	}
+	if second.Name() != "host-b" {
+		t.Errorf("expected second machine to claim host-b, got %s", second.Name())
+	}
+
+	if _, err := p.Create(newMachine("third", "workers"), nil, "#cloud-config"); err == nil {
+		t.Errorf("expected an error when no free host is left")
+	}
+
+	i, err := p.Get(newMachine("first", "workers"))
+	if err != nil {
+		t.Fatalf("failed to get first machine: %v", err)
+	}
+	if i.ID() != "host-a" || len(i.Addresses()) != 1 || i.Addresses()[0] != "host-a.example.com" {
+		t.Errorf("got unexpected instance %s with addresses %v", i.ID(), i.Addresses())
+	}
+
+	if err := p.Delete(newMachine("first", "workers"), nil); err != nil {
+		t.Fatalf("failed to delete first machine: %v", err)
+	}
+	if _, err := p.Get(newMachine("first", "workers")); err != cloudprovidererrors.ErrInstanceNotFound {
+		t.Errorf("expected ErrInstanceNotFound after deletion, got %v", err)
+	}
+	if err := p.Delete(newMachine("first", "workers"), nil); err != nil {
+		t.Errorf("expected deleting a released machine to succeed, got %v", err)
+	}
+
+	third, err := p.Create(newMachine("third", "workers"), nil, "#cloud-config")
+	if err != nil {
+		t.Fatalf("failed to create third machine: %v", err)
+	}
+	if third.Name() != "host-a" {
+		t.Errorf("expected third machine to claim the released host-a, got %s", third.Name())
+	}
+}
+
+func TestGetSSHConfigRequiresHostKey(t *testing.T) {
+	p := New(providerconfig.NewConfigVarResolver(kubefake.NewSimpleClientset(newSSHSecret(t)))).(*provider)
+
+	host := newHost("host-a", "workers")
+	host.Spec.SSH.InsecureSkipHostKeyVerification = false
+	if _, err := p.getSSHConfig(host); err == nil {
+		t.Errorf("expected an error for a host without hostPublicKey")
+	}
+
+	host.Spec.SSH.HostPublicKey = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIOMqqnkVzrm0SdG6UOoqKLsabgH5C9okWi0dh2l9GKJl"
+	if _, err := p.getSSHConfig(host); err != nil {
+		t.Errorf("expected a host with hostPublicKey to be accepted, got %v", err)
+	}
+}
+
+func TestValidateRequiresHostSelector(t *testing.T) {
+	p := New(providerconfig.NewConfigVarResolver(kubefake.NewSimpleClientset()))
+
+	machine := newMachine("first", "workers")
+	if err := p.Validate(machine.Spec); err != nil {
+		t.Errorf("expected machine with host selector to be valid, got %v", err)
+	}
+
+	machine.Spec.ProviderConfig.Value.Raw = []byte(`{"cloudProvider":"hostpool","operatingSystem":"ubuntu","cloudProviderSpec":{"hostSelector":{}}}`)
+	if err := p.Validate(machine.Spec); err == nil {
+		t.Errorf("expected an error for an empty host selector")
+	}
+}
@@ -0,0 +1,138 @@
+package hostpool
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+
+	hostpoolv1alpha1 "github.com/kubermatic/machine-controller/pkg/hostpool/v1alpha1"
+	"github.com/kubermatic/machine-controller/pkg/providerconfig"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+)
+
+const (
+	defaultSSHPort = 22
+	sshTimeout     = 30 * time.Second
+
+	noCloudSeedDir    = "/var/lib/cloud/seed/nocloud"
+	datasourceCfgFile = "/etc/cloud/cloud.cfg.d/99_machine_controller_nocloud.cfg"
+	provisioningLog   = "/var/log/machine-controller-provisioning.log"
+)
+
+// runScript gets replaced in tests
+var runScript = runSSHScript
+
+func (p *provider) getSSHConfig(host *hostpoolv1alpha1.Host) (*ssh.ClientConfig, error) {
+	ref := host.Spec.SSH.PrivateKeySecretRef
+	privateKey, err := p.configVarResolver.GetConfigVarStringValue(providerconfig.ConfigVarString{
+		SecretKeyRef: providerconfig.GlobalSecretKeySelector{
+			ObjectReference: corev1.ObjectReference{Namespace: ref.Namespace, Name: ref.Name},
+			Key:             ref.Key,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the ssh private key of host %s: %v", host.Name, err)
+	}
+
+	signer, err := ssh.ParsePrivateKey([]byte(privateKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the ssh private key of host %s: %v", host.Name, err)
+	}
+
+	var hostKeyCallback ssh.HostKeyCallback
+	switch {
+	case host.Spec.SSH.HostPublicKey != "":
+		hostKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(host.Spec.SSH.HostPublicKey))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the public key of host %s: %v", host.Name, err)
+		}
+		hostKeyCallback = ssh.FixedHostKey(hostKey)
+	case host.Spec.SSH.InsecureSkipHostKeyVerification:
+		hostKeyCallback = ssh.InsecureIgnoreHostKey()
+	default:
+		return nil, fmt.Errorf("host %s has no hostPublicKey, set insecureSkipHostKeyVerification to connect without verifying the host key", host.Name)
+	}
+
+	return &ssh.ClientConfig{
+		User:            host.Spec.SSH.User,
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         sshTimeout,
+	}, nil
+}
+
+// runSSHScript executes the script with bash on the host.
+// The script gets passed via stdin, so its size is not limited by the maximum command line length.
+func runSSHScript(host *hostpoolv1alpha1.Host, config *ssh.ClientConfig, script string) error {
+	port := host.Spec.SSH.Port
+	if port == 0 {
+		port = defaultSSHPort
+	}
+
+	conn, err := ssh.Dial("tcp", net.JoinHostPort(host.Spec.Address, strconv.Itoa(port)), config)
+	if err != nil {
+		return fmt.Errorf("failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	session, err := conn.NewSession()
+	if err != nil {
+		return fmt.Errorf("failed to create session: %v", err)
+	}
+	defer session.Close()
+
+	var output bytes.Buffer
+	session.Stdin = bytes.NewBufferString(script)
+	session.Stdout = &output
+	session.Stderr = &output
+
+	cmd := "bash -s"
+	if config.User != "root" {
+		cmd = "sudo -n " + cmd
+	}
+	if err := session.Run(cmd); err != nil {
+		return fmt.Errorf("script failed: %v, output: %s", err, output.String())
+	}
+	return nil
+}
+
+// provisionScript writes the userdata as NoCloud seed and runs cloud-init in the background,
+// as the userdata might restart the network or the ssh daemon.
+func provisionScript(machine *v1alpha1.Machine, userdata string) string {
+	return fmt.Sprintf(`set -xeuo pipefail
+mkdir -p %[1]s
+echo '%[2]s' | base64 -d > %[1]s/user-data
+cat > %[1]s/meta-data <<EOF
+instance-id: %[3]s
+local-hostname: %[4]s
+EOF
+cat > %[5]s <<EOF
+datasource_list: [ NoCloud, None ]
+EOF
+cloud-init clean --logs
+nohup sh -c 'cloud-init init --local && cloud-init init && cloud-init modules --mode=config && cloud-init modules --mode=final' > %[6]s 2>&1 &
+`, noCloudSeedDir, base64.StdEncoding.EncodeToString([]byte(userdata)), machine.UID, machine.Spec.Name, datasourceCfgFile, provisioningLog)
+}
+
+// wipeScript removes everything the userdata set up, so the host can be claimed again.
+// Errors are ignored, as a previous attempt might have removed parts already.
+var wipeScript = fmt.Sprintf(`set -xuo pipefail
+systemctl disable --now kubelet kubelet-healthcheck docker-healthcheck setup
+docker ps -aq | xargs -r docker rm -f
+systemctl stop docker
+rm -rf /etc/kubernetes /var/lib/kubelet /etc/cni /opt/cni /var/lib/cni /opt/bin
+rm -rf /etc/systemd/system/kubelet.service /etc/systemd/system/kubelet.service.d
+rm -rf /etc/systemd/system/kubelet-healthcheck.service /etc/systemd/system/docker-healthcheck.service /etc/systemd/system/setup.service
+systemctl daemon-reload
+rm -rf %s %s %s
+cloud-init clean --logs
+exit 0
+`, noCloudSeedDir, datasourceCfgFile, provisioningLog)
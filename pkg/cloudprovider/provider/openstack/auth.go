@@ -0,0 +1,133 @@
+package openstack
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Masterminds/semver"
+	"github.com/gophercloud/gophercloud"
+	goopenstack "github.com/gophercloud/gophercloud/openstack"
+
+	"github.com/kubermatic/machine-controller/pkg/userdata/cloud"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+)
+
+const (
+	// caBundlePath is the path of the CA bundle on the machines, the kubelets read it from there
+	caBundlePath = "/etc/kubernetes/openstack-ca-bundle.pem"
+
+	// applicationCredentialsMinKubeletVersion is the first kubelet whose in-tree cloud provider authenticates with
+	// application credentials, older ones only read the username and password from the cloud config
+	applicationCredentialsMinKubeletVersion = "1.13.0"
+)
+
+var (
+	endpointTypes = sets.NewString(
+		string(gophercloud.AvailabilityPublic),
+		string(gophercloud.AvailabilityInternal),
+		string(gophercloud.AvailabilityAdmin),
+	)
+
+	// endpointTypeServices are the services whose endpoints get looked up with the configured endpoint type,
+	// the identity endpoint is configured explicitly
+	endpointTypeServices = sets.NewString("compute", "network", "image", "volumev2")
+)
+
+func getClient(c *Config) (*gophercloud.ProviderClient, error) {
+	opts := gophercloud.AuthOptions{IdentityEndpoint: c.IdentityEndpoint}
+	if c.ApplicationCredentialID != "" {
+		// Application credentials are scoped to their project already
+		opts.ApplicationCredentialID = c.ApplicationCredentialID
+		opts.ApplicationCredentialSecret = c.ApplicationCredentialSecret
+	} else {
+		opts.Username = c.Username
+		opts.Password = c.Password
+		opts.DomainName = c.DomainName
+		opts.TenantName = c.TenantName
+		opts.TokenID = c.TokenID
+	}
+
+	client, err := goopenstack.NewClient(c.IdentityEndpoint)
+	if err != nil {
+		return nil, err
+	}
+	if c.CACert != "" {
+		pool, err := getCACertPool(c.CACert)
+		if err != nil {
+			return nil, err
+		}
+		client.HTTPClient = http.Client{
+			Transport: &http.Transport{
+				Proxy:           http.ProxyFromEnvironment,
+				TLSClientConfig: &tls.Config{RootCAs: pool},
+			},
+		}
+	}
+	if err := goopenstack.Authenticate(client, opts); err != nil {
+		return nil, err
+	}
+
+	// The endpoint locator is set by the authentication, it gets wrapped to apply the endpoint type to all clients
+	locateEndpoint := client.EndpointLocator
+	endpointType := gophercloud.Availability(c.EndpointType)
+	client.EndpointLocator = func(eo gophercloud.EndpointOpts) (string, error) {
+		if endpointTypeServices.Has(eo.Type) {
+			eo.Availability = endpointType
+		}
+		return locateEndpoint(eo)
+	}
+	return client, nil
+}
+
+func getCACertPool(caCert string) (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM([]byte(caCert)) {
+		return nil, errors.New("caCert doesn't contain any PEM encoded certificate")
+	}
+	return pool, nil
+}
+
+func validateCredentials(c *Config, kubeletVersion string) error {
+	if c.ApplicationCredentialID != "" && c.ApplicationCredentialSecret == "" {
+		return errors.New("applicationCredentialSecret is required with applicationCredentialID")
+	}
+	if c.ApplicationCredentialID != "" {
+		version, err := semver.NewVersion(kubeletVersion)
+		if err != nil {
+			return fmt.Errorf("invalid kubelet version %q: %v", kubeletVersion, err)
+		}
+		if version.LessThan(semver.MustParse(applicationCredentialsMinKubeletVersion)) {
+			return fmt.Errorf("application credentials require kubelet %s or newer, the kubelet %s can't authenticate with them", applicationCredentialsMinKubeletVersion, kubeletVersion)
+		}
+	}
+	if c.ApplicationCredentialID == "" && c.ApplicationCredentialSecret != "" {
+		return errors.New("applicationCredentialSecret requires applicationCredentialID")
+	}
+	if !endpointTypes.Has(c.EndpointType) {
+		return fmt.Errorf("invalid endpoint type %q specified. Supported: %s", c.EndpointType, endpointTypes.List())
+	}
+	if c.CACert != "" {
+		if _, err := getCACertPool(c.CACert); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetCloudConfigFiles returns the CA bundle, the cloud config refers to it
+func (p *provider) GetCloudConfigFiles(spec v1alpha1.MachineSpec) ([]cloud.CloudConfigFile, error) {
+	c, _, _, err := p.getConfig(spec.ProviderConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config: %v", err)
+	}
+	if c.CACert == "" {
+		return nil, nil
+	}
+	return []cloud.CloudConfigFile{{Path: caBundlePath, Content: c.CACert}}, nil
+}
@@ -0,0 +1,61 @@
+package openstack
+
+import (
+	"testing"
+)
+
+func TestValidateCredentials(t *testing.T) {
+	tests := []struct {
+		name           string
+		config         *Config
+		kubeletVersion string
+		expectErr      bool
+	}{
+		{
+			name:           "password",
+			config:         &Config{Username: "user", Password: "password", EndpointType: "public"},
+			kubeletVersion: "1.10.8",
+		},
+		{
+			name:           "application credentials",
+			config:         &Config{ApplicationCredentialID: "id", ApplicationCredentialSecret: "secret", EndpointType: "public"},
+			kubeletVersion: "1.13.0",
+		},
+		{
+			name:           "application credentials with old kubelet",
+			config:         &Config{ApplicationCredentialID: "id", ApplicationCredentialSecret: "secret", EndpointType: "public"},
+			kubeletVersion: "1.12.1",
+			expectErr:      true,
+		},
+		{
+			name:           "application credentials with invalid kubelet version",
+			config:         &Config{ApplicationCredentialID: "id", ApplicationCredentialSecret: "secret", EndpointType: "public"},
+			kubeletVersion: "latest",
+			expectErr:      true,
+		},
+		{
+			name:           "application credential without secret",
+			config:         &Config{ApplicationCredentialID: "id", EndpointType: "public"},
+			kubeletVersion: "1.13.0",
+			expectErr:      true,
+		},
+		{
+			name:           "invalid endpoint type",
+			config:         &Config{Username: "user", Password: "password", EndpointType: "private"},
+			kubeletVersion: "1.13.0",
+			expectErr:      true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := validateCredentials(test.config, test.kubeletVersion)
+			if test.expectErr && err == nil {
+				t.Error("expected an error, got none")
+			}
+			if !test.expectErr && err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+		})
+	}
+}
@@ -18,6 +18,16 @@ password    = {{ .Global.Password | iniEscape }}
 tenant-name = {{ .Global.TenantName | iniEscape }}
 domain-name = {{ .Global.DomainName | iniEscape }}
 region      = {{ .Global.Region | iniEscape }}
+{{- if .Global.ApplicationCredentialID }}
+application-credential-id     = {{ .Global.ApplicationCredentialID | iniEscape }}
+application-credential-secret = {{ .Global.ApplicationCredentialSecret | iniEscape }}
+{{- end }}
+{{- if .Global.CAFile }}
+ca-file = {{ .Global.CAFile | iniEscape }}
+{{- end }}
+{{- if .Global.EndpointType }}
+os-endpoint-type = {{ .Global.EndpointType | iniEscape }}
+{{- end }}
 
 [LoadBalancer]
 manage-security-groups = {{ .LoadBalancer.ManageSecurityGroups }}
@@ -46,6 +56,13 @@ type GlobalOpts struct {
 	TenantName string `gcfg:"tenant-name"`
 	DomainName string `gcfg:"domain-name"`
 	Region     string
+
+	ApplicationCredentialID     string `gcfg:"application-credential-id"`
+	ApplicationCredentialSecret string `gcfg:"application-credential-secret"`
+	// CAFile is the path of the CA bundle the endpoints get verified with
+	CAFile string `gcfg:"ca-file"`
+	// EndpointType is the interface of the service endpoints, one of public, internal or admin
+	EndpointType string `gcfg:"os-endpoint-type"`
 }
 
 // CloudConfig is used to read and store information from the cloud configuration file
@@ -58,6 +58,27 @@ func TestCloudConfigToString(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "config-with-application-credentials",
+			config: &CloudConfig{
+				Global: GlobalOpts{
+					AuthURL:                     "https://127.0.0.1:8443",
+					Region:                      "eu-central1",
+					ApplicationCredentialID:     "app-credential-id",
+					ApplicationCredentialSecret: "app-credential-secret",
+					CAFile:                      "/etc/kubernetes/openstack-ca-bundle.pem",
+					EndpointType:                "internal",
+				},
+				BlockStorage: BlockStorageOpts{
+					BSVersion:       "v2",
+					IgnoreVolumeAZ:  true,
+					TrustDevicePath: true,
+				},
+				LoadBalancer: LoadBalancerOpts{
+					ManageSecurityGroups: true,
+				},
+			},
+		},
 	}
 
 	for _, test := range tests {
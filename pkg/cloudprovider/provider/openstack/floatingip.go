@@ -0,0 +1,121 @@
+package openstack
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/golang/glog"
+
+	"github.com/gophercloud/gophercloud"
+	goopenstack "github.com/gophercloud/gophercloud/openstack"
+	osfloatingips "github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/layer3/floatingips"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	// floatingIPReusePolicyAny reuses any unattached floating IP of the pool before allocating a new one
+	floatingIPReusePolicyAny = "Any"
+	// floatingIPReusePolicyNever always allocates a new floating IP
+	floatingIPReusePolicyNever = "Never"
+
+	// floatingIPDescriptionPrefix marks the floating IPs allocated by the machine-controller, followed by the machine's UID
+	floatingIPDescriptionPrefix = "allocated by the machine-controller for machine "
+)
+
+var floatingIPReusePolicies = sets.NewString(floatingIPReusePolicyAny, floatingIPReusePolicyNever)
+
+func getFloatingIPDescription(machineUID types.UID) string {
+	return floatingIPDescriptionPrefix + string(machineUID)
+}
+
+func isAllocatedByController(ip osfloatingips.FloatingIP) bool {
+	return strings.HasPrefix(ip.Description, floatingIPDescriptionPrefix)
+}
+
+func getFloatingIPsByMachineUID(netClient *gophercloud.ServiceClient, machineUID types.UID) ([]osfloatingips.FloatingIP, error) {
+	description := getFloatingIPDescription(machineUID)
+	allPages, err := osfloatingips.List(netClient, osfloatingips.ListOpts{Description: description}).AllPages()
+	if err != nil {
+		return nil, err
+	}
+
+	allFIPs, err := osfloatingips.ExtractFloatingIPs(allPages)
+	if err != nil {
+		return nil, err
+	}
+
+	// older networking services ignore the description filter
+	var matchingFIPs []osfloatingips.FloatingIP
+	for _, f := range allFIPs {
+		if f.Description == description {
+			matchingFIPs = append(matchingFIPs, f)
+		}
+	}
+	return matchingFIPs, nil
+}
+
+// releaseFloatingIPs releases the floating IPs the machine-controller allocated for the machine.
+// Reused floating IPs which were allocated by someone else are kept.
+func releaseFloatingIPs(client *gophercloud.ProviderClient, region string, machineUID types.UID) error {
+	netClient, err := goopenstack.NewNetworkV2(client, gophercloud.EndpointOpts{Region: region})
+	if err != nil {
+		return fmt.Errorf("failed to create the networkv2 client for region %s: %v", region, err)
+	}
+
+	fips, err := getFloatingIPsByMachineUID(netClient, machineUID)
+	if err != nil {
+		return osErrorToTerminalError(err, "failed to list floating ips")
+	}
+
+	for _, f := range fips {
+		glog.V(2).Infof("Releasing FloatingIP %s of machine %s", f.FloatingIP, machineUID)
+		if err := osfloatingips.Delete(netClient, f.ID).ExtractErr(); err != nil {
+			if _, ok := err.(gophercloud.ErrDefault404); ok {
+				continue
+			}
+			return osErrorToTerminalError(err, fmt.Sprintf("failed to release floating ip %s", f.FloatingIP))
+		}
+	}
+	return nil
+}
+
+// releaseUnattachedFloatingIPs releases the given unattached floating IPs which were allocated by the machine-controller,
+// they are left over from failed creations. The floating IPs which were allocated by someone else get returned.
+func releaseUnattachedFloatingIPs(netClient *gophercloud.ServiceClient, freeFIPs []osfloatingips.FloatingIP) []osfloatingips.FloatingIP {
+	var foreignFIPs []osfloatingips.FloatingIP
+	for _, f := range freeFIPs {
+		if !isAllocatedByController(f) {
+			foreignFIPs = append(foreignFIPs, f)
+			continue
+		}
+		glog.V(2).Infof("Releasing unattached FloatingIP %s (%s)", f.FloatingIP, f.Description)
+		if err := osfloatingips.Delete(netClient, f.ID).ExtractErr(); err != nil {
+			// The cleanup is best effort, it gets retried with the next assignment
+			glog.V(2).Infof("failed to release unattached FloatingIP %s: %v", f.FloatingIP, err)
+		}
+	}
+	return foreignFIPs
+}
+
+// migrateFloatingIPs records the floating IPs allocated for the machine against its new UID
+func migrateFloatingIPs(client *gophercloud.ProviderClient, region string, machineUID, newUID types.UID) error {
+	netClient, err := goopenstack.NewNetworkV2(client, gophercloud.EndpointOpts{Region: region})
+	if err != nil {
+		return fmt.Errorf("failed to create the networkv2 client for region %s: %v", region, err)
+	}
+
+	fips, err := getFloatingIPsByMachineUID(netClient, machineUID)
+	if err != nil {
+		return osErrorToTerminalError(err, "failed to list floating ips")
+	}
+
+	description := getFloatingIPDescription(newUID)
+	for _, f := range fips {
+		if _, err := osfloatingips.Update(netClient, f.ID, osfloatingips.UpdateOpts{Description: &description}).Extract(); err != nil {
+			return fmt.Errorf("failed to update FloatingIP %s with new UID: %v", f.FloatingIP, err)
+		}
+	}
+	return nil
+}
@@ -21,6 +21,8 @@ import (
 	osports "github.com/gophercloud/gophercloud/openstack/networking/v2/ports"
 	ossubnets "github.com/gophercloud/gophercloud/openstack/networking/v2/subnets"
 	"github.com/gophercloud/gophercloud/pagination"
+
+	"k8s.io/apimachinery/pkg/types"
 )
 
 var (
@@ -331,7 +333,9 @@ func getFreeFloatingIPs(client *gophercloud.ProviderClient, region string, float
 	return freeFIPs, nil
 }
 
-func assignFloatingIPToInstance(client *gophercloud.ProviderClient, instanceID, floatingIPPoolName, region string, network *osnetworks.Network) error {
+// assignFloatingIPToInstance assigns a free floating IP of the pool to the instance, depending on the reuse policy,
+// or allocates a new one. Allocated floating IPs get recorded against the machine, so they get released on deletion.
+func assignFloatingIPToInstance(client *gophercloud.ProviderClient, machineUID types.UID, instanceID, floatingIPPoolName, reusePolicy, region string, network *osnetworks.Network) error {
 	port, err := getInstancePort(client, region, instanceID, network.ID)
 	if err != nil {
 		return fmt.Errorf("failed to get instance port for network %s in region %s: %v", network.ID, region, err)
@@ -358,10 +362,14 @@ func assignFloatingIPToInstance(client *gophercloud.ProviderClient, instanceID,
 	if err != nil {
 		return osErrorToTerminalError(err, "failed to get free floating ips")
 	}
+	freeFloatingIps = releaseUnattachedFloatingIPs(netClient, freeFloatingIps)
+	if reusePolicy == floatingIPReusePolicyNever {
+		freeFloatingIps = nil
+	}
 
 	var ip *osfloatingips.FloatingIP
 	if len(freeFloatingIps) < 1 {
-		if ip, err = createFloatingIP(client, region, port.ID, floatingIPPool); err != nil {
+		if ip, err = createFloatingIP(client, region, port.ID, getFloatingIPDescription(machineUID), floatingIPPool); err != nil {
 			return osErrorToTerminalError(err, "failed to allocate a floating ip")
 		}
 	} else {
@@ -391,13 +399,14 @@ func assignFloatingIPToInstance(client *gophercloud.ProviderClient, instanceID,
 	return nil
 }
 
-func createFloatingIP(client *gophercloud.ProviderClient, region, portID string, floatingIPPool *osnetworks.Network) (*osfloatingips.FloatingIP, error) {
+func createFloatingIP(client *gophercloud.ProviderClient, region, portID, description string, floatingIPPool *osnetworks.Network) (*osfloatingips.FloatingIP, error) {
 	netClient, err := goopenstack.NewNetworkV2(client, gophercloud.EndpointOpts{Region: region})
 	if err != nil {
 		return nil, err
 	}
 
 	opts := osfloatingips.CreateOpts{
+		Description:       description,
 		FloatingNetworkID: floatingIPPool.ID,
 		PortID:            portID,
 	}
@@ -0,0 +1,321 @@
+package openstack
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/golang/glog"
+
+	"github.com/gophercloud/gophercloud"
+	goopenstack "github.com/gophercloud/gophercloud/openstack"
+	osservers "github.com/gophercloud/gophercloud/openstack/compute/v2/servers"
+	osattributestags "github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/attributestags"
+	osportsecurity "github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/portsecurity"
+	osports "github.com/gophercloud/gophercloud/openstack/networking/v2/ports"
+
+	"github.com/kubermatic/machine-controller/pkg/providerconfig"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+)
+
+// PortRawConfig is an additional port of the instance, it gets created before the instance
+type PortRawConfig struct {
+	// Network is the name or id of the network of the port
+	Network providerconfig.ConfigVarString `json:"network"`
+	// FixedIPs are allocated from the subnets of the network, a dynamic address gets allocated without them
+	FixedIPs []FixedIPRawConfig `json:"fixedIPs,omitempty"`
+	// AllowedAddressPairs are additional addresses the instance may send traffic from
+	AllowedAddressPairs []AllowedAddressPairRawConfig `json:"allowedAddressPairs,omitempty"`
+	// DisablePortSecurity removes the security groups and the anti-spoofing rules from the port
+	DisablePortSecurity bool `json:"disablePortSecurity,omitempty"`
+}
+
+type FixedIPRawConfig struct {
+	// Subnet is the name or id of the subnet
+	Subnet providerconfig.ConfigVarString `json:"subnet"`
+	// IPAddress gets allocated from the subnet, a dynamic address gets allocated without it.
+	// It can only be allocated to a single port, so it is not suited for MachineDeployments with more than one replica.
+	IPAddress providerconfig.ConfigVarString `json:"ipAddress,omitempty"`
+}
+
+type AllowedAddressPairRawConfig struct {
+	// IPAddress is an IP address or a CIDR
+	IPAddress providerconfig.ConfigVarString `json:"ipAddress"`
+	// MACAddress defaults to the MAC address of the port
+	MACAddress providerconfig.ConfigVarString `json:"macAddress,omitempty"`
+}
+
+type PortConfig struct {
+	Network             string
+	FixedIPs            []FixedIPConfig
+	AllowedAddressPairs []osports.AddressPair
+	DisablePortSecurity bool
+}
+
+type FixedIPConfig struct {
+	Subnet    string
+	IPAddress string
+}
+
+func (p *provider) getPortConfig(raw PortRawConfig) (*PortConfig, error) {
+	var err error
+	c := PortConfig{DisablePortSecurity: raw.DisablePortSecurity}
+	c.Network, err = p.configVarResolver.GetConfigVarStringValue(raw.Network)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the value of \"ports.network\" field, error = %v", err)
+	}
+	for _, rawIP := range raw.FixedIPs {
+		ip := FixedIPConfig{}
+		ip.Subnet, err = p.configVarResolver.GetConfigVarStringValue(rawIP.Subnet)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the value of \"ports.fixedIPs.subnet\" field, error = %v", err)
+		}
+		ip.IPAddress, err = p.configVarResolver.GetConfigVarStringValue(rawIP.IPAddress)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the value of \"ports.fixedIPs.ipAddress\" field, error = %v", err)
+		}
+		c.FixedIPs = append(c.FixedIPs, ip)
+	}
+	for _, rawPair := range raw.AllowedAddressPairs {
+		pair := osports.AddressPair{}
+		pair.IPAddress, err = p.configVarResolver.GetConfigVarStringValue(rawPair.IPAddress)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the value of \"ports.allowedAddressPairs.ipAddress\" field, error = %v", err)
+		}
+		pair.MACAddress, err = p.configVarResolver.GetConfigVarStringValue(rawPair.MACAddress)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the value of \"ports.allowedAddressPairs.macAddress\" field, error = %v", err)
+		}
+		c.AllowedAddressPairs = append(c.AllowedAddressPairs, pair)
+	}
+	return &c, nil
+}
+
+// validatePorts verifies that the networks and subnets of the ports exist, and that the fixed IPs
+// belong to their subnets
+func validatePorts(client *gophercloud.ProviderClient, c *Config) error {
+	for i, p := range c.Ports {
+		if p.Network == "" {
+			return fmt.Errorf("network of port %d is missing", i)
+		}
+		network, err := getNetwork(client, c.Region, p.Network)
+		if err != nil {
+			return fmt.Errorf("failed to get network %q of port %d: %v", p.Network, i, err)
+		}
+
+		for _, ip := range p.FixedIPs {
+			subnet, err := getSubnet(client, c.Region, ip.Subnet)
+			if err != nil {
+				return fmt.Errorf("failed to get subnet %q of port %d: %v", ip.Subnet, i, err)
+			}
+			if subnet.NetworkID != network.ID {
+				return fmt.Errorf("subnet %q of port %d is not in network %q", ip.Subnet, i, p.Network)
+			}
+			if ip.IPAddress == "" {
+				continue
+			}
+			_, cidr, err := net.ParseCIDR(subnet.CIDR)
+			if err != nil {
+				return fmt.Errorf("failed to parse the cidr of subnet %q: %v", ip.Subnet, err)
+			}
+			if !cidr.Contains(net.ParseIP(ip.IPAddress)) {
+				return fmt.Errorf("fixed ip %q of port %d is not in subnet %q (%s)", ip.IPAddress, i, ip.Subnet, cidr)
+			}
+		}
+
+		if p.DisablePortSecurity && len(p.AllowedAddressPairs) > 0 {
+			return fmt.Errorf("allowed address pairs of port %d require port security", i)
+		}
+		for _, pair := range p.AllowedAddressPairs {
+			if net.ParseIP(pair.IPAddress) == nil {
+				if _, _, err := net.ParseCIDR(pair.IPAddress); err != nil {
+					return fmt.Errorf("allowed address %q of port %d is neither an IP address nor a CIDR", pair.IPAddress, i)
+				}
+			}
+			if pair.MACAddress != "" {
+				if _, err := net.ParseMAC(pair.MACAddress); err != nil {
+					return fmt.Errorf("invalid mac address %q of port %d: %v", pair.MACAddress, i, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func getPortName(machineName string, index int) string {
+	return fmt.Sprintf("%s-port-%d", machineName, index)
+}
+
+func getPortTag(machineUID types.UID) string {
+	return fmt.Sprintf("%s=%s", machineUIDMetaKey, machineUID)
+}
+
+func getPortsByMachineUID(netClient *gophercloud.ServiceClient, machineUID types.UID) ([]osports.Port, error) {
+	tag := getPortTag(machineUID)
+	allPages, err := osports.List(netClient, osports.ListOpts{Tags: tag}).AllPages()
+	if err != nil {
+		return nil, err
+	}
+
+	allPorts, err := osports.ExtractPorts(allPages)
+	if err != nil {
+		return nil, err
+	}
+
+	// the tags filter gets ignored without the tagging extension
+	var matchingPorts []osports.Port
+	for _, p := range allPorts {
+		if sets.NewString(p.Tags...).Has(tag) {
+			matchingPorts = append(matchingPorts, p)
+		}
+	}
+	return matchingPorts, nil
+}
+
+// ensurePorts creates the additional ports of the instance and returns them as networks of the instance.
+// The ports are tagged with the machine's UID, so the ports of a previous attempt get reused.
+func ensurePorts(client *gophercloud.ProviderClient, c *Config, machine *v1alpha1.Machine, securityGroups []string) ([]osservers.Network, error) {
+	if len(c.Ports) == 0 {
+		return nil, nil
+	}
+
+	netClient, err := goopenstack.NewNetworkV2(client, gophercloud.EndpointOpts{Region: c.Region})
+	if err != nil {
+		return nil, osErrorToTerminalError(err, "failed to get network client")
+	}
+
+	existingPorts, err := getPortsByMachineUID(netClient, machine.UID)
+	if err != nil {
+		return nil, osErrorToTerminalError(err, "failed to list ports")
+	}
+	portIDs := map[string]string{}
+	for _, p := range existingPorts {
+		portIDs[p.Name] = p.ID
+	}
+
+	var securityGroupIDs []string
+	for _, name := range securityGroups {
+		securityGroup, err := getSecurityGroup(client, c.Region, name)
+		if err != nil {
+			return nil, osErrorToTerminalError(err, fmt.Sprintf("failed to get security group %s", name))
+		}
+		securityGroupIDs = append(securityGroupIDs, securityGroup.ID)
+	}
+
+	var networks []osservers.Network
+	for i, p := range c.Ports {
+		name := getPortName(machine.Spec.Name, i)
+		if id, ok := portIDs[name]; ok {
+			networks = append(networks, osservers.Network{Port: id})
+			continue
+		}
+
+		port, err := createPort(client, netClient, c.Region, name, p, securityGroupIDs)
+		if err != nil {
+			return nil, err
+		}
+		tags := osattributestags.ReplaceAllOpts{Tags: []string{getPortTag(machine.UID)}}
+		if _, err := osattributestags.ReplaceAll(netClient, "ports", port.ID, tags).Extract(); err != nil {
+			// an untagged port wouldn't get cleaned up
+			if err := osports.Delete(netClient, port.ID).ExtractErr(); err != nil {
+				glog.V(2).Infof("failed to delete untagged port %s: %v", port.ID, err)
+			}
+			return nil, osErrorToTerminalError(err, fmt.Sprintf("failed to tag port %s", name))
+		}
+		networks = append(networks, osservers.Network{Port: port.ID})
+	}
+	return networks, nil
+}
+
+func createPort(client *gophercloud.ProviderClient, netClient *gophercloud.ServiceClient, region, name string, c PortConfig, securityGroupIDs []string) (*osports.Port, error) {
+	network, err := getNetwork(client, region, c.Network)
+	if err != nil {
+		return nil, osErrorToTerminalError(err, fmt.Sprintf("failed to get network %s", c.Network))
+	}
+
+	opts := osports.CreateOpts{
+		Name:                name,
+		NetworkID:           network.ID,
+		AllowedAddressPairs: c.AllowedAddressPairs,
+	}
+	if len(c.FixedIPs) > 0 {
+		var fixedIPs []osports.IP
+		for _, ip := range c.FixedIPs {
+			subnet, err := getSubnet(client, region, ip.Subnet)
+			if err != nil {
+				return nil, osErrorToTerminalError(err, fmt.Sprintf("failed to get subnet %s", ip.Subnet))
+			}
+			fixedIPs = append(fixedIPs, osports.IP{SubnetID: subnet.ID, IPAddress: ip.IPAddress})
+		}
+		opts.FixedIPs = fixedIPs
+	}
+
+	var createOpts osports.CreateOptsBuilder
+	if c.DisablePortSecurity {
+		// ports without port security must not have security groups
+		opts.SecurityGroups = &[]string{}
+		portSecurityEnabled := false
+		createOpts = osportsecurity.PortCreateOptsExt{CreateOptsBuilder: opts, PortSecurityEnabled: &portSecurityEnabled}
+	} else {
+		opts.SecurityGroups = &securityGroupIDs
+		createOpts = opts
+	}
+
+	glog.V(2).Infof("Creating port %s in network %s", name, network.Name)
+	port, err := osports.Create(netClient, createOpts).Extract()
+	if err != nil {
+		return nil, osErrorToTerminalError(err, fmt.Sprintf("failed to create port %s", name))
+	}
+	return port, nil
+}
+
+// deletePorts deletes the additional ports of the machine, they remain after the instance got deleted.
+// It gets called without an instance as well, to clean up the ports of failed creations.
+func deletePorts(client *gophercloud.ProviderClient, region string, machineUID types.UID) error {
+	netClient, err := goopenstack.NewNetworkV2(client, gophercloud.EndpointOpts{Region: region})
+	if err != nil {
+		return osErrorToTerminalError(err, "failed to get network client")
+	}
+
+	ports, err := getPortsByMachineUID(netClient, machineUID)
+	if err != nil {
+		return osErrorToTerminalError(err, "failed to list ports")
+	}
+
+	for _, p := range ports {
+		glog.V(2).Infof("Deleting port %s of machine %s", p.Name, machineUID)
+		if err := osports.Delete(netClient, p.ID).ExtractErr(); err != nil {
+			if _, ok := err.(gophercloud.ErrDefault404); ok {
+				continue
+			}
+			return osErrorToTerminalError(err, fmt.Sprintf("failed to delete port %s", p.Name))
+		}
+	}
+	return nil
+}
+
+// migratePorts tags the additional ports of the machine with its new UID
+func migratePorts(client *gophercloud.ProviderClient, region string, machineUID, newUID types.UID) error {
+	netClient, err := goopenstack.NewNetworkV2(client, gophercloud.EndpointOpts{Region: region})
+	if err != nil {
+		return osErrorToTerminalError(err, "failed to get network client")
+	}
+
+	ports, err := getPortsByMachineUID(netClient, machineUID)
+	if err != nil {
+		return osErrorToTerminalError(err, "failed to list ports")
+	}
+
+	for _, p := range ports {
+		tags := sets.NewString(p.Tags...)
+		tags.Delete(getPortTag(machineUID))
+		tags.Insert(getPortTag(newUID))
+		if _, err := osattributestags.ReplaceAll(netClient, "ports", p.ID, osattributestags.ReplaceAllOpts{Tags: tags.List()}).Extract(); err != nil {
+			return fmt.Errorf("failed to update port %s with new UID: %v", p.Name, err)
+		}
+	}
+	return nil
+}
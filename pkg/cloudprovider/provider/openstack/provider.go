@@ -3,6 +3,7 @@ package openstack
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -11,9 +12,13 @@ import (
 
 	"github.com/gophercloud/gophercloud"
 	goopenstack "github.com/gophercloud/gophercloud/openstack"
+	osvolumes "github.com/gophercloud/gophercloud/openstack/blockstorage/v2/volumes"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/bootfromvolume"
 	osextendedstatus "github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/extendedstatus"
 	"github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/keypairs"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/schedulerhints"
 	osservers "github.com/gophercloud/gophercloud/openstack/compute/v2/servers"
+	osports "github.com/gophercloud/gophercloud/openstack/networking/v2/ports"
 	"github.com/gophercloud/gophercloud/pagination"
 
 	"github.com/kubermatic/machine-controller/pkg/cloudprovider/cloud"
@@ -24,6 +29,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
 
 	"sigs.k8s.io/cluster-api/pkg/apis/cluster/common"
@@ -48,6 +54,14 @@ type RawConfig struct {
 	TenantName       providerconfig.ConfigVarString `json:"tenantName"`
 	TokenID          providerconfig.ConfigVarString `json:"tokenId"`
 
+	// ApplicationCredentialID and ApplicationCredentialSecret authenticate instead of the username and password
+	ApplicationCredentialID     providerconfig.ConfigVarString `json:"applicationCredentialID,omitempty"`
+	ApplicationCredentialSecret providerconfig.ConfigVarString `json:"applicationCredentialSecret,omitempty"`
+	// EndpointType is the interface of the service endpoints, one of public, internal or admin. Defaults to public
+	EndpointType providerconfig.ConfigVarString `json:"endpointType,omitempty"`
+	// CACert is the PEM encoded CA bundle the endpoints get verified with
+	CACert providerconfig.ConfigVarString `json:"caCert,omitempty"`
+
 	// Machine details
 	Image            providerconfig.ConfigVarString   `json:"image"`
 	Flavor           providerconfig.ConfigVarString   `json:"flavor"`
@@ -58,6 +72,21 @@ type RawConfig struct {
 	AvailabilityZone providerconfig.ConfigVarString   `json:"availabilityZone"`
 	Region           providerconfig.ConfigVarString   `json:"region"`
 	Tags             map[string]string                `json:"tags"`
+
+	// FloatingIPReusePolicy is one of Any or Never, defaults to Any
+	FloatingIPReusePolicy providerconfig.ConfigVarString `json:"floatingIpReusePolicy,omitempty"`
+
+	// RootVolume boots the instance from a volume, which gets created from the image
+	RootVolume *RootVolumeRawConfig `json:"rootVolume,omitempty"`
+
+	// ServerGroup is the name or id of an existing server group the instances get scheduled into
+	ServerGroup providerconfig.ConfigVarString `json:"serverGroup,omitempty"`
+	// ServerGroupPolicy schedules the instances of a MachineDeployment into a server group with the policy,
+	// which gets managed by the machine-controller. One of anti-affinity or soft-anti-affinity.
+	ServerGroupPolicy providerconfig.ConfigVarString `json:"serverGroupPolicy,omitempty"`
+
+	// Ports are attached to the instance in addition to the network
+	Ports []PortRawConfig `json:"ports,omitempty"`
 }
 
 type Config struct {
@@ -68,6 +97,11 @@ type Config struct {
 	TenantName       string
 	TokenID          string
 
+	ApplicationCredentialID     string
+	ApplicationCredentialSecret string
+	EndpointType                string
+	CACert                      string
+
 	// Machine details
 	Image            string
 	Flavor           string
@@ -79,6 +113,15 @@ type Config struct {
 	Region           string
 
 	Tags map[string]string
+
+	FloatingIPReusePolicy string
+
+	RootVolume *RootVolumeConfig
+
+	ServerGroup       string
+	ServerGroupPolicy string
+
+	Ports []PortConfig
 }
 
 const (
@@ -129,6 +172,25 @@ func (p *provider) getConfig(s v1alpha1.ProviderConfig) (*Config, *providerconfi
 	if err != nil {
 		return nil, nil, nil, err
 	}
+	c.ApplicationCredentialID, err = p.configVarResolver.GetConfigVarStringValueOrEnv(rawConfig.ApplicationCredentialID, "OS_APPLICATION_CREDENTIAL_ID")
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to get the value of \"applicationCredentialID\" field, error = %v", err)
+	}
+	c.ApplicationCredentialSecret, err = p.configVarResolver.GetConfigVarStringValueOrEnv(rawConfig.ApplicationCredentialSecret, "OS_APPLICATION_CREDENTIAL_SECRET")
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to get the value of \"applicationCredentialSecret\" field, error = %v", err)
+	}
+	c.EndpointType, err = p.configVarResolver.GetConfigVarStringValue(rawConfig.EndpointType)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if c.EndpointType == "" {
+		c.EndpointType = string(gophercloud.AvailabilityPublic)
+	}
+	c.CACert, err = p.configVarResolver.GetConfigVarStringValue(rawConfig.CACert)
+	if err != nil {
+		return nil, nil, nil, err
+	}
 	c.Image, err = p.configVarResolver.GetConfigVarStringValue(rawConfig.Image)
 	if err != nil {
 		return nil, nil, nil, err
@@ -156,6 +218,13 @@ func (p *provider) getConfig(s v1alpha1.ProviderConfig) (*Config, *providerconfi
 	if err != nil {
 		return nil, nil, nil, err
 	}
+	c.FloatingIPReusePolicy, err = p.configVarResolver.GetConfigVarStringValue(rawConfig.FloatingIPReusePolicy)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if c.FloatingIPReusePolicy == "" {
+		c.FloatingIPReusePolicy = floatingIPReusePolicyAny
+	}
 	c.AvailabilityZone, err = p.configVarResolver.GetConfigVarStringValue(rawConfig.AvailabilityZone)
 	if err != nil {
 		return nil, nil, nil, err
@@ -168,6 +237,25 @@ func (p *provider) getConfig(s v1alpha1.ProviderConfig) (*Config, *providerconfi
 	if c.Tags == nil {
 		c.Tags = map[string]string{}
 	}
+	c.RootVolume, err = p.getRootVolumeConfig(rawConfig.RootVolume)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	c.ServerGroup, err = p.configVarResolver.GetConfigVarStringValue(rawConfig.ServerGroup)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	c.ServerGroupPolicy, err = p.configVarResolver.GetConfigVarStringValue(rawConfig.ServerGroupPolicy)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	for _, rawPort := range rawConfig.Ports {
+		port, err := p.getPortConfig(rawPort)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		c.Ports = append(c.Ports, *port)
+	}
 
 	return &c, &pconfig, &rawConfig, err
 }
@@ -194,19 +282,6 @@ func setProviderConfig(rawConfig RawConfig, s v1alpha1.ProviderConfig) (*runtime
 	return &runtime.RawExtension{Raw: rawPconfig}, nil
 }
 
-func getClient(c *Config) (*gophercloud.ProviderClient, error) {
-	opts := gophercloud.AuthOptions{
-		IdentityEndpoint: c.IdentityEndpoint,
-		Username:         c.Username,
-		Password:         c.Password,
-		DomainName:       c.DomainName,
-		TenantName:       c.TenantName,
-		TokenID:          c.TokenID,
-	}
-
-	return goopenstack.AuthenticatedClient(opts)
-}
-
 func (p *provider) AddDefaults(spec v1alpha1.MachineSpec) (v1alpha1.MachineSpec, bool, error) {
 	var changed bool
 
@@ -299,6 +374,10 @@ func (p *provider) Validate(spec v1alpha1.MachineSpec) error {
 		return fmt.Errorf("failed to parse config: %v", err)
 	}
 
+	if err := validateCredentials(c, spec.Versions.Kubelet); err != nil {
+		return err
+	}
+
 	client, err := getClient(c)
 	if err != nil {
 		return fmt.Errorf("failed to get a openstack client: %v", err)
@@ -309,7 +388,8 @@ func (p *provider) Validate(spec v1alpha1.MachineSpec) error {
 		return fmt.Errorf("failed to get region %q: %v", c.Region, err)
 	}
 
-	if _, err := getImageByName(client, c.Region, c.Image); err != nil {
+	image, err := getImageByName(client, c.Region, c.Image)
+	if err != nil {
 		return fmt.Errorf("failed to get image %q: %v", c.Image, err)
 	}
 
@@ -331,6 +411,10 @@ func (p *provider) Validate(spec v1alpha1.MachineSpec) error {
 		}
 	}
 
+	if !floatingIPReusePolicies.Has(c.FloatingIPReusePolicy) {
+		return fmt.Errorf("invalid floating ip reuse policy %q specified. Supported: %s", c.FloatingIPReusePolicy, floatingIPReusePolicies.List())
+	}
+
 	if _, err := getAvailabilityZone(client, c.Region, c.AvailabilityZone); err != nil {
 		return fmt.Errorf("failed to get availability zone %q: %v", c.AvailabilityZone, err)
 	}
@@ -344,6 +428,32 @@ func (p *provider) Validate(spec v1alpha1.MachineSpec) error {
 		}
 	}
 
+	if c.RootVolume != nil {
+		if err := validateRootVolume(c.RootVolume, image); err != nil {
+			return err
+		}
+		if _, err := getBlockStorageClient(client, c.Region); err != nil {
+			return fmt.Errorf("failed to get block storage client: %v", err)
+		}
+	}
+
+	if err := validatePorts(client, c); err != nil {
+		return err
+	}
+
+	if err := validateServerGroupConfig(c); err != nil {
+		return err
+	}
+	if c.ServerGroup != "" {
+		computeClient, err := goopenstack.NewComputeV2(client, gophercloud.EndpointOpts{Region: c.Region})
+		if err != nil {
+			return fmt.Errorf("failed to get compute client: %v", err)
+		}
+		if _, err := getServerGroup(computeClient, c.ServerGroup); err != nil {
+			return fmt.Errorf("failed to get server group %q: %v", c.ServerGroup, err)
+		}
+	}
+
 	// validate reserved tags
 	if _, ok := c.Tags[machineUIDMetaKey]; ok {
 		return fmt.Errorf("the tag with the given name =%s is reserved, choose a different one", machineUIDMetaKey)
@@ -395,6 +505,11 @@ func (p *provider) Create(machine *v1alpha1.Machine, _ cloud.MachineUpdater, use
 	allTags := c.Tags
 	allTags[machineUIDMetaKey] = string(machine.UID)
 
+	portNetworks, err := ensurePorts(client, c, machine, securityGroups)
+	if err != nil {
+		return nil, err
+	}
+
 	serverOpts := osservers.CreateOpts{
 		Name:             machine.Spec.Name,
 		FlavorRef:        flavor.ID,
@@ -402,19 +517,45 @@ func (p *provider) Create(machine *v1alpha1.Machine, _ cloud.MachineUpdater, use
 		UserData:         []byte(userdata),
 		SecurityGroups:   securityGroups,
 		AvailabilityZone: c.AvailabilityZone,
-		Networks:         []osservers.Network{{UUID: network.ID}},
+		Networks:         append([]osservers.Network{{UUID: network.ID}}, portNetworks...),
 		Metadata:         allTags,
 	}
-	computeClient, err := goopenstack.NewComputeV2(client, gophercloud.EndpointOpts{Availability: gophercloud.AvailabilityPublic, Region: c.Region})
+	var blockDevices []bootfromvolume.BlockDevice
+	if c.RootVolume != nil {
+		rootVolume, err := ensureRootVolume(client, c, machine, image)
+		if err != nil {
+			return nil, err
+		}
+		// the instance boots from the volume instead of the image
+		serverOpts.ImageRef = ""
+		blockDevices = append(blockDevices, getRootBlockDevice(c.RootVolume, rootVolume))
+	}
+	var createOpts osservers.CreateOptsBuilder = keypairs.CreateOptsExt{
+		CreateOptsBuilder: serverOpts,
+		KeyName:           "",
+	}
+	if len(blockDevices) > 0 {
+		createOpts = bootfromvolume.CreateOptsExt{CreateOptsBuilder: createOpts, BlockDevice: blockDevices}
+	}
+
+	computeClient, err := goopenstack.NewComputeV2(client, gophercloud.EndpointOpts{Region: c.Region})
 	if err != nil {
 		return nil, osErrorToTerminalError(err, "failed to get compute client")
 	}
 
+	serverGroupID, err := ensureServerGroup(computeClient, c, machine)
+	if err != nil {
+		return nil, err
+	}
+	if serverGroupID != "" {
+		createOpts = schedulerhints.CreateOptsExt{
+			CreateOptsBuilder: createOpts,
+			SchedulerHints:    schedulerhints.SchedulerHints{Group: serverGroupID},
+		}
+	}
+
 	var server serverWithExt
-	err = osservers.Create(computeClient, keypairs.CreateOptsExt{
-		CreateOptsBuilder: serverOpts,
-		KeyName:           "",
-	}).ExtractInto(&server)
+	err = osservers.Create(computeClient, createOpts).ExtractInto(&server)
 	if err != nil {
 		return nil, osErrorToTerminalError(err, "failed to create server")
 	}
@@ -426,7 +567,7 @@ func (p *provider) Create(machine *v1alpha1.Machine, _ cloud.MachineUpdater, use
 
 	// Find a free FloatingIP or allocate a new one
 	if c.FloatingIPPool != "" {
-		if err := assignFloatingIPToInstance(client, server.ID, c.FloatingIPPool, c.Region, network); err != nil {
+		if err := assignFloatingIPToInstance(client, machine.UID, server.ID, c.FloatingIPPool, c.FloatingIPReusePolicy, c.Region, network); err != nil {
 			defer deleteInstanceDueToFatalLogged(computeClient, server.ID)
 			return nil, fmt.Errorf("failed to assign a floating ip to instance %s: %v", server.ID, err)
 		}
@@ -479,11 +620,10 @@ func deleteInstanceDueToFatalLogged(computeClient *gophercloud.ServiceClient, se
 }
 
 func (p *provider) Delete(machine *v1alpha1.Machine, _ cloud.MachineUpdater) error {
+	// the resources tagged with the UID of the machine get cleaned up as well when the instance is already gone,
+	// e.g. when the deletion failed after the instance got deleted or its creation failed halfway
 	instance, err := p.Get(machine)
-	if err != nil {
-		if err == cloudprovidererrors.ErrInstanceNotFound {
-			return nil
-		}
+	if err != nil && err != cloudprovidererrors.ErrInstanceNotFound {
 		return err
 	}
 
@@ -500,16 +640,46 @@ func (p *provider) Delete(machine *v1alpha1.Machine, _ cloud.MachineUpdater) err
 		return osErrorToTerminalError(err, "failed to get a openstack client")
 	}
 
-	computeClient, err := goopenstack.NewComputeV2(client, gophercloud.EndpointOpts{Availability: gophercloud.AvailabilityPublic, Region: c.Region})
+	computeClient, err := goopenstack.NewComputeV2(client, gophercloud.EndpointOpts{Region: c.Region})
 	if err != nil {
 		return osErrorToTerminalError(err, "failed to get compute client")
 	}
 
-	if err := osservers.Delete(computeClient, instance.ID()).ExtractErr(); err != nil {
-		return osErrorToTerminalError(err, "failed to delete instance")
+	if instance != nil {
+		if err := osservers.Delete(computeClient, instance.ID()).ExtractErr(); err != nil {
+			return osErrorToTerminalError(err, "failed to delete instance")
+		}
 	}
 
-	return nil
+	if c.FloatingIPPool != "" {
+		if err := releaseFloatingIPs(client, c.Region, machine.UID); err != nil {
+			return err
+		}
+	}
+
+	if c.RootVolume == nil && c.ServerGroupPolicy == "" && len(c.Ports) == 0 {
+		return nil
+	}
+
+	if instance != nil {
+		if err := waitUntilInstanceIsDeleted(computeClient, instance.ID()); err != nil {
+			return err
+		}
+	}
+
+	if c.RootVolume != nil {
+		if err := deleteRootVolume(client, c, machine.UID); err != nil {
+			return err
+		}
+	}
+
+	if len(c.Ports) > 0 {
+		if err := deletePorts(client, c.Region, machine.UID); err != nil {
+			return err
+		}
+	}
+
+	return deleteServerGroupIfEmpty(computeClient, c, machine)
 }
 
 func (p *provider) Get(machine *v1alpha1.Machine) (instance.Instance, error) {
@@ -526,7 +696,7 @@ func (p *provider) Get(machine *v1alpha1.Machine) (instance.Instance, error) {
 		return nil, osErrorToTerminalError(err, "failed to get a openstack client")
 	}
 
-	computeClient, err := goopenstack.NewComputeV2(client, gophercloud.EndpointOpts{Availability: gophercloud.AvailabilityPublic, Region: c.Region})
+	computeClient, err := goopenstack.NewComputeV2(client, gophercloud.EndpointOpts{Region: c.Region})
 	if err != nil {
 		return nil, osErrorToTerminalError(err, "failed to get compute client")
 	}
@@ -548,7 +718,17 @@ func (p *provider) Get(machine *v1alpha1.Machine) (instance.Instance, error) {
 
 	for i, s := range allServers {
 		if s.Metadata[machineUIDMetaKey] == string(machine.UID) {
-			return &osInstance{server: &allServers[i]}, nil
+			inst := &osInstance{server: &allServers[i]}
+			if len(c.Ports) > 0 {
+				netClient, err := goopenstack.NewNetworkV2(client, gophercloud.EndpointOpts{Region: c.Region})
+				if err != nil {
+					return nil, osErrorToTerminalError(err, "failed to get network client")
+				}
+				if inst.ports, err = getPortsByMachineUID(netClient, machine.UID); err != nil {
+					return nil, osErrorToTerminalError(err, "failed to list ports")
+				}
+			}
+			return inst, nil
 		}
 	}
 
@@ -569,7 +749,7 @@ func (p *provider) MigrateUID(machine *v1alpha1.Machine, new types.UID) error {
 		return osErrorToTerminalError(err, "failed to get a openstack client")
 	}
 
-	computeClient, err := goopenstack.NewComputeV2(client, gophercloud.EndpointOpts{Availability: gophercloud.AvailabilityPublic, Region: c.Region})
+	computeClient, err := goopenstack.NewComputeV2(client, gophercloud.EndpointOpts{Region: c.Region})
 	if err != nil {
 		return osErrorToTerminalError(err, "failed to get compute client")
 	}
@@ -600,6 +780,36 @@ func (p *provider) MigrateUID(machine *v1alpha1.Machine, new types.UID) error {
 		}
 	}
 
+	if c.FloatingIPPool != "" {
+		if err := migrateFloatingIPs(client, c.Region, machine.UID, new); err != nil {
+			return err
+		}
+	}
+
+	if len(c.Ports) > 0 {
+		if err := migratePorts(client, c.Region, machine.UID, new); err != nil {
+			return err
+		}
+	}
+
+	if c.RootVolume != nil {
+		blockStorageClient, err := getBlockStorageClient(client, c.Region)
+		if err != nil {
+			return osErrorToTerminalError(err, "failed to get block storage client")
+		}
+		volumes, err := getVolumesByMachineUID(blockStorageClient, machine.UID)
+		if err != nil {
+			return osErrorToTerminalError(err, "failed to list volumes")
+		}
+		for _, v := range volumes {
+			metadata := v.Metadata
+			metadata[machineUIDMetaKey] = string(new)
+			if _, err := osvolumes.Update(blockStorageClient, v.ID, osvolumes.UpdateOpts{Metadata: metadata}).Extract(); err != nil {
+				return fmt.Errorf("failed to update volume metadata with new UID: %v", err)
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -611,12 +821,9 @@ func (p *provider) GetCloudConfig(spec v1alpha1.MachineSpec) (config string, nam
 
 	cc := &CloudConfig{
 		Global: GlobalOpts{
-			AuthURL:    c.IdentityEndpoint,
-			Username:   c.Username,
-			Password:   c.Password,
-			DomainName: c.DomainName,
-			TenantName: c.TenantName,
-			Region:     c.Region,
+			AuthURL:      c.IdentityEndpoint,
+			Region:       c.Region,
+			EndpointType: c.EndpointType,
 		},
 		LoadBalancer: LoadBalancerOpts{
 			ManageSecurityGroups: true,
@@ -628,6 +835,19 @@ func (p *provider) GetCloudConfig(spec v1alpha1.MachineSpec) (config string, nam
 		},
 	}
 
+	if c.ApplicationCredentialID != "" {
+		cc.Global.ApplicationCredentialID = c.ApplicationCredentialID
+		cc.Global.ApplicationCredentialSecret = c.ApplicationCredentialSecret
+	} else {
+		cc.Global.Username = c.Username
+		cc.Global.Password = c.Password
+		cc.Global.DomainName = c.DomainName
+		cc.Global.TenantName = c.TenantName
+	}
+	if c.CACert != "" {
+		cc.Global.CAFile = caBundlePath
+	}
+
 	s, err := CloudConfigToString(cc)
 	if err != nil {
 		return "", "", fmt.Errorf("failed to convert the cloud-config to string: %v", err)
@@ -643,6 +863,10 @@ func (p *provider) MachineMetricsLabels(machine *v1alpha1.Machine) (map[string]s
 		labels["size"] = c.Flavor
 		labels["image"] = c.Image
 		labels["region"] = c.Region
+		if c.RootVolume != nil {
+			labels["rootVolumeSize"] = strconv.Itoa(c.RootVolume.Size)
+			labels["rootVolumeType"] = c.RootVolume.VolumeType
+		}
 	}
 
 	return labels, err
@@ -655,6 +879,8 @@ type serverWithExt struct {
 
 type osInstance struct {
 	server *serverWithExt
+	// ports are the additional ports of the instance
+	ports []osports.Port
 }
 
 func (d *osInstance) Name() string {
@@ -674,6 +900,17 @@ func (d *osInstance) Addresses() []string {
 		}
 	}
 
+	// The addresses of the instance might not contain the ones of the ports yet
+	known := sets.NewString(addresses...)
+	for _, p := range d.ports {
+		for _, ip := range p.FixedIPs {
+			if !known.Has(ip.IPAddress) {
+				known.Insert(ip.IPAddress)
+				addresses = append(addresses, ip.IPAddress)
+			}
+		}
+	}
+
 	return addresses
 }
 
@@ -0,0 +1,157 @@
+package openstack
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/golang/glog"
+
+	"github.com/gophercloud/gophercloud"
+	osservergroups "github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/servergroups"
+
+	kuberneteshelper "github.com/kubermatic/machine-controller/pkg/kubernetes"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+)
+
+const (
+	serverGroupPolicyAntiAffinity     = "anti-affinity"
+	serverGroupPolicySoftAntiAffinity = "soft-anti-affinity"
+
+	// serverGroupSoftPoliciesMicroversion is the first compute API microversion which supports the soft policies
+	serverGroupSoftPoliciesMicroversion = "2.15"
+)
+
+var (
+	serverGroupPolicies = sets.NewString(serverGroupPolicyAntiAffinity, serverGroupPolicySoftAntiAffinity)
+
+	// Protects the creation of the server groups managed per MachineDeployment
+	serverGroupCreationLock = sync.Mutex{}
+)
+
+// getServerGroupName returns the name of the server group managed for the MachineDeployment of the machine.
+// Machines without a MachineDeployment get their own group.
+func getServerGroupName(c *Config, machine *v1alpha1.Machine) string {
+	return kuberneteshelper.MachineDeploymentResourceName(kuberneteshelper.ClusterID(c.Tags), machine, "server-group")
+}
+
+func validateServerGroupConfig(c *Config) error {
+	if c.ServerGroup != "" && c.ServerGroupPolicy != "" {
+		return fmt.Errorf("serverGroup and serverGroupPolicy can't be combined")
+	}
+	if c.ServerGroupPolicy != "" && !serverGroupPolicies.Has(c.ServerGroupPolicy) {
+		return fmt.Errorf("invalid server group policy %q specified. Supported: %s", c.ServerGroupPolicy, serverGroupPolicies.List())
+	}
+	return nil
+}
+
+func getServerGroups(computeClient *gophercloud.ServiceClient) ([]osservergroups.ServerGroup, error) {
+	allPages, err := osservergroups.List(computeClient).AllPages()
+	if err != nil {
+		return nil, err
+	}
+	return osservergroups.ExtractServerGroups(allPages)
+}
+
+// getServerGroup returns the server group with the given name or id
+func getServerGroup(computeClient *gophercloud.ServiceClient, nameOrID string) (*osservergroups.ServerGroup, error) {
+	groups, err := getServerGroups(computeClient)
+	if err != nil {
+		return nil, err
+	}
+
+	var matchingGroups []osservergroups.ServerGroup
+	for _, g := range groups {
+		if g.ID == nameOrID {
+			return &g, nil
+		}
+		if g.Name == nameOrID {
+			matchingGroups = append(matchingGroups, g)
+		}
+	}
+	if len(matchingGroups) > 1 {
+		return nil, fmt.Errorf("found %d server groups with the name %q, use the id instead", len(matchingGroups), nameOrID)
+	}
+	if len(matchingGroups) == 0 {
+		return nil, errNotFound
+	}
+	return &matchingGroups[0], nil
+}
+
+// ensureServerGroup returns the id of the server group the instance gets scheduled into.
+// The group managed for the MachineDeployment gets created if it doesn't exist yet.
+func ensureServerGroup(computeClient *gophercloud.ServiceClient, c *Config, machine *v1alpha1.Machine) (string, error) {
+	if c.ServerGroup != "" {
+		group, err := getServerGroup(computeClient, c.ServerGroup)
+		if err != nil {
+			return "", osErrorToTerminalError(err, fmt.Sprintf("failed to get server group %s", c.ServerGroup))
+		}
+		return group.ID, nil
+	}
+	if c.ServerGroupPolicy == "" {
+		return "", nil
+	}
+
+	// We need a mutex here because otherwise if more than one machine of a MachineDeployment gets created
+	// at roughly the same time we will create two server groups, which are only identified by their name
+	serverGroupCreationLock.Lock()
+	defer serverGroupCreationLock.Unlock()
+
+	name := getServerGroupName(c, machine)
+	group, err := getServerGroup(computeClient, name)
+	if err == nil {
+		return group.ID, nil
+	}
+	if err != errNotFound {
+		return "", osErrorToTerminalError(err, fmt.Sprintf("failed to get server group %s", name))
+	}
+
+	glog.V(2).Infof("Creating server group %s with policy %s", name, c.ServerGroupPolicy)
+	groupClient := *computeClient
+	if c.ServerGroupPolicy == serverGroupPolicySoftAntiAffinity {
+		groupClient.Microversion = serverGroupSoftPoliciesMicroversion
+	}
+	group, err = osservergroups.Create(&groupClient, osservergroups.CreateOpts{
+		Name:     name,
+		Policies: []string{c.ServerGroupPolicy},
+	}).Extract()
+	if err != nil {
+		return "", osErrorToTerminalError(err, fmt.Sprintf("failed to create server group %s", name))
+	}
+	return group.ID, nil
+}
+
+// deleteServerGroupIfEmpty removes the server group managed for the MachineDeployment of the machine,
+// once no instances are members of it anymore
+func deleteServerGroupIfEmpty(computeClient *gophercloud.ServiceClient, c *Config, machine *v1alpha1.Machine) error {
+	if c.ServerGroupPolicy == "" {
+		return nil
+	}
+
+	serverGroupCreationLock.Lock()
+	defer serverGroupCreationLock.Unlock()
+
+	name := getServerGroupName(c, machine)
+	group, err := getServerGroup(computeClient, name)
+	if err != nil {
+		if err == errNotFound {
+			return nil
+		}
+		return osErrorToTerminalError(err, fmt.Sprintf("failed to get server group %s", name))
+	}
+	if len(group.Members) > 0 {
+		glog.V(2).Infof("Not deleting server group %s, it still has %d members", name, len(group.Members))
+		return nil
+	}
+
+	glog.V(2).Infof("Deleting server group %s", name)
+	if err := osservergroups.Delete(computeClient, group.ID).ExtractErr(); err != nil {
+		if _, ok := err.(gophercloud.ErrDefault404); ok {
+			return nil
+		}
+		return osErrorToTerminalError(err, fmt.Sprintf("failed to delete server group %s", name))
+	}
+	return nil
+}
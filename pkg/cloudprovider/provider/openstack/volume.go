@@ -0,0 +1,219 @@
+package openstack
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+
+	"github.com/gophercloud/gophercloud"
+	goopenstack "github.com/gophercloud/gophercloud/openstack"
+	osvolumes "github.com/gophercloud/gophercloud/openstack/blockstorage/v2/volumes"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/bootfromvolume"
+	osimages "github.com/gophercloud/gophercloud/openstack/compute/v2/images"
+	osservers "github.com/gophercloud/gophercloud/openstack/compute/v2/servers"
+	"github.com/gophercloud/gophercloud/pagination"
+
+	"github.com/kubermatic/machine-controller/pkg/providerconfig"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+)
+
+const (
+	volumeStatusAvailable = "available"
+	volumeStatusError     = "error"
+
+	volumeReadyCheckTimeout    = 5 * time.Minute
+	volumeDeleteCheckPeriod    = 2 * time.Second
+	volumeDeleteCheckTimeout   = 2 * time.Minute
+	instanceDeleteCheckPeriod  = 2 * time.Second
+	instanceDeleteCheckTimeout = 2 * time.Minute
+)
+
+// RootVolumeRawConfig describes the volume the instance boots from
+type RootVolumeRawConfig struct {
+	// Size in GB
+	Size       int                            `json:"size"`
+	VolumeType providerconfig.ConfigVarString `json:"volumeType,omitempty"`
+	// AvailabilityZone of the volume, defaults to the default availability zone of the block storage service
+	AvailabilityZone providerconfig.ConfigVarString `json:"availabilityZone,omitempty"`
+	// DeleteOnTermination deletes the volume together with the instance, defaults to true
+	DeleteOnTermination *bool `json:"deleteOnTermination,omitempty"`
+}
+
+type RootVolumeConfig struct {
+	Size                int
+	VolumeType          string
+	AvailabilityZone    string
+	DeleteOnTermination bool
+}
+
+func (p *provider) getRootVolumeConfig(raw *RootVolumeRawConfig) (*RootVolumeConfig, error) {
+	if raw == nil {
+		return nil, nil
+	}
+
+	var err error
+	c := RootVolumeConfig{Size: raw.Size, DeleteOnTermination: true}
+	c.VolumeType, err = p.configVarResolver.GetConfigVarStringValue(raw.VolumeType)
+	if err != nil {
+		return nil, err
+	}
+	c.AvailabilityZone, err = p.configVarResolver.GetConfigVarStringValue(raw.AvailabilityZone)
+	if err != nil {
+		return nil, err
+	}
+	if raw.DeleteOnTermination != nil {
+		c.DeleteOnTermination = *raw.DeleteOnTermination
+	}
+	return &c, nil
+}
+
+func validateRootVolume(c *RootVolumeConfig, image *osimages.Image) error {
+	if c.Size <= 0 {
+		return fmt.Errorf("size of the root volume must be greater than 0")
+	}
+	if c.Size < image.MinDisk {
+		return fmt.Errorf("size of the root volume must be at least %d GB, the minimum disk size of image %q", image.MinDisk, image.Name)
+	}
+	return nil
+}
+
+func getBlockStorageClient(client *gophercloud.ProviderClient, region string) (*gophercloud.ServiceClient, error) {
+	return goopenstack.NewBlockStorageV2(client, gophercloud.EndpointOpts{Region: region})
+}
+
+func getVolumesByMachineUID(blockStorageClient *gophercloud.ServiceClient, machineUID types.UID) ([]osvolumes.Volume, error) {
+	var matchingVolumes []osvolumes.Volume
+	pager := osvolumes.List(blockStorageClient, osvolumes.ListOpts{Metadata: map[string]string{machineUIDMetaKey: string(machineUID)}})
+	err := pager.EachPage(func(page pagination.Page) (bool, error) {
+		volumes, err := osvolumes.ExtractVolumes(page)
+		if err != nil {
+			return false, err
+		}
+		// not every block storage service supports filtering by metadata
+		for _, v := range volumes {
+			if v.Metadata[machineUIDMetaKey] == string(machineUID) {
+				matchingVolumes = append(matchingVolumes, v)
+			}
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return matchingVolumes, nil
+}
+
+// ensureRootVolume creates the root volume from the image and waits until it is available.
+// The volume is tagged with the machine's UID, so a volume of a previous attempt gets reused.
+func ensureRootVolume(client *gophercloud.ProviderClient, c *Config, machine *v1alpha1.Machine, image *osimages.Image) (*osvolumes.Volume, error) {
+	blockStorageClient, err := getBlockStorageClient(client, c.Region)
+	if err != nil {
+		return nil, osErrorToTerminalError(err, "failed to get block storage client")
+	}
+
+	volumes, err := getVolumesByMachineUID(blockStorageClient, machine.UID)
+	if err != nil {
+		return nil, osErrorToTerminalError(err, "failed to list volumes")
+	}
+
+	var volume *osvolumes.Volume
+	for i, v := range volumes {
+		if v.Status == volumeStatusError {
+			continue
+		}
+		volume = &volumes[i]
+		break
+	}
+	if volume == nil {
+		glog.V(2).Infof("Creating root volume for instance %s", machine.Spec.Name)
+		volume, err = osvolumes.Create(blockStorageClient, osvolumes.CreateOpts{
+			Name:             machine.Spec.Name + "-root",
+			Size:             c.RootVolume.Size,
+			VolumeType:       c.RootVolume.VolumeType,
+			AvailabilityZone: c.RootVolume.AvailabilityZone,
+			ImageID:          image.ID,
+			Metadata:         map[string]string{machineUIDMetaKey: string(machine.UID)},
+		}).Extract()
+		if err != nil {
+			return nil, osErrorToTerminalError(err, "failed to create root volume")
+		}
+	}
+
+	if err := osvolumes.WaitForStatus(blockStorageClient, volume.ID, volumeStatusAvailable, int(volumeReadyCheckTimeout.Seconds())); err != nil {
+		return nil, fmt.Errorf("root volume %s became not available: %v", volume.ID, err)
+	}
+	return volume, nil
+}
+
+func getRootBlockDevice(c *RootVolumeConfig, volume *osvolumes.Volume) bootfromvolume.BlockDevice {
+	return bootfromvolume.BlockDevice{
+		SourceType:          bootfromvolume.SourceVolume,
+		DestinationType:     bootfromvolume.DestinationVolume,
+		UUID:                volume.ID,
+		BootIndex:           0,
+		DeleteOnTermination: c.DeleteOnTermination,
+	}
+}
+
+func waitUntilInstanceIsDeleted(computeClient *gophercloud.ServiceClient, serverID string) error {
+	glog.V(2).Infof("Waiting for the instance %s to get deleted...", serverID)
+
+	instanceIsDeleted := func() (bool, error) {
+		if _, err := osservers.Get(computeClient, serverID).Extract(); err != nil {
+			if _, ok := err.(gophercloud.ErrDefault404); ok {
+				return true, nil
+			}
+			// Only log the error but don't exit. in case of a network failure we want to retry
+			glog.V(2).Infof("failed to get current instance %s: %v", serverID, err)
+		}
+		return false, nil
+	}
+
+	if err := wait.Poll(instanceDeleteCheckPeriod, instanceDeleteCheckTimeout, instanceIsDeleted); err != nil {
+		return fmt.Errorf("instance %s didn't get deleted: %v", serverID, err)
+	}
+	return nil
+}
+
+// deleteRootVolume verifies that the root volume got deleted together with the instance.
+// Volumes which remain detached, e.g. because the instance failed to boot, get deleted.
+func deleteRootVolume(client *gophercloud.ProviderClient, c *Config, machineUID types.UID) error {
+	if !c.RootVolume.DeleteOnTermination {
+		return nil
+	}
+
+	blockStorageClient, err := getBlockStorageClient(client, c.Region)
+	if err != nil {
+		return osErrorToTerminalError(err, "failed to get block storage client")
+	}
+
+	volumesAreDeleted := func() (bool, error) {
+		volumes, err := getVolumesByMachineUID(blockStorageClient, machineUID)
+		if err != nil {
+			glog.V(2).Infof("failed to list volumes of machine %s: %v", machineUID, err)
+			return false, nil
+		}
+		for _, v := range volumes {
+			if v.Status != volumeStatusAvailable && v.Status != volumeStatusError {
+				continue
+			}
+			glog.V(2).Infof("Deleting root volume %s", v.ID)
+			if err := osvolumes.Delete(blockStorageClient, v.ID, nil).ExtractErr(); err != nil {
+				if _, ok := err.(gophercloud.ErrDefault404); !ok {
+					glog.V(2).Infof("failed to delete volume %s: %v", v.ID, err)
+				}
+			}
+		}
+		return len(volumes) == 0, nil
+	}
+
+	if err := wait.Poll(volumeDeleteCheckPeriod, volumeDeleteCheckTimeout, volumesAreDeleted); err != nil {
+		return fmt.Errorf("root volume of machine %s didn't get deleted: %v", machineUID, err)
+	}
+	return nil
+}
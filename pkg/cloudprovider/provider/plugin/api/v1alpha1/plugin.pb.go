@@ -0,0 +1,1141 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: pkg/cloudprovider/provider/plugin/api/v1alpha1/plugin.proto
+
+package v1alpha1
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+
+import (
+	context "golang.org/x/net/context"
+	grpc "google.golang.org/grpc"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+type Instance struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Id                   string   `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	Addresses            []string `protobuf:"bytes,3,rep,name=addresses,proto3" json:"addresses,omitempty"`
+	Status               string   `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Instance) Reset()         { *m = Instance{} }
+func (m *Instance) String() string { return proto.CompactTextString(m) }
+func (*Instance) ProtoMessage()    {}
+func (*Instance) Descriptor() ([]byte, []int) {
+	return fileDescriptor_plugin_22b6773ea52b679e, []int{0}
+}
+func (m *Instance) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Instance.Unmarshal(m, b)
+}
+func (m *Instance) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Instance.Marshal(b, m, deterministic)
+}
+func (dst *Instance) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Instance.Merge(dst, src)
+}
+func (m *Instance) XXX_Size() int {
+	return xxx_messageInfo_Instance.Size(m)
+}
+func (m *Instance) XXX_DiscardUnknown() {
+	xxx_messageInfo_Instance.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Instance proto.InternalMessageInfo
+
+func (m *Instance) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Instance) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Instance) GetAddresses() []string {
+	if m != nil {
+		return m.Addresses
+	}
+	return nil
+}
+
+func (m *Instance) GetStatus() string {
+	if m != nil {
+		return m.Status
+	}
+	return ""
+}
+
+type TerminalError struct {
+	Reason               string   `protobuf:"bytes,1,opt,name=reason,proto3" json:"reason,omitempty"`
+	Message              string   `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TerminalError) Reset()         { *m = TerminalError{} }
+func (m *TerminalError) String() string { return proto.CompactTextString(m) }
+func (*TerminalError) ProtoMessage()    {}
+func (*TerminalError) Descriptor() ([]byte, []int) {
+	return fileDescriptor_plugin_22b6773ea52b679e, []int{1}
+}
+func (m *TerminalError) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TerminalError.Unmarshal(m, b)
+}
+func (m *TerminalError) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TerminalError.Marshal(b, m, deterministic)
+}
+func (dst *TerminalError) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TerminalError.Merge(dst, src)
+}
+func (m *TerminalError) XXX_Size() int {
+	return xxx_messageInfo_TerminalError.Size(m)
+}
+func (m *TerminalError) XXX_DiscardUnknown() {
+	xxx_messageInfo_TerminalError.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TerminalError proto.InternalMessageInfo
+
+func (m *TerminalError) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+func (m *TerminalError) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+type AddDefaultsRequest struct {
+	MachineSpec          []byte   `protobuf:"bytes,1,opt,name=machine_spec,json=machineSpec,proto3" json:"machine_spec,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *AddDefaultsRequest) Reset()         { *m = AddDefaultsRequest{} }
+func (m *AddDefaultsRequest) String() string { return proto.CompactTextString(m) }
+func (*AddDefaultsRequest) ProtoMessage()    {}
+func (*AddDefaultsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_plugin_22b6773ea52b679e, []int{2}
+}
+func (m *AddDefaultsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AddDefaultsRequest.Unmarshal(m, b)
+}
+func (m *AddDefaultsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AddDefaultsRequest.Marshal(b, m, deterministic)
+}
+func (dst *AddDefaultsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AddDefaultsRequest.Merge(dst, src)
+}
+func (m *AddDefaultsRequest) XXX_Size() int {
+	return xxx_messageInfo_AddDefaultsRequest.Size(m)
+}
+func (m *AddDefaultsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_AddDefaultsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AddDefaultsRequest proto.InternalMessageInfo
+
+func (m *AddDefaultsRequest) GetMachineSpec() []byte {
+	if m != nil {
+		return m.MachineSpec
+	}
+	return nil
+}
+
+type AddDefaultsResponse struct {
+	MachineSpec          []byte   `protobuf:"bytes,1,opt,name=machine_spec,json=machineSpec,proto3" json:"machine_spec,omitempty"`
+	Changed              bool     `protobuf:"varint,2,opt,name=changed,proto3" json:"changed,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *AddDefaultsResponse) Reset()         { *m = AddDefaultsResponse{} }
+func (m *AddDefaultsResponse) String() string { return proto.CompactTextString(m) }
+func (*AddDefaultsResponse) ProtoMessage()    {}
+func (*AddDefaultsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_plugin_22b6773ea52b679e, []int{3}
+}
+func (m *AddDefaultsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AddDefaultsResponse.Unmarshal(m, b)
+}
+func (m *AddDefaultsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AddDefaultsResponse.Marshal(b, m, deterministic)
+}
+func (dst *AddDefaultsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AddDefaultsResponse.Merge(dst, src)
+}
+func (m *AddDefaultsResponse) XXX_Size() int {
+	return xxx_messageInfo_AddDefaultsResponse.Size(m)
+}
+func (m *AddDefaultsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_AddDefaultsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AddDefaultsResponse proto.InternalMessageInfo
+
+func (m *AddDefaultsResponse) GetMachineSpec() []byte {
+	if m != nil {
+		return m.MachineSpec
+	}
+	return nil
+}
+
+func (m *AddDefaultsResponse) GetChanged() bool {
+	if m != nil {
+		return m.Changed
+	}
+	return false
+}
+
+type ValidateRequest struct {
+	MachineSpec          []byte   `protobuf:"bytes,1,opt,name=machine_spec,json=machineSpec,proto3" json:"machine_spec,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ValidateRequest) Reset()         { *m = ValidateRequest{} }
+func (m *ValidateRequest) String() string { return proto.CompactTextString(m) }
+func (*ValidateRequest) ProtoMessage()    {}
+func (*ValidateRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_plugin_22b6773ea52b679e, []int{4}
+}
+func (m *ValidateRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ValidateRequest.Unmarshal(m, b)
+}
+func (m *ValidateRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ValidateRequest.Marshal(b, m, deterministic)
+}
+func (dst *ValidateRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ValidateRequest.Merge(dst, src)
+}
+func (m *ValidateRequest) XXX_Size() int {
+	return xxx_messageInfo_ValidateRequest.Size(m)
+}
+func (m *ValidateRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ValidateRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ValidateRequest proto.InternalMessageInfo
+
+func (m *ValidateRequest) GetMachineSpec() []byte {
+	if m != nil {
+		return m.MachineSpec
+	}
+	return nil
+}
+
+type ValidateResponse struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ValidateResponse) Reset()         { *m = ValidateResponse{} }
+func (m *ValidateResponse) String() string { return proto.CompactTextString(m) }
+func (*ValidateResponse) ProtoMessage()    {}
+func (*ValidateResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_plugin_22b6773ea52b679e, []int{5}
+}
+func (m *ValidateResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ValidateResponse.Unmarshal(m, b)
+}
+func (m *ValidateResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ValidateResponse.Marshal(b, m, deterministic)
+}
+func (dst *ValidateResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ValidateResponse.Merge(dst, src)
+}
+func (m *ValidateResponse) XXX_Size() int {
+	return xxx_messageInfo_ValidateResponse.Size(m)
+}
+func (m *ValidateResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ValidateResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ValidateResponse proto.InternalMessageInfo
+
+type GetRequest struct {
+	Machine              []byte   `protobuf:"bytes,1,opt,name=machine,proto3" json:"machine,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetRequest) Reset()         { *m = GetRequest{} }
+func (m *GetRequest) String() string { return proto.CompactTextString(m) }
+func (*GetRequest) ProtoMessage()    {}
+func (*GetRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_plugin_22b6773ea52b679e, []int{6}
+}
+func (m *GetRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetRequest.Unmarshal(m, b)
+}
+func (m *GetRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetRequest.Marshal(b, m, deterministic)
+}
+func (dst *GetRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetRequest.Merge(dst, src)
+}
+func (m *GetRequest) XXX_Size() int {
+	return xxx_messageInfo_GetRequest.Size(m)
+}
+func (m *GetRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetRequest proto.InternalMessageInfo
+
+func (m *GetRequest) GetMachine() []byte {
+	if m != nil {
+		return m.Machine
+	}
+	return nil
+}
+
+type GetResponse struct {
+	Instance             *Instance `protobuf:"bytes,1,opt,name=instance,proto3" json:"instance,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *GetResponse) Reset()         { *m = GetResponse{} }
+func (m *GetResponse) String() string { return proto.CompactTextString(m) }
+func (*GetResponse) ProtoMessage()    {}
+func (*GetResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_plugin_22b6773ea52b679e, []int{7}
+}
+func (m *GetResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetResponse.Unmarshal(m, b)
+}
+func (m *GetResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetResponse.Marshal(b, m, deterministic)
+}
+func (dst *GetResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetResponse.Merge(dst, src)
+}
+func (m *GetResponse) XXX_Size() int {
+	return xxx_messageInfo_GetResponse.Size(m)
+}
+func (m *GetResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetResponse proto.InternalMessageInfo
+
+func (m *GetResponse) GetInstance() *Instance {
+	if m != nil {
+		return m.Instance
+	}
+	return nil
+}
+
+type GetCloudConfigRequest struct {
+	MachineSpec          []byte   `protobuf:"bytes,1,opt,name=machine_spec,json=machineSpec,proto3" json:"machine_spec,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetCloudConfigRequest) Reset()         { *m = GetCloudConfigRequest{} }
+func (m *GetCloudConfigRequest) String() string { return proto.CompactTextString(m) }
+func (*GetCloudConfigRequest) ProtoMessage()    {}
+func (*GetCloudConfigRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_plugin_22b6773ea52b679e, []int{8}
+}
+func (m *GetCloudConfigRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetCloudConfigRequest.Unmarshal(m, b)
+}
+func (m *GetCloudConfigRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetCloudConfigRequest.Marshal(b, m, deterministic)
+}
+func (dst *GetCloudConfigRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetCloudConfigRequest.Merge(dst, src)
+}
+func (m *GetCloudConfigRequest) XXX_Size() int {
+	return xxx_messageInfo_GetCloudConfigRequest.Size(m)
+}
+func (m *GetCloudConfigRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetCloudConfigRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetCloudConfigRequest proto.InternalMessageInfo
+
+func (m *GetCloudConfigRequest) GetMachineSpec() []byte {
+	if m != nil {
+		return m.MachineSpec
+	}
+	return nil
+}
+
+type GetCloudConfigResponse struct {
+	Config               string   `protobuf:"bytes,1,opt,name=config,proto3" json:"config,omitempty"`
+	Name                 string   `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetCloudConfigResponse) Reset()         { *m = GetCloudConfigResponse{} }
+func (m *GetCloudConfigResponse) String() string { return proto.CompactTextString(m) }
+func (*GetCloudConfigResponse) ProtoMessage()    {}
+func (*GetCloudConfigResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_plugin_22b6773ea52b679e, []int{9}
+}
+func (m *GetCloudConfigResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetCloudConfigResponse.Unmarshal(m, b)
+}
+func (m *GetCloudConfigResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetCloudConfigResponse.Marshal(b, m, deterministic)
+}
+func (dst *GetCloudConfigResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetCloudConfigResponse.Merge(dst, src)
+}
+func (m *GetCloudConfigResponse) XXX_Size() int {
+	return xxx_messageInfo_GetCloudConfigResponse.Size(m)
+}
+func (m *GetCloudConfigResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetCloudConfigResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetCloudConfigResponse proto.InternalMessageInfo
+
+func (m *GetCloudConfigResponse) GetConfig() string {
+	if m != nil {
+		return m.Config
+	}
+	return ""
+}
+
+func (m *GetCloudConfigResponse) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+type CreateRequest struct {
+	Machine              []byte   `protobuf:"bytes,1,opt,name=machine,proto3" json:"machine,omitempty"`
+	Userdata             string   `protobuf:"bytes,2,opt,name=userdata,proto3" json:"userdata,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CreateRequest) Reset()         { *m = CreateRequest{} }
+func (m *CreateRequest) String() string { return proto.CompactTextString(m) }
+func (*CreateRequest) ProtoMessage()    {}
+func (*CreateRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_plugin_22b6773ea52b679e, []int{10}
+}
+func (m *CreateRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CreateRequest.Unmarshal(m, b)
+}
+func (m *CreateRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CreateRequest.Marshal(b, m, deterministic)
+}
+func (dst *CreateRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CreateRequest.Merge(dst, src)
+}
+func (m *CreateRequest) XXX_Size() int {
+	return xxx_messageInfo_CreateRequest.Size(m)
+}
+func (m *CreateRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CreateRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CreateRequest proto.InternalMessageInfo
+
+func (m *CreateRequest) GetMachine() []byte {
+	if m != nil {
+		return m.Machine
+	}
+	return nil
+}
+
+func (m *CreateRequest) GetUserdata() string {
+	if m != nil {
+		return m.Userdata
+	}
+	return ""
+}
+
+type CreateResponse struct {
+	Instance             *Instance `protobuf:"bytes,1,opt,name=instance,proto3" json:"instance,omitempty"`
+	UpdatedMachine       []byte    `protobuf:"bytes,2,opt,name=updated_machine,json=updatedMachine,proto3" json:"updated_machine,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *CreateResponse) Reset()         { *m = CreateResponse{} }
+func (m *CreateResponse) String() string { return proto.CompactTextString(m) }
+func (*CreateResponse) ProtoMessage()    {}
+func (*CreateResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_plugin_22b6773ea52b679e, []int{11}
+}
+func (m *CreateResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CreateResponse.Unmarshal(m, b)
+}
+func (m *CreateResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CreateResponse.Marshal(b, m, deterministic)
+}
+func (dst *CreateResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CreateResponse.Merge(dst, src)
+}
+func (m *CreateResponse) XXX_Size() int {
+	return xxx_messageInfo_CreateResponse.Size(m)
+}
+func (m *CreateResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_CreateResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CreateResponse proto.InternalMessageInfo
+
+func (m *CreateResponse) GetInstance() *Instance {
+	if m != nil {
+		return m.Instance
+	}
+	return nil
+}
+
+func (m *CreateResponse) GetUpdatedMachine() []byte {
+	if m != nil {
+		return m.UpdatedMachine
+	}
+	return nil
+}
+
+type DeleteRequest struct {
+	Machine              []byte   `protobuf:"bytes,1,opt,name=machine,proto3" json:"machine,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeleteRequest) Reset()         { *m = DeleteRequest{} }
+func (m *DeleteRequest) String() string { return proto.CompactTextString(m) }
+func (*DeleteRequest) ProtoMessage()    {}
+func (*DeleteRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_plugin_22b6773ea52b679e, []int{12}
+}
+func (m *DeleteRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeleteRequest.Unmarshal(m, b)
+}
+func (m *DeleteRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeleteRequest.Marshal(b, m, deterministic)
+}
+func (dst *DeleteRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeleteRequest.Merge(dst, src)
+}
+func (m *DeleteRequest) XXX_Size() int {
+	return xxx_messageInfo_DeleteRequest.Size(m)
+}
+func (m *DeleteRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeleteRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeleteRequest proto.InternalMessageInfo
+
+func (m *DeleteRequest) GetMachine() []byte {
+	if m != nil {
+		return m.Machine
+	}
+	return nil
+}
+
+type DeleteResponse struct {
+	UpdatedMachine       []byte   `protobuf:"bytes,1,opt,name=updated_machine,json=updatedMachine,proto3" json:"updated_machine,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeleteResponse) Reset()         { *m = DeleteResponse{} }
+func (m *DeleteResponse) String() string { return proto.CompactTextString(m) }
+func (*DeleteResponse) ProtoMessage()    {}
+func (*DeleteResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_plugin_22b6773ea52b679e, []int{13}
+}
+func (m *DeleteResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeleteResponse.Unmarshal(m, b)
+}
+func (m *DeleteResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeleteResponse.Marshal(b, m, deterministic)
+}
+func (dst *DeleteResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeleteResponse.Merge(dst, src)
+}
+func (m *DeleteResponse) XXX_Size() int {
+	return xxx_messageInfo_DeleteResponse.Size(m)
+}
+func (m *DeleteResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeleteResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeleteResponse proto.InternalMessageInfo
+
+func (m *DeleteResponse) GetUpdatedMachine() []byte {
+	if m != nil {
+		return m.UpdatedMachine
+	}
+	return nil
+}
+
+type MachineMetricsLabelsRequest struct {
+	Machine              []byte   `protobuf:"bytes,1,opt,name=machine,proto3" json:"machine,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *MachineMetricsLabelsRequest) Reset()         { *m = MachineMetricsLabelsRequest{} }
+func (m *MachineMetricsLabelsRequest) String() string { return proto.CompactTextString(m) }
+func (*MachineMetricsLabelsRequest) ProtoMessage()    {}
+func (*MachineMetricsLabelsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_plugin_22b6773ea52b679e, []int{14}
+}
+func (m *MachineMetricsLabelsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_MachineMetricsLabelsRequest.Unmarshal(m, b)
+}
+func (m *MachineMetricsLabelsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_MachineMetricsLabelsRequest.Marshal(b, m, deterministic)
+}
+func (dst *MachineMetricsLabelsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MachineMetricsLabelsRequest.Merge(dst, src)
+}
+func (m *MachineMetricsLabelsRequest) XXX_Size() int {
+	return xxx_messageInfo_MachineMetricsLabelsRequest.Size(m)
+}
+func (m *MachineMetricsLabelsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_MachineMetricsLabelsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MachineMetricsLabelsRequest proto.InternalMessageInfo
+
+func (m *MachineMetricsLabelsRequest) GetMachine() []byte {
+	if m != nil {
+		return m.Machine
+	}
+	return nil
+}
+
+type MachineMetricsLabelsResponse struct {
+	Labels               map[string]string `protobuf:"bytes,1,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *MachineMetricsLabelsResponse) Reset()         { *m = MachineMetricsLabelsResponse{} }
+func (m *MachineMetricsLabelsResponse) String() string { return proto.CompactTextString(m) }
+func (*MachineMetricsLabelsResponse) ProtoMessage()    {}
+func (*MachineMetricsLabelsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_plugin_22b6773ea52b679e, []int{15}
+}
+func (m *MachineMetricsLabelsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_MachineMetricsLabelsResponse.Unmarshal(m, b)
+}
+func (m *MachineMetricsLabelsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_MachineMetricsLabelsResponse.Marshal(b, m, deterministic)
+}
+func (dst *MachineMetricsLabelsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MachineMetricsLabelsResponse.Merge(dst, src)
+}
+func (m *MachineMetricsLabelsResponse) XXX_Size() int {
+	return xxx_messageInfo_MachineMetricsLabelsResponse.Size(m)
+}
+func (m *MachineMetricsLabelsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_MachineMetricsLabelsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MachineMetricsLabelsResponse proto.InternalMessageInfo
+
+func (m *MachineMetricsLabelsResponse) GetLabels() map[string]string {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
+type MigrateUIDRequest struct {
+	Machine              []byte   `protobuf:"bytes,1,opt,name=machine,proto3" json:"machine,omitempty"`
+	NewUid               string   `protobuf:"bytes,2,opt,name=new_uid,json=newUid,proto3" json:"new_uid,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *MigrateUIDRequest) Reset()         { *m = MigrateUIDRequest{} }
+func (m *MigrateUIDRequest) String() string { return proto.CompactTextString(m) }
+func (*MigrateUIDRequest) ProtoMessage()    {}
+func (*MigrateUIDRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_plugin_22b6773ea52b679e, []int{16}
+}
+func (m *MigrateUIDRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_MigrateUIDRequest.Unmarshal(m, b)
+}
+func (m *MigrateUIDRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_MigrateUIDRequest.Marshal(b, m, deterministic)
+}
+func (dst *MigrateUIDRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MigrateUIDRequest.Merge(dst, src)
+}
+func (m *MigrateUIDRequest) XXX_Size() int {
+	return xxx_messageInfo_MigrateUIDRequest.Size(m)
+}
+func (m *MigrateUIDRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_MigrateUIDRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MigrateUIDRequest proto.InternalMessageInfo
+
+func (m *MigrateUIDRequest) GetMachine() []byte {
+	if m != nil {
+		return m.Machine
+	}
+	return nil
+}
+
+func (m *MigrateUIDRequest) GetNewUid() string {
+	if m != nil {
+		return m.NewUid
+	}
+	return ""
+}
+
+type MigrateUIDResponse struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *MigrateUIDResponse) Reset()         { *m = MigrateUIDResponse{} }
+func (m *MigrateUIDResponse) String() string { return proto.CompactTextString(m) }
+func (*MigrateUIDResponse) ProtoMessage()    {}
+func (*MigrateUIDResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_plugin_22b6773ea52b679e, []int{17}
+}
+func (m *MigrateUIDResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_MigrateUIDResponse.Unmarshal(m, b)
+}
+func (m *MigrateUIDResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_MigrateUIDResponse.Marshal(b, m, deterministic)
+}
+func (dst *MigrateUIDResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MigrateUIDResponse.Merge(dst, src)
+}
+func (m *MigrateUIDResponse) XXX_Size() int {
+	return xxx_messageInfo_MigrateUIDResponse.Size(m)
+}
+func (m *MigrateUIDResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_MigrateUIDResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MigrateUIDResponse proto.InternalMessageInfo
+
+func init() {
+	proto.RegisterType((*Instance)(nil), "plugin.v1alpha1.Instance")
+	proto.RegisterType((*TerminalError)(nil), "plugin.v1alpha1.TerminalError")
+	proto.RegisterType((*AddDefaultsRequest)(nil), "plugin.v1alpha1.AddDefaultsRequest")
+	proto.RegisterType((*AddDefaultsResponse)(nil), "plugin.v1alpha1.AddDefaultsResponse")
+	proto.RegisterType((*ValidateRequest)(nil), "plugin.v1alpha1.ValidateRequest")
+	proto.RegisterType((*ValidateResponse)(nil), "plugin.v1alpha1.ValidateResponse")
+	proto.RegisterType((*GetRequest)(nil), "plugin.v1alpha1.GetRequest")
+	proto.RegisterType((*GetResponse)(nil), "plugin.v1alpha1.GetResponse")
+	proto.RegisterType((*GetCloudConfigRequest)(nil), "plugin.v1alpha1.GetCloudConfigRequest")
+	proto.RegisterType((*GetCloudConfigResponse)(nil), "plugin.v1alpha1.GetCloudConfigResponse")
+	proto.RegisterType((*CreateRequest)(nil), "plugin.v1alpha1.CreateRequest")
+	proto.RegisterType((*CreateResponse)(nil), "plugin.v1alpha1.CreateResponse")
+	proto.RegisterType((*DeleteRequest)(nil), "plugin.v1alpha1.DeleteRequest")
+	proto.RegisterType((*DeleteResponse)(nil), "plugin.v1alpha1.DeleteResponse")
+	proto.RegisterType((*MachineMetricsLabelsRequest)(nil), "plugin.v1alpha1.MachineMetricsLabelsRequest")
+	proto.RegisterType((*MachineMetricsLabelsResponse)(nil), "plugin.v1alpha1.MachineMetricsLabelsResponse")
+	proto.RegisterMapType((map[string]string)(nil), "plugin.v1alpha1.MachineMetricsLabelsResponse.LabelsEntry")
+	proto.RegisterType((*MigrateUIDRequest)(nil), "plugin.v1alpha1.MigrateUIDRequest")
+	proto.RegisterType((*MigrateUIDResponse)(nil), "plugin.v1alpha1.MigrateUIDResponse")
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// ProviderClient is the client API for Provider service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type ProviderClient interface {
+	AddDefaults(ctx context.Context, in *AddDefaultsRequest, opts ...grpc.CallOption) (*AddDefaultsResponse, error)
+	Validate(ctx context.Context, in *ValidateRequest, opts ...grpc.CallOption) (*ValidateResponse, error)
+	Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*GetResponse, error)
+	GetCloudConfig(ctx context.Context, in *GetCloudConfigRequest, opts ...grpc.CallOption) (*GetCloudConfigResponse, error)
+	Create(ctx context.Context, in *CreateRequest, opts ...grpc.CallOption) (*CreateResponse, error)
+	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error)
+	MachineMetricsLabels(ctx context.Context, in *MachineMetricsLabelsRequest, opts ...grpc.CallOption) (*MachineMetricsLabelsResponse, error)
+	MigrateUID(ctx context.Context, in *MigrateUIDRequest, opts ...grpc.CallOption) (*MigrateUIDResponse, error)
+}
+
+type providerClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewProviderClient(cc *grpc.ClientConn) ProviderClient {
+	return &providerClient{cc}
+}
+
+func (c *providerClient) AddDefaults(ctx context.Context, in *AddDefaultsRequest, opts ...grpc.CallOption) (*AddDefaultsResponse, error) {
+	out := new(AddDefaultsResponse)
+	err := c.cc.Invoke(ctx, "/plugin.v1alpha1.Provider/AddDefaults", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *providerClient) Validate(ctx context.Context, in *ValidateRequest, opts ...grpc.CallOption) (*ValidateResponse, error) {
+	out := new(ValidateResponse)
+	err := c.cc.Invoke(ctx, "/plugin.v1alpha1.Provider/Validate", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *providerClient) Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*GetResponse, error) {
+	out := new(GetResponse)
+	err := c.cc.Invoke(ctx, "/plugin.v1alpha1.Provider/Get", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *providerClient) GetCloudConfig(ctx context.Context, in *GetCloudConfigRequest, opts ...grpc.CallOption) (*GetCloudConfigResponse, error) {
+	out := new(GetCloudConfigResponse)
+	err := c.cc.Invoke(ctx, "/plugin.v1alpha1.Provider/GetCloudConfig", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *providerClient) Create(ctx context.Context, in *CreateRequest, opts ...grpc.CallOption) (*CreateResponse, error) {
+	out := new(CreateResponse)
+	err := c.cc.Invoke(ctx, "/plugin.v1alpha1.Provider/Create", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *providerClient) Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error) {
+	out := new(DeleteResponse)
+	err := c.cc.Invoke(ctx, "/plugin.v1alpha1.Provider/Delete", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *providerClient) MachineMetricsLabels(ctx context.Context, in *MachineMetricsLabelsRequest, opts ...grpc.CallOption) (*MachineMetricsLabelsResponse, error) {
+	out := new(MachineMetricsLabelsResponse)
+	err := c.cc.Invoke(ctx, "/plugin.v1alpha1.Provider/MachineMetricsLabels", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *providerClient) MigrateUID(ctx context.Context, in *MigrateUIDRequest, opts ...grpc.CallOption) (*MigrateUIDResponse, error) {
+	out := new(MigrateUIDResponse)
+	err := c.cc.Invoke(ctx, "/plugin.v1alpha1.Provider/MigrateUID", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ProviderServer is the server API for Provider service.
+type ProviderServer interface {
+	AddDefaults(context.Context, *AddDefaultsRequest) (*AddDefaultsResponse, error)
+	Validate(context.Context, *ValidateRequest) (*ValidateResponse, error)
+	Get(context.Context, *GetRequest) (*GetResponse, error)
+	GetCloudConfig(context.Context, *GetCloudConfigRequest) (*GetCloudConfigResponse, error)
+	Create(context.Context, *CreateRequest) (*CreateResponse, error)
+	Delete(context.Context, *DeleteRequest) (*DeleteResponse, error)
+	MachineMetricsLabels(context.Context, *MachineMetricsLabelsRequest) (*MachineMetricsLabelsResponse, error)
+	MigrateUID(context.Context, *MigrateUIDRequest) (*MigrateUIDResponse, error)
+}
+
+func RegisterProviderServer(s *grpc.Server, srv ProviderServer) {
+	s.RegisterService(&_Provider_serviceDesc, srv)
+}
+
+func _Provider_AddDefaults_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddDefaultsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProviderServer).AddDefaults(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/plugin.v1alpha1.Provider/AddDefaults",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProviderServer).AddDefaults(ctx, req.(*AddDefaultsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Provider_Validate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ValidateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProviderServer).Validate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/plugin.v1alpha1.Provider/Validate",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProviderServer).Validate(ctx, req.(*ValidateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Provider_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProviderServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/plugin.v1alpha1.Provider/Get",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProviderServer).Get(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Provider_GetCloudConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCloudConfigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProviderServer).GetCloudConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/plugin.v1alpha1.Provider/GetCloudConfig",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProviderServer).GetCloudConfig(ctx, req.(*GetCloudConfigRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Provider_Create_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProviderServer).Create(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/plugin.v1alpha1.Provider/Create",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProviderServer).Create(ctx, req.(*CreateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Provider_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProviderServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/plugin.v1alpha1.Provider/Delete",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProviderServer).Delete(ctx, req.(*DeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Provider_MachineMetricsLabels_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MachineMetricsLabelsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProviderServer).MachineMetricsLabels(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/plugin.v1alpha1.Provider/MachineMetricsLabels",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProviderServer).MachineMetricsLabels(ctx, req.(*MachineMetricsLabelsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Provider_MigrateUID_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MigrateUIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProviderServer).MigrateUID(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/plugin.v1alpha1.Provider/MigrateUID",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProviderServer).MigrateUID(ctx, req.(*MigrateUIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Provider_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "plugin.v1alpha1.Provider",
+	HandlerType: (*ProviderServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "AddDefaults",
+			Handler:    _Provider_AddDefaults_Handler,
+		},
+		{
+			MethodName: "Validate",
+			Handler:    _Provider_Validate_Handler,
+		},
+		{
+			MethodName: "Get",
+			Handler:    _Provider_Get_Handler,
+		},
+		{
+			MethodName: "GetCloudConfig",
+			Handler:    _Provider_GetCloudConfig_Handler,
+		},
+		{
+			MethodName: "Create",
+			Handler:    _Provider_Create_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _Provider_Delete_Handler,
+		},
+		{
+			MethodName: "MachineMetricsLabels",
+			Handler:    _Provider_MachineMetricsLabels_Handler,
+		},
+		{
+			MethodName: "MigrateUID",
+			Handler:    _Provider_MigrateUID_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "pkg/cloudprovider/provider/plugin/api/v1alpha1/plugin.proto",
+}
+
+func init() {
+	proto.RegisterFile("pkg/cloudprovider/provider/plugin/api/v1alpha1/plugin.proto", fileDescriptor_plugin_22b6773ea52b679e)
+}
+
+var fileDescriptor_plugin_22b6773ea52b679e = []byte{
+	// 699 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x55, 0xff, 0x4f, 0xd3, 0x40,
+	0x14, 0xcf, 0x36, 0x18, 0xe5, 0x0d, 0x06, 0x9e, 0x88, 0xb3, 0x10, 0x19, 0x87, 0x01, 0x4c, 0x74,
+	0x0b, 0xa8, 0x41, 0xf0, 0x17, 0x91, 0x21, 0x21, 0x91, 0xa8, 0x55, 0x8c, 0xf1, 0x17, 0x72, 0xb4,
+	0x8f, 0xd1, 0xd0, 0xb5, 0xf5, 0xee, 0x0a, 0xe1, 0x7f, 0xf2, 0x4f, 0xf0, 0x8f, 0x33, 0x6b, 0xef,
+	0xd6, 0x6d, 0x2d, 0x03, 0xe2, 0x6f, 0xf7, 0xde, 0xbd, 0xcf, 0xe7, 0x7d, 0x7a, 0xef, 0x4b, 0xe1,
+	0x5d, 0x78, 0xd1, 0x6e, 0xda, 0x5e, 0x10, 0x39, 0x21, 0x0f, 0x2e, 0x5d, 0x07, 0x79, 0x33, 0x3d,
+	0x78, 0x51, 0xdb, 0xf5, 0x9b, 0x2c, 0x74, 0x9b, 0x97, 0x1b, 0xcc, 0x0b, 0xcf, 0xd9, 0x86, 0xf2,
+	0x35, 0x42, 0x1e, 0xc8, 0x80, 0xcc, 0x28, 0x4b, 0xdf, 0x52, 0x07, 0x8c, 0x43, 0x5f, 0x48, 0xe6,
+	0xdb, 0x48, 0x08, 0x8c, 0xf9, 0xac, 0x83, 0xb5, 0x42, 0xbd, 0xb0, 0x3e, 0x69, 0xc5, 0x67, 0x52,
+	0x85, 0xa2, 0xeb, 0xd4, 0x8a, 0xb1, 0xa7, 0xe8, 0x3a, 0x64, 0x11, 0x26, 0x99, 0xe3, 0x70, 0x14,
+	0x02, 0x45, 0xad, 0x54, 0x2f, 0xad, 0x4f, 0x5a, 0xa9, 0x83, 0xcc, 0x43, 0x59, 0x48, 0x26, 0x23,
+	0x51, 0x1b, 0x8b, 0x11, 0xca, 0xa2, 0xbb, 0x30, 0xfd, 0x1d, 0x79, 0xc7, 0xf5, 0x99, 0xb7, 0xcf,
+	0x79, 0xc0, 0xbb, 0x81, 0x1c, 0x99, 0x08, 0x7c, 0x95, 0x4c, 0x59, 0xa4, 0x06, 0x13, 0x1d, 0x14,
+	0x82, 0xb5, 0x51, 0xe5, 0xd4, 0x26, 0xdd, 0x02, 0xb2, 0xeb, 0x38, 0x2d, 0x3c, 0x63, 0x91, 0x27,
+	0x85, 0x85, 0xbf, 0x23, 0x14, 0x92, 0x2c, 0xc3, 0x54, 0x87, 0xd9, 0xe7, 0xae, 0x8f, 0x27, 0x22,
+	0x44, 0x3b, 0x66, 0x9b, 0xb2, 0x2a, 0xca, 0xf7, 0x2d, 0x44, 0x9b, 0x5a, 0xf0, 0x70, 0x00, 0x28,
+	0xc2, 0xc0, 0x17, 0x78, 0x07, 0x64, 0x57, 0x8c, 0x7d, 0xce, 0xfc, 0x36, 0x26, 0x0f, 0x60, 0x58,
+	0xda, 0xa4, 0xaf, 0x61, 0xe6, 0x07, 0xf3, 0x5c, 0x87, 0x49, 0xbc, 0x87, 0x12, 0x02, 0xb3, 0x29,
+	0x2a, 0x91, 0x41, 0x57, 0x01, 0x0e, 0x50, 0x6a, 0x92, 0xee, 0xe7, 0x27, 0x00, 0x85, 0xd7, 0x26,
+	0x6d, 0x41, 0x25, 0x8e, 0x53, 0xea, 0xdf, 0x80, 0xe1, 0xaa, 0xb2, 0xc5, 0x91, 0x95, 0xcd, 0x27,
+	0x8d, 0xa1, 0xd2, 0x36, 0x74, 0x5d, 0xad, 0x5e, 0x28, 0xdd, 0x81, 0x47, 0x07, 0x28, 0xf7, 0xba,
+	0xcd, 0xb3, 0x17, 0xf8, 0x67, 0x6e, 0xfb, 0x1e, 0xea, 0x5b, 0x30, 0x3f, 0x8c, 0x55, 0x62, 0xe6,
+	0xa1, 0x6c, 0xc7, 0x1e, 0x5d, 0xcc, 0xc4, 0xea, 0xf5, 0x53, 0x31, 0xed, 0x27, 0xba, 0x0f, 0xd3,
+	0x7b, 0x1c, 0xfb, 0xde, 0xed, 0xc6, 0x4f, 0x26, 0x26, 0x18, 0x91, 0x40, 0xee, 0x30, 0xc9, 0x14,
+	0x45, 0xcf, 0xa6, 0x21, 0x54, 0x35, 0xcd, 0x7f, 0xbd, 0x08, 0x59, 0x83, 0x99, 0x28, 0xec, 0x56,
+	0xc4, 0x39, 0xd1, 0x32, 0x8a, 0xb1, 0x8c, 0xaa, 0x72, 0x1f, 0xa9, 0x02, 0x3c, 0x87, 0xe9, 0x16,
+	0x7a, 0x78, 0x07, 0xe1, 0x74, 0x1b, 0xaa, 0x3a, 0x54, 0x89, 0xcb, 0xc9, 0x52, 0xc8, 0xcd, 0xb2,
+	0x05, 0x0b, 0xea, 0x78, 0x84, 0x92, 0xbb, 0xb6, 0xf8, 0xc4, 0x4e, 0xd1, 0x13, 0xb7, 0xe7, 0xfc,
+	0x53, 0x80, 0xc5, 0x7c, 0xa4, 0x92, 0xf0, 0x15, 0xca, 0x5e, 0xec, 0xa9, 0x15, 0xea, 0xa5, 0xf5,
+	0xca, 0xe6, 0x76, 0xe6, 0x75, 0x46, 0xc1, 0x1b, 0x89, 0xb9, 0xef, 0x4b, 0x7e, 0x6d, 0x29, 0x22,
+	0x73, 0x1b, 0x2a, 0x7d, 0x6e, 0x32, 0x0b, 0xa5, 0x0b, 0xbc, 0x56, 0x3d, 0xd0, 0x3d, 0x92, 0x39,
+	0x18, 0xbf, 0x64, 0x5e, 0xa4, 0x3b, 0x20, 0x31, 0x76, 0x8a, 0x6f, 0x0b, 0xf4, 0x23, 0x3c, 0x38,
+	0x72, 0xdb, 0x9c, 0x49, 0x3c, 0x3e, 0x6c, 0xdd, 0xde, 0x0a, 0x8f, 0x61, 0xc2, 0xc7, 0xab, 0x93,
+	0xa8, 0xb7, 0x8a, 0xca, 0x3e, 0x5e, 0x1d, 0xbb, 0x0e, 0x9d, 0x03, 0xd2, 0xcf, 0x93, 0x88, 0xdd,
+	0xfc, 0x3b, 0x0e, 0xc6, 0x17, 0xb5, 0x13, 0xc9, 0x4f, 0xa8, 0xf4, 0xcd, 0x3f, 0x59, 0xc9, 0x7c,
+	0x77, 0x76, 0xad, 0x98, 0xcf, 0x46, 0x07, 0xa9, 0x27, 0xfd, 0x0c, 0x86, 0x9e, 0x67, 0x52, 0xcf,
+	0x20, 0x86, 0x16, 0x84, 0xb9, 0x3c, 0x22, 0x42, 0x11, 0xbe, 0x87, 0xd2, 0x01, 0x4a, 0xb2, 0x90,
+	0x89, 0x4c, 0x57, 0x84, 0xb9, 0x98, 0x7f, 0xa9, 0x18, 0x18, 0x54, 0x07, 0x87, 0x94, 0xac, 0xe6,
+	0xc5, 0x67, 0x37, 0x80, 0xb9, 0x76, 0x6b, 0x9c, 0x4a, 0x71, 0x08, 0xe5, 0x64, 0xf4, 0xc8, 0xd3,
+	0x0c, 0x64, 0x60, 0xb4, 0xcd, 0xa5, 0x1b, 0xef, 0x53, 0xaa, 0x64, 0x50, 0x72, 0xa8, 0x06, 0x86,
+	0xcd, 0x5c, 0xba, 0xf1, 0x5e, 0x51, 0x09, 0x98, 0xcb, 0xeb, 0x5f, 0xf2, 0xe2, 0x8e, 0x6d, 0x9e,
+	0xa4, 0x79, 0x79, 0xaf, 0xa1, 0x20, 0xc7, 0x00, 0x69, 0xf7, 0x11, 0x9a, 0x05, 0x0f, 0xb7, 0xb8,
+	0xb9, 0x32, 0x32, 0x26, 0xa1, 0xfd, 0x00, 0xbf, 0x0c, 0x7d, 0x7d, 0x5a, 0x8e, 0xff, 0xdb, 0xaf,
+	0xfe, 0x0d, 0x00, 0xd0, 0x4a, 0x99, 0x30, 0xf6, 0x07, 0x00, 0x00,
+}
@@ -0,0 +1,64 @@
+package plugin
+
+import (
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	cloudprovidererrors "github.com/kubermatic/machine-controller/pkg/cloudprovider/errors"
+	pluginapi "github.com/kubermatic/machine-controller/pkg/cloudprovider/provider/plugin/api/v1alpha1"
+
+	common "sigs.k8s.io/cluster-api/pkg/apis/cluster/common"
+)
+
+// errorToStatus converts an error returned by a cloud.Provider into a gRPC status error
+func errorToStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if err == cloudprovidererrors.ErrInstanceNotFound {
+		return status.Error(codes.NotFound, err.Error())
+	}
+
+	if isTerminal, reason, message := cloudprovidererrors.IsTerminalError(err); isTerminal {
+		st, detailErr := status.New(codes.FailedPrecondition, message).WithDetails(&pluginapi.TerminalError{
+			Reason:  string(reason),
+			Message: message,
+		})
+		if detailErr != nil {
+			return status.Error(codes.Internal, fmt.Sprintf("failed to attach terminal error %q: %v", message, detailErr))
+		}
+		return st.Err()
+	}
+
+	return status.Error(codes.Unknown, err.Error())
+}
+
+// statusToError converts a gRPC status error returned by a plugin back into
+// the errors the machine-controller expects from a cloud.Provider
+func statusToError(err error, msg string) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return fmt.Errorf("%s, due to %v", msg, err)
+	}
+
+	switch st.Code() {
+	case codes.NotFound:
+		return cloudprovidererrors.ErrInstanceNotFound
+	case codes.FailedPrecondition:
+		for _, detail := range st.Details() {
+			if terminalError, ok := detail.(*pluginapi.TerminalError); ok {
+				return cloudprovidererrors.TerminalError{
+					Reason:  common.MachineStatusError(terminalError.Reason),
+					Message: terminalError.Message,
+				}
+			}
+		}
+	case codes.Unimplemented:
+		return fmt.Errorf("%s, the plugin does not support the v1alpha1 plugin protocol: %s", msg, st.Message())
+	}
+
+	return fmt.Errorf("%s, due to %s", msg, st.Message())
+}
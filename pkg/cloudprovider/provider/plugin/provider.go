@@ -0,0 +1,364 @@
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+
+	"github.com/kubermatic/machine-controller/pkg/cloudprovider/cloud"
+	cloudprovidererrors "github.com/kubermatic/machine-controller/pkg/cloudprovider/errors"
+	"github.com/kubermatic/machine-controller/pkg/cloudprovider/instance"
+	pluginapi "github.com/kubermatic/machine-controller/pkg/cloudprovider/provider/plugin/api/v1alpha1"
+	"github.com/kubermatic/machine-controller/pkg/providerconfig"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	common "sigs.k8s.io/cluster-api/pkg/apis/cluster/common"
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+)
+
+const (
+	unixSocketPrefix = "unix://"
+
+	// callTimeout is the maximum duration of a single call to the plugin.
+	// Plugins are expected to return early with a creating/deleting instance
+	// instead of blocking until the cloud operation finished
+	callTimeout = 5 * time.Minute
+)
+
+var (
+	// The provider gets created for every sync, so we keep the connections
+	// to the plugins around
+	connectionsLock = &sync.Mutex{}
+	connections     = map[string]*grpc.ClientConn{}
+)
+
+type provider struct {
+	configVarResolver *providerconfig.ConfigVarResolver
+}
+
+// New returns a provider which forwards all calls to an out-of-tree plugin
+func New(configVarResolver *providerconfig.ConfigVarResolver) cloud.Provider {
+	return &provider{configVarResolver: configVarResolver}
+}
+
+// RawConfig contains the fields of the cloudProviderSpec the machine-controller itself uses.
+// The whole cloudProviderSpec gets passed to the plugin as part of the machine.
+type RawConfig struct {
+	Endpoint providerconfig.ConfigVarString `json:"endpoint"`
+}
+
+type Config struct {
+	Endpoint string
+}
+
+func (p *provider) getConfig(s v1alpha1.ProviderConfig) (*Config, error) {
+	if s.Value == nil {
+		return nil, fmt.Errorf("machine.spec.providerconfig.value is nil")
+	}
+	pconfig := providerconfig.Config{}
+	err := json.Unmarshal(s.Value.Raw, &pconfig)
+	if err != nil {
+		return nil, err
+	}
+
+	rawConfig := RawConfig{}
+	if err = json.Unmarshal(pconfig.CloudProviderSpec.Raw, &rawConfig); err != nil {
+		return nil, err
+	}
+
+	c := Config{}
+	c.Endpoint, err = p.configVarResolver.GetConfigVarStringValueOrEnv(rawConfig.Endpoint, "PLUGIN_ENDPOINT")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the value of \"endpoint\" field, error = %v", err)
+	}
+	if c.Endpoint == "" {
+		return nil, errors.New("endpoint is missing")
+	}
+
+	return &c, nil
+}
+
+func dial(endpoint string) (*grpc.ClientConn, error) {
+	opts := []grpc.DialOption{grpc.WithInsecure()}
+	if strings.HasPrefix(endpoint, unixSocketPrefix) {
+		endpoint = strings.TrimPrefix(endpoint, unixSocketPrefix)
+		opts = append(opts, grpc.WithDialer(func(addr string, timeout time.Duration) (net.Conn, error) {
+			return net.DialTimeout("unix", addr, timeout)
+		}))
+	}
+	return grpc.Dial(endpoint, opts...)
+}
+
+func getClient(endpoint string) (pluginapi.ProviderClient, error) {
+	connectionsLock.Lock()
+	defer connectionsLock.Unlock()
+
+	conn, exists := connections[endpoint]
+	if !exists {
+		var err error
+		conn, err = dial(endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to plugin at %q: %v", endpoint, err)
+		}
+		glog.V(4).Infof("Connected to cloud provider plugin at %q", endpoint)
+		connections[endpoint] = conn
+	}
+
+	return pluginapi.NewProviderClient(conn), nil
+}
+
+func (p *provider) getClientForSpec(spec v1alpha1.MachineSpec) (pluginapi.ProviderClient, error) {
+	c, err := p.getConfig(spec.ProviderConfig)
+	if err != nil {
+		return nil, cloudprovidererrors.TerminalError{
+			Reason:  common.InvalidConfigurationMachineError,
+			Message: fmt.Sprintf("Failed to parse MachineSpec, due to %v", err),
+		}
+	}
+	return getClient(c.Endpoint)
+}
+
+func (p *provider) AddDefaults(spec v1alpha1.MachineSpec) (v1alpha1.MachineSpec, bool, error) {
+	client, err := p.getClientForSpec(spec)
+	if err != nil {
+		return spec, false, err
+	}
+
+	rawSpec, err := json.Marshal(spec)
+	if err != nil {
+		return spec, false, fmt.Errorf("failed to marshal machine spec: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), callTimeout)
+	defer cancel()
+	resp, err := client.AddDefaults(ctx, &pluginapi.AddDefaultsRequest{MachineSpec: rawSpec})
+	if err != nil {
+		return spec, false, statusToError(err, "failed to add defaults")
+	}
+	if !resp.Changed {
+		return spec, false, nil
+	}
+
+	defaultedSpec := v1alpha1.MachineSpec{}
+	if err := json.Unmarshal(resp.MachineSpec, &defaultedSpec); err != nil {
+		return spec, false, fmt.Errorf("failed to unmarshal defaulted machine spec: %v", err)
+	}
+	return defaultedSpec, true, nil
+}
+
+func (p *provider) Validate(spec v1alpha1.MachineSpec) error {
+	c, err := p.getConfig(spec.ProviderConfig)
+	if err != nil {
+		return fmt.Errorf("failed to parse config: %v", err)
+	}
+	client, err := getClient(c.Endpoint)
+	if err != nil {
+		return err
+	}
+
+	rawSpec, err := json.Marshal(spec)
+	if err != nil {
+		return fmt.Errorf("failed to marshal machine spec: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), callTimeout)
+	defer cancel()
+	if _, err := client.Validate(ctx, &pluginapi.ValidateRequest{MachineSpec: rawSpec}); err != nil {
+		return statusToError(err, "validation failed")
+	}
+	return nil
+}
+
+func (p *provider) Get(machine *v1alpha1.Machine) (instance.Instance, error) {
+	client, err := p.getClientForSpec(machine.Spec)
+	if err != nil {
+		return nil, err
+	}
+
+	rawMachine, err := json.Marshal(machine)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal machine: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), callTimeout)
+	defer cancel()
+	resp, err := client.Get(ctx, &pluginapi.GetRequest{Machine: rawMachine})
+	if err != nil {
+		return nil, statusToError(err, "failed to get instance")
+	}
+	if resp.Instance == nil {
+		return nil, cloudprovidererrors.ErrInstanceNotFound
+	}
+
+	return &pluginInstance{instance: resp.Instance}, nil
+}
+
+func (p *provider) GetCloudConfig(spec v1alpha1.MachineSpec) (config string, name string, err error) {
+	client, err := p.getClientForSpec(spec)
+	if err != nil {
+		return "", "", err
+	}
+
+	rawSpec, err := json.Marshal(spec)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to marshal machine spec: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), callTimeout)
+	defer cancel()
+	resp, err := client.GetCloudConfig(ctx, &pluginapi.GetCloudConfigRequest{MachineSpec: rawSpec})
+	if err != nil {
+		return "", "", statusToError(err, "failed to get cloud config")
+	}
+	return resp.Config, resp.Name, nil
+}
+
+func (p *provider) Create(machine *v1alpha1.Machine, update cloud.MachineUpdater, userdata string) (instance.Instance, error) {
+	client, err := p.getClientForSpec(machine.Spec)
+	if err != nil {
+		return nil, err
+	}
+
+	rawMachine, err := json.Marshal(machine)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal machine: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), callTimeout)
+	defer cancel()
+	resp, err := client.Create(ctx, &pluginapi.CreateRequest{Machine: rawMachine, Userdata: userdata})
+	if err != nil {
+		return nil, statusToError(err, "failed to create instance")
+	}
+
+	if err := applyMachineUpdates(machine, update, resp.UpdatedMachine); err != nil {
+		return nil, err
+	}
+	if resp.Instance == nil {
+		return nil, errors.New("plugin did not return the created instance")
+	}
+
+	return &pluginInstance{instance: resp.Instance}, nil
+}
+
+func (p *provider) Delete(machine *v1alpha1.Machine, update cloud.MachineUpdater) error {
+	client, err := p.getClientForSpec(machine.Spec)
+	if err != nil {
+		return err
+	}
+
+	rawMachine, err := json.Marshal(machine)
+	if err != nil {
+		return fmt.Errorf("failed to marshal machine: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), callTimeout)
+	defer cancel()
+	resp, err := client.Delete(ctx, &pluginapi.DeleteRequest{Machine: rawMachine})
+	if err != nil {
+		return statusToError(err, "failed to delete instance")
+	}
+
+	return applyMachineUpdates(machine, update, resp.UpdatedMachine)
+}
+
+func (p *provider) MachineMetricsLabels(machine *v1alpha1.Machine) (map[string]string, error) {
+	client, err := p.getClientForSpec(machine.Spec)
+	if err != nil {
+		return nil, err
+	}
+
+	rawMachine, err := json.Marshal(machine)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal machine: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), callTimeout)
+	defer cancel()
+	resp, err := client.MachineMetricsLabels(ctx, &pluginapi.MachineMetricsLabelsRequest{Machine: rawMachine})
+	if err != nil {
+		return nil, statusToError(err, "failed to get machine metrics labels")
+	}
+
+	labels := resp.Labels
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	return labels, nil
+}
+
+func (p *provider) MigrateUID(machine *v1alpha1.Machine, new types.UID) error {
+	client, err := p.getClientForSpec(machine.Spec)
+	if err != nil {
+		return err
+	}
+
+	rawMachine, err := json.Marshal(machine)
+	if err != nil {
+		return fmt.Errorf("failed to marshal machine: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), callTimeout)
+	defer cancel()
+	if _, err := client.MigrateUID(ctx, &pluginapi.MigrateUIDRequest{Machine: rawMachine, NewUid: string(new)}); err != nil {
+		return statusToError(err, "failed to migrate uid")
+	}
+	return nil
+}
+
+// applyMachineUpdates persists the changes a plugin made to the machine
+// during a call, as the plugin has no access to the MachineUpdater
+func applyMachineUpdates(machine *v1alpha1.Machine, update cloud.MachineUpdater, rawUpdatedMachine []byte) error {
+	if len(rawUpdatedMachine) == 0 {
+		return nil
+	}
+
+	updatedMachine := &v1alpha1.Machine{}
+	if err := json.Unmarshal(rawUpdatedMachine, updatedMachine); err != nil {
+		return fmt.Errorf("failed to unmarshal updated machine: %v", err)
+	}
+
+	if _, err := update(machine, func(m *v1alpha1.Machine) {
+		m.Labels = updatedMachine.Labels
+		m.Annotations = updatedMachine.Annotations
+		m.Finalizers = updatedMachine.Finalizers
+		m.Spec = updatedMachine.Spec
+	}); err != nil {
+		return fmt.Errorf("failed to update machine: %v", err)
+	}
+	return nil
+}
+
+type pluginInstance struct {
+	instance *pluginapi.Instance
+}
+
+func (i *pluginInstance) Name() string {
+	return i.instance.GetName()
+}
+
+func (i *pluginInstance) ID() string {
+	return i.instance.GetId()
+}
+
+func (i *pluginInstance) Addresses() []string {
+	return i.instance.GetAddresses()
+}
+
+func (i *pluginInstance) Status() instance.Status {
+	switch status := instance.Status(i.instance.GetStatus()); status {
+	case instance.StatusRunning, instance.StatusDeleting, instance.StatusDeleted, instance.StatusCreating, instance.StatusInterrupted:
+		return status
+	default:
+		return instance.StatusUnknown
+	}
+}
@@ -0,0 +1,195 @@
+package plugin
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/kubermatic/machine-controller/pkg/cloudprovider/cloud"
+	cloudprovidererrors "github.com/kubermatic/machine-controller/pkg/cloudprovider/errors"
+	"github.com/kubermatic/machine-controller/pkg/cloudprovider/instance"
+	"github.com/kubermatic/machine-controller/pkg/providerconfig"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	common "sigs.k8s.io/cluster-api/pkg/apis/cluster/common"
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+)
+
+const testFinalizer = "kubermatic.io/test-plugin"
+
+type testInstance struct{}
+
+func (i *testInstance) Name() string            { return "test-instance" }
+func (i *testInstance) ID() string              { return "1234" }
+func (i *testInstance) Addresses() []string     { return []string{"10.0.0.1"} }
+func (i *testInstance) Status() instance.Status { return instance.StatusRunning }
+
+// testProvider knows a single instance, which belongs to the machine with the uid "existing"
+type testProvider struct{}
+
+func (p *testProvider) AddDefaults(spec v1alpha1.MachineSpec) (v1alpha1.MachineSpec, bool, error) {
+	return spec, false, nil
+}
+
+func (p *testProvider) Validate(spec v1alpha1.MachineSpec) error {
+	return cloudprovidererrors.TerminalError{
+		Reason:  common.InvalidConfigurationMachineError,
+		Message: "invalid",
+	}
+}
+
+func (p *testProvider) Get(machine *v1alpha1.Machine) (instance.Instance, error) {
+	if machine.UID != "existing" {
+		return nil, cloudprovidererrors.ErrInstanceNotFound
+	}
+	return &testInstance{}, nil
+}
+
+func (p *testProvider) GetCloudConfig(spec v1alpha1.MachineSpec) (string, string, error) {
+	return "config", "test", nil
+}
+
+func (p *testProvider) Create(machine *v1alpha1.Machine, update cloud.MachineUpdater, userdata string) (instance.Instance, error) {
+	if _, err := update(machine, func(m *v1alpha1.Machine) {
+		m.Finalizers = append(m.Finalizers, testFinalizer)
+	}); err != nil {
+		return nil, err
+	}
+	return &testInstance{}, nil
+}
+
+func (p *testProvider) Delete(machine *v1alpha1.Machine, update cloud.MachineUpdater) error {
+	return fmt.Errorf("delete failed")
+}
+
+func (p *testProvider) MachineMetricsLabels(machine *v1alpha1.Machine) (map[string]string, error) {
+	return map[string]string{"size": "small"}, nil
+}
+
+func (p *testProvider) MigrateUID(machine *v1alpha1.Machine, new types.UID) error {
+	return nil
+}
+
+func startTestPlugin(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "machine-controller-plugin")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	socket := filepath.Join(dir, "plugin.sock")
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatalf("failed to listen on %s: %v", socket, err)
+	}
+	go func() {
+		_ = Serve(l, &testProvider{})
+	}()
+
+	return unixSocketPrefix + socket, func() {
+		_ = l.Close()
+		_ = os.RemoveAll(dir)
+	}
+}
+
+func newMachine(uid types.UID, endpoint string) *v1alpha1.Machine {
+	return &v1alpha1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-machine",
+			UID:  uid,
+		},
+		Spec: v1alpha1.MachineSpec{
+			ProviderConfig: v1alpha1.ProviderConfig{
+				Value: &runtime.RawExtension{
+					Raw: []byte(fmt.Sprintf(`{"cloudProvider":"plugin","cloudProviderSpec":{"endpoint":"%s"}}`, endpoint)),
+				},
+			},
+		},
+	}
+}
+
+func TestProvider(t *testing.T) {
+	endpoint, cleanup := startTestPlugin(t)
+	defer cleanup()
+
+	p := New(providerconfig.NewConfigVarResolver(nil))
+
+	t.Run("get existing instance", func(t *testing.T) {
+		i, err := p.Get(newMachine("existing", endpoint))
+		if err != nil {
+			t.Fatalf("failed to get instance: %v", err)
+		}
+		if i.ID() != "1234" || i.Name() != "test-instance" || i.Status() != instance.StatusRunning {
+			t.Errorf("got unexpected instance %s/%s with status %s", i.ID(), i.Name(), i.Status())
+		}
+		if !reflect.DeepEqual(i.Addresses(), []string{"10.0.0.1"}) {
+			t.Errorf("got unexpected addresses %v", i.Addresses())
+		}
+	})
+
+	t.Run("get missing instance", func(t *testing.T) {
+		if _, err := p.Get(newMachine("missing", endpoint)); err != cloudprovidererrors.ErrInstanceNotFound {
+			t.Errorf("expected ErrInstanceNotFound, got %v", err)
+		}
+	})
+
+	t.Run("terminal errors", func(t *testing.T) {
+		err := p.Validate(newMachine("existing", endpoint).Spec)
+		isTerminal, reason, message := cloudprovidererrors.IsTerminalError(err)
+		if !isTerminal || reason != common.InvalidConfigurationMachineError || message != "invalid" {
+			t.Errorf("expected terminal error, got %v", err)
+		}
+	})
+
+	t.Run("other errors", func(t *testing.T) {
+		err := p.Delete(newMachine("existing", endpoint), nil)
+		if err == nil {
+			t.Fatalf("expected error")
+		}
+		if isTerminal, _, _ := cloudprovidererrors.IsTerminalError(err); isTerminal {
+			t.Errorf("expected non terminal error, got %v", err)
+		}
+	})
+
+	t.Run("create applies machine updates", func(t *testing.T) {
+		machine := newMachine("new", endpoint)
+		var updated bool
+		updater := func(m *v1alpha1.Machine, modify func(*v1alpha1.Machine)) (*v1alpha1.Machine, error) {
+			modify(m)
+			updated = true
+			return m, nil
+		}
+		if _, err := p.Create(machine, updater, "userdata"); err != nil {
+			t.Fatalf("failed to create instance: %v", err)
+		}
+		if !updated {
+			t.Fatalf("expected the machine to be updated")
+		}
+		if !reflect.DeepEqual(machine.Finalizers, []string{testFinalizer}) {
+			t.Errorf("expected finalizer %s to be set, got %v", testFinalizer, machine.Finalizers)
+		}
+	})
+
+	t.Run("cloud config and labels", func(t *testing.T) {
+		machine := newMachine("existing", endpoint)
+		config, name, err := p.GetCloudConfig(machine.Spec)
+		if err != nil {
+			t.Fatalf("failed to get cloud config: %v", err)
+		}
+		if config != "config" || name != "test" {
+			t.Errorf("got unexpected cloud config %q with name %q", config, name)
+		}
+		labels, err := p.MachineMetricsLabels(machine)
+		if err != nil {
+			t.Fatalf("failed to get labels: %v", err)
+		}
+		if !reflect.DeepEqual(labels, map[string]string{"size": "small"}) {
+			t.Errorf("got unexpected labels %v", labels)
+		}
+	})
+}
@@ -0,0 +1,200 @@
+package plugin
+
+import (
+	"encoding/json"
+	"net"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/kubermatic/machine-controller/pkg/cloudprovider/cloud"
+	"github.com/kubermatic/machine-controller/pkg/cloudprovider/instance"
+	pluginapi "github.com/kubermatic/machine-controller/pkg/cloudprovider/provider/plugin/api/v1alpha1"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+)
+
+// Serve serves the given cloud.Provider as plugin on the listener.
+// It blocks until the listener gets closed.
+func Serve(l net.Listener, provider cloud.Provider) error {
+	s := grpc.NewServer()
+	pluginapi.RegisterProviderServer(s, NewServer(provider))
+	return s.Serve(l)
+}
+
+// NewServer returns a plugin server which forwards all calls to the given cloud.Provider.
+// It can be used to implement a plugin with the same interface as the in-tree providers.
+func NewServer(provider cloud.Provider) pluginapi.ProviderServer {
+	return &server{provider: provider}
+}
+
+type server struct {
+	provider cloud.Provider
+}
+
+// machineRecorder is handed to the provider as cloud.MachineUpdater.
+// The plugin has no access to the api, so it only records the changes
+// which then get applied by the machine-controller.
+type machineRecorder struct {
+	machine *v1alpha1.Machine
+}
+
+func (r *machineRecorder) update(machine *v1alpha1.Machine, modify func(*v1alpha1.Machine)) (*v1alpha1.Machine, error) {
+	modify(machine)
+	r.machine = machine
+	return machine, nil
+}
+
+func (r *machineRecorder) marshal() ([]byte, error) {
+	if r.machine == nil {
+		return nil, nil
+	}
+	return json.Marshal(r.machine)
+}
+
+func unmarshalMachine(raw []byte) (*v1alpha1.Machine, error) {
+	machine := &v1alpha1.Machine{}
+	if err := json.Unmarshal(raw, machine); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to unmarshal machine: %v", err)
+	}
+	return machine, nil
+}
+
+func unmarshalMachineSpec(raw []byte) (v1alpha1.MachineSpec, error) {
+	spec := v1alpha1.MachineSpec{}
+	if err := json.Unmarshal(raw, &spec); err != nil {
+		return spec, status.Errorf(codes.InvalidArgument, "failed to unmarshal machine spec: %v", err)
+	}
+	return spec, nil
+}
+
+func toPluginInstance(i instance.Instance) *pluginapi.Instance {
+	return &pluginapi.Instance{
+		Name:      i.Name(),
+		Id:        i.ID(),
+		Addresses: i.Addresses(),
+		Status:    string(i.Status()),
+	}
+}
+
+func (s *server) AddDefaults(_ context.Context, req *pluginapi.AddDefaultsRequest) (*pluginapi.AddDefaultsResponse, error) {
+	spec, err := unmarshalMachineSpec(req.MachineSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	spec, changed, err := s.provider.AddDefaults(spec)
+	if err != nil {
+		return nil, errorToStatus(err)
+	}
+
+	rawSpec, err := json.Marshal(spec)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to marshal machine spec: %v", err)
+	}
+	return &pluginapi.AddDefaultsResponse{MachineSpec: rawSpec, Changed: changed}, nil
+}
+
+func (s *server) Validate(_ context.Context, req *pluginapi.ValidateRequest) (*pluginapi.ValidateResponse, error) {
+	spec, err := unmarshalMachineSpec(req.MachineSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.provider.Validate(spec); err != nil {
+		return nil, errorToStatus(err)
+	}
+	return &pluginapi.ValidateResponse{}, nil
+}
+
+func (s *server) Get(_ context.Context, req *pluginapi.GetRequest) (*pluginapi.GetResponse, error) {
+	machine, err := unmarshalMachine(req.Machine)
+	if err != nil {
+		return nil, err
+	}
+
+	i, err := s.provider.Get(machine)
+	if err != nil {
+		return nil, errorToStatus(err)
+	}
+	return &pluginapi.GetResponse{Instance: toPluginInstance(i)}, nil
+}
+
+func (s *server) GetCloudConfig(_ context.Context, req *pluginapi.GetCloudConfigRequest) (*pluginapi.GetCloudConfigResponse, error) {
+	spec, err := unmarshalMachineSpec(req.MachineSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	config, name, err := s.provider.GetCloudConfig(spec)
+	if err != nil {
+		return nil, errorToStatus(err)
+	}
+	return &pluginapi.GetCloudConfigResponse{Config: config, Name: name}, nil
+}
+
+func (s *server) Create(_ context.Context, req *pluginapi.CreateRequest) (*pluginapi.CreateResponse, error) {
+	machine, err := unmarshalMachine(req.Machine)
+	if err != nil {
+		return nil, err
+	}
+
+	recorder := &machineRecorder{}
+	i, err := s.provider.Create(machine, recorder.update, req.Userdata)
+	if err != nil {
+		return nil, errorToStatus(err)
+	}
+
+	rawMachine, err := recorder.marshal()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to marshal machine: %v", err)
+	}
+	return &pluginapi.CreateResponse{Instance: toPluginInstance(i), UpdatedMachine: rawMachine}, nil
+}
+
+func (s *server) Delete(_ context.Context, req *pluginapi.DeleteRequest) (*pluginapi.DeleteResponse, error) {
+	machine, err := unmarshalMachine(req.Machine)
+	if err != nil {
+		return nil, err
+	}
+
+	recorder := &machineRecorder{}
+	if err := s.provider.Delete(machine, recorder.update); err != nil {
+		return nil, errorToStatus(err)
+	}
+
+	rawMachine, err := recorder.marshal()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to marshal machine: %v", err)
+	}
+	return &pluginapi.DeleteResponse{UpdatedMachine: rawMachine}, nil
+}
+
+func (s *server) MachineMetricsLabels(_ context.Context, req *pluginapi.MachineMetricsLabelsRequest) (*pluginapi.MachineMetricsLabelsResponse, error) {
+	machine, err := unmarshalMachine(req.Machine)
+	if err != nil {
+		return nil, err
+	}
+
+	labels, err := s.provider.MachineMetricsLabels(machine)
+	if err != nil {
+		return nil, errorToStatus(err)
+	}
+	return &pluginapi.MachineMetricsLabelsResponse{Labels: labels}, nil
+}
+
+func (s *server) MigrateUID(_ context.Context, req *pluginapi.MigrateUIDRequest) (*pluginapi.MigrateUIDResponse, error) {
+	machine, err := unmarshalMachine(req.Machine)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.provider.MigrateUID(machine, types.UID(req.NewUid)); err != nil {
+		return nil, errorToStatus(err)
+	}
+	return &pluginapi.MigrateUIDResponse{}, nil
+}
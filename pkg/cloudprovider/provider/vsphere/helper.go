@@ -13,6 +13,8 @@ import (
 
 	"github.com/golang/glog"
 
+	"github.com/kubermatic/machine-controller/pkg/providerconfig"
+
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 
 	"github.com/vmware/govmomi"
@@ -33,7 +35,7 @@ const (
 
 var errSnapshotNotFound = errors.New("no snapshot with given name found")
 
-func createClonedVM(ctx context.Context, vmName string, config *Config, dc *object.Datacenter, f *find.Finder, containerLinuxUserdata string) (*object.VirtualMachine, error) {
+func createClonedVM(ctx context.Context, vmName string, config *Config, dc *object.Datacenter, f *find.Finder, containerLinuxUserdata string, guestInfo []types.BaseOptionValue) (*object.VirtualMachine, error) {
 	templateVM, err := f.VirtualMachine(ctx, config.TemplateVMName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get template vm: %v", err)
@@ -128,9 +130,10 @@ func createClonedVM(ctx context.Context, vmName string, config *Config, dc *obje
 		Flags: &types.VirtualMachineFlagInfo{
 			DiskUuidEnabled: &diskUUIDEnabled,
 		},
-		NumCPUs:    config.CPUs,
-		MemoryMB:   config.MemoryMB,
-		VAppConfig: vAppAconfig,
+		NumCPUs:     config.CPUs,
+		MemoryMB:    config.MemoryMB,
+		VAppConfig:  vAppAconfig,
+		ExtraConfig: guestInfo,
 	}
 
 	// Create a cloned VM from the template VM's snapshot
@@ -335,6 +338,50 @@ func getDatacenterFinder(datacenter string, client *govmomi.Client) (*find.Finde
 	return finder, nil
 }
 
+func renderMetadata(name string) ([]byte, error) {
+	metadataTmpl, err := template.New("metadata").Parse(metaDataTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse metadata template: %v", err)
+	}
+	metadata := &bytes.Buffer{}
+	templateContext := struct {
+		InstanceID string
+		Hostname   string
+	}{
+		InstanceID: name,
+		Hostname:   name,
+	}
+	if err = metadataTmpl.Execute(metadata, templateContext); err != nil {
+		return nil, fmt.Errorf("failed to render metadata: %v", err)
+	}
+	return metadata.Bytes(), nil
+}
+
+// getGuestInfoUserdata returns the userdata as guestinfo extra config of the VM.
+// Ignition reads the Container Linux userdata from it, the VMware datasource of cloud-init everything else.
+func getGuestInfoUserdata(userdata, name string, operatingSystem providerconfig.OperatingSystem) ([]types.BaseOptionValue, error) {
+	userdataBase64 := base64.StdEncoding.EncodeToString([]byte(userdata))
+	if operatingSystem == providerconfig.OperatingSystemCoreos {
+		return []types.BaseOptionValue{
+			&types.OptionValue{Key: "guestinfo.coreos.config.data", Value: userdataBase64},
+			&types.OptionValue{Key: "guestinfo.coreos.config.data.encoding", Value: "base64"},
+			&types.OptionValue{Key: "guestinfo.ignition.config.data", Value: userdataBase64},
+			&types.OptionValue{Key: "guestinfo.ignition.config.data.encoding", Value: "base64"},
+		}, nil
+	}
+
+	metadata, err := renderMetadata(name)
+	if err != nil {
+		return nil, err
+	}
+	return []types.BaseOptionValue{
+		&types.OptionValue{Key: "guestinfo.userdata", Value: userdataBase64},
+		&types.OptionValue{Key: "guestinfo.userdata.encoding", Value: "base64"},
+		&types.OptionValue{Key: "guestinfo.metadata", Value: base64.StdEncoding.EncodeToString(metadata)},
+		&types.OptionValue{Key: "guestinfo.metadata.encoding", Value: "base64"},
+	}, nil
+}
+
 func generateLocalUserdataISO(userdata, name string) (string, error) {
 	// We must create a directory, because the iso-generation commands
 	// take a directory as input
@@ -352,27 +399,16 @@ func generateLocalUserdataISO(userdata, name string) (string, error) {
 	metadataFilePath := fmt.Sprintf("%s/meta-data", userdataDir)
 	isoFilePath := fmt.Sprintf("%s/%s.iso", localTempDir, name)
 
-	metadataTmpl, err := template.New("metadata").Parse(metaDataTemplate)
+	metadata, err := renderMetadata(name)
 	if err != nil {
-		return "", fmt.Errorf("failed to parse metadata template: %v", err)
-	}
-	metadata := &bytes.Buffer{}
-	templateContext := struct {
-		InstanceID string
-		Hostname   string
-	}{
-		InstanceID: name,
-		Hostname:   name,
-	}
-	if err = metadataTmpl.Execute(metadata, templateContext); err != nil {
-		return "", fmt.Errorf("failed to render metadata: %v", err)
+		return "", err
 	}
 
 	if err := ioutil.WriteFile(userdataFilePath, []byte(userdata), 0644); err != nil {
 		return "", fmt.Errorf("failed to locally write userdata file to %s: %v", userdataFilePath, err)
 	}
 
-	if err := ioutil.WriteFile(metadataFilePath, metadata.Bytes(), 0644); err != nil {
+	if err := ioutil.WriteFile(metadataFilePath, metadata, 0644); err != nil {
 		return "", fmt.Errorf("failed to locally write metadata file to %s: %v", userdataFilePath, err)
 	}
 
@@ -58,6 +58,9 @@ type RawConfig struct {
 	CPUs            int32                          `json:"cpus"`
 	MemoryMB        int64                          `json:"memoryMB"`
 	AllowInsecure   providerconfig.ConfigVarBool   `json:"allowInsecure"`
+
+	// GuestInfoUserdata passes the userdata via the guestinfo extra config of the VM instead of an ISO
+	GuestInfoUserdata providerconfig.ConfigVarBool `json:"guestInfoUserdata"`
 }
 
 type Config struct {
@@ -74,6 +77,8 @@ type Config struct {
 	AllowInsecure   bool
 	CPUs            int32
 	MemoryMB        int64
+
+	GuestInfoUserdata bool
 }
 
 type Server struct {
@@ -260,6 +265,11 @@ func (p *provider) getConfig(s v1alpha1.ProviderConfig) (*Config, *providerconfi
 		return nil, nil, nil, err
 	}
 
+	c.GuestInfoUserdata, err = p.configVarResolver.GetConfigVarBoolValue(rawConfig.GuestInfoUserdata)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
 	c.CPUs = rawConfig.CPUs
 	c.MemoryMB = rawConfig.MemoryMB
 
@@ -331,7 +341,13 @@ func (p *provider) Create(machine *v1alpha1.Machine, _ cloud.MachineUpdater, use
 	}()
 
 	var containerLinuxUserdata string
-	if pc.OperatingSystem == providerconfig.OperatingSystemCoreos {
+	var guestInfo []types.BaseOptionValue
+	if config.GuestInfoUserdata {
+		guestInfo, err = getGuestInfoUserdata(userdata, machine.Spec.Name, pc.OperatingSystem)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get guestinfo userdata: %v", err)
+		}
+	} else if pc.OperatingSystem == providerconfig.OperatingSystemCoreos {
 		containerLinuxUserdata = userdata
 	}
 
@@ -347,12 +363,13 @@ func (p *provider) Create(machine *v1alpha1.Machine, _ cloud.MachineUpdater, use
 		config,
 		dc,
 		finder,
-		containerLinuxUserdata)
+		containerLinuxUserdata,
+		guestInfo)
 	if err != nil {
 		return nil, machineInvalidConfigurationTerminalError(fmt.Errorf("failed to create cloned vm: '%v'", err))
 	}
 
-	if pc.OperatingSystem != providerconfig.OperatingSystemCoreos {
+	if pc.OperatingSystem != providerconfig.OperatingSystemCoreos && !config.GuestInfoUserdata {
 		localUserdataIsoFilePath, err := generateLocalUserdataISO(userdata, machine.Spec.Name)
 		if err != nil {
 			return nil, fmt.Errorf("failed to generate local userdadata iso: %v", err)
@@ -446,7 +463,7 @@ func (p *provider) Delete(machine *v1alpha1.Machine, _ cloud.MachineUpdater) err
 		return fmt.Errorf("failed to destroy vm %s: %v", virtualMachine.Name(), err)
 	}
 
-	if pc.OperatingSystem != providerconfig.OperatingSystemCoreos {
+	if pc.OperatingSystem != providerconfig.OperatingSystemCoreos && !config.GuestInfoUserdata {
 		datastore, err := finder.Datastore(context.TODO(), config.Datastore)
 		if err != nil {
 			return fmt.Errorf("failed to get datastore %s: %v", config.Datastore, err)
@@ -397,6 +397,16 @@ func (c *Controller) syncHandler(key string) error {
 	}
 
 	if c.nodeIsReady(node) {
+		// Instances can get interrupted while their node is still ready, e.g. reclaimed spot instances.
+		// The node gets drained right away instead of waiting for it to become NotReady.
+		interrupted, err := c.instanceIsInterrupted(prov, machine)
+		if err != nil {
+			return err
+		}
+		if interrupted {
+			return c.replaceInterruptedMachine(machine)
+		}
+
 		// We must do this to ensure the informers in the machineSet and machineDeployment controller
 		// get triggered as soon as a ready node exists for a machine
 		if machine, err = c.ensureMachineHasNodeReadyCondition(machine); err != nil {
@@ -549,7 +559,11 @@ func (c *Controller) ensureInstanceExistsForMachine(prov cloud.Provider, machine
 				return fmt.Errorf("failed to create bootstrap kubeconfig: %v", err)
 			}
 
-			userdata, err := userdataProvider.UserData(machine.Spec, kubeconfig, prov, c.clusterDNSIPs)
+			ccProvider := prov
+			if machineConfigProvider, ok := prov.(cloud.MachineConfigProvider); ok {
+				ccProvider = machineConfigProvider.ForMachine(machine)
+			}
+			userdata, err := userdataProvider.UserData(machine.Spec, kubeconfig, ccProvider, c.clusterDNSIPs)
 			if err != nil {
 				c.recorder.Eventf(machine, corev1.EventTypeWarning, "UserdataRenderingFailed", "Userdata rendering failed: %v", err)
 				return fmt.Errorf("failed get userdata: %v", err)
@@ -581,6 +595,10 @@ func (c *Controller) ensureInstanceExistsForMachine(prov cloud.Provider, machine
 		return err
 	}
 
+	if providerInstance.Status() == instance.StatusInterrupted {
+		return c.replaceInterruptedMachine(machine)
+	}
+
 	// case 3: retrieving the instance from cloudprovider was successfull
 	// Emit an event and update .Status.Addresses
 	addresses := providerInstance.Addresses()
@@ -599,6 +617,47 @@ func (c *Controller) ensureInstanceExistsForMachine(prov cloud.Provider, machine
 	return c.ensureNodeOwnerRefAndConfigSource(providerInstance, machine, providerConfig)
 }
 
+// instanceIsInterrupted returns if the instance of the machine got interrupted by the cloud provider.
+// A missing instance is not treated as interruption, it gets recreated once the node is NotReady.
+func (c *Controller) instanceIsInterrupted(prov cloud.Provider, machine *clusterv1alpha1.Machine) (bool, error) {
+	providerInstance, err := prov.Get(machine)
+	if err != nil {
+		if err == cloudprovidererrors.ErrInstanceNotFound {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get instance from provider: %v", err)
+	}
+	return providerInstance.Status() == instance.StatusInterrupted, nil
+}
+
+// replaceInterruptedMachine drains the node of a machine whose instance got interrupted by the cloud provider.
+// Machines owned by a MachineSet get deleted afterwards, so the MachineSet creates a replacement.
+func (c *Controller) replaceInterruptedMachine(machine *clusterv1alpha1.Machine) error {
+	c.recorder.Event(machine, corev1.EventTypeWarning, "InstanceInterrupted", "Instance got interrupted by the cloud provider")
+
+	if machine.Status.NodeRef != nil {
+		if _, err := c.nodesLister.Get(machine.Status.NodeRef.Name); err != nil {
+			if !kerrors.IsNotFound(err) {
+				return fmt.Errorf("failed to get node %s for machine %s/%s: %v", machine.Status.NodeRef.Name, machine.Namespace, machine.Name, err)
+			}
+		} else if err := eviction.New(machine.Status.NodeRef.Name, c.nodesLister, c.kubeClient).Run(); err != nil {
+			return fmt.Errorf("failed to evict node %s: %v", machine.Status.NodeRef.Name, err)
+		}
+	}
+
+	if metav1.GetControllerOf(machine) == nil {
+		glog.V(2).Infof("Instance of machine %s got interrupted, not replacing it as it is not owned by a MachineSet", machine.Name)
+		return nil
+	}
+
+	glog.V(2).Infof("Instance of machine %s got interrupted, deleting the machine to get it replaced", machine.Name)
+	if err := c.machineClient.ClusterV1alpha1().Machines(machine.Namespace).Delete(machine.Name, &metav1.DeleteOptions{}); err != nil && !kerrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete interrupted machine %s: %v", machine.Name, err)
+	}
+	c.recorder.Event(machine, corev1.EventTypeNormal, "Replacing", "Deleted the machine to get it replaced")
+	return nil
+}
+
 func (c *Controller) ensureNodeOwnerRefAndConfigSource(providerInstance instance.Instance, machine *clusterv1alpha1.Machine, providerConfig *providerconfig.Config) error {
 	node, exists, err := c.getNode(providerInstance, providerConfig.CloudProvider)
 	if err != nil {
@@ -0,0 +1,6 @@
+// +k8s:deepcopy-gen=package,register
+
+// Package v1alpha1 contains the types of the static host pool
+// which gets used by the hostpool cloud provider.
+// +groupName=hostpool.kubermatic.io
+package v1alpha1
@@ -0,0 +1,95 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const HostResourcePlural = "hosts"
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// Host is a pre-existing server which can be claimed by a Machine.
+// The labels of the Host are used to select it for a Machine.
+type Host struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+
+	Spec   HostSpec   `json:"spec"`
+	Status HostStatus `json:"status,omitempty"`
+}
+
+type HostSpec struct {
+	// Address is the IP address or DNS name under which the host is reachable via SSH.
+	// It should match one of the addresses of the resulting Node.
+	Address string `json:"address"`
+
+	// SSH contains the credentials used to provision and wipe the host
+	SSH SSHSpec `json:"ssh"`
+}
+
+type SSHSpec struct {
+	// Port of the SSH server, defaults to 22
+	// +optional
+	Port int `json:"port,omitempty"`
+
+	// User to log in with. When the user is not root, commands get executed via sudo.
+	User string `json:"user"`
+
+	// PrivateKeySecretRef references the secret key containing the PEM encoded private key
+	PrivateKeySecretRef SecretKeyReference `json:"privateKeySecretRef"`
+
+	// HostPublicKey is the public key of the host in authorized_keys format.
+	// It is required unless InsecureSkipHostKeyVerification is set.
+	// +optional
+	HostPublicKey string `json:"hostPublicKey,omitempty"`
+
+	// InsecureSkipHostKeyVerification disables the verification of the host key,
+	// which makes the connection vulnerable to man-in-the-middle attacks
+	// +optional
+	InsecureSkipHostKeyVerification bool `json:"insecureSkipHostKeyVerification,omitempty"`
+}
+
+type SecretKeyReference struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Key       string `json:"key"`
+}
+
+type HostPhase string
+
+const (
+	// HostPhaseAvailable means the host is not claimed and can be used for a Machine
+	HostPhaseAvailable HostPhase = ""
+	// HostPhaseProvisioning means the host got claimed and the userdata gets applied
+	HostPhaseProvisioning HostPhase = "Provisioning"
+	// HostPhaseProvisioned means the userdata got applied to the host
+	HostPhaseProvisioned HostPhase = "Provisioned"
+	// HostPhaseReleasing means the Machine got deleted and the host gets wiped
+	HostPhaseReleasing HostPhase = "Releasing"
+)
+
+type HostStatus struct {
+	// Phase of the host
+	// +optional
+	Phase HostPhase `json:"phase,omitempty"`
+
+	// MachineUID is the UID of the Machine which claimed the host.
+	// Empty when the host is available.
+	// +optional
+	MachineUID types.UID `json:"machineUID,omitempty"`
+
+	// MachineName is the name of the Machine which claimed the host
+	// +optional
+	MachineName string `json:"machineName,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type HostList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []Host `json:"items"`
+}
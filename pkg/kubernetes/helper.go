@@ -1,8 +1,22 @@
 package kubernetes
 
 import (
+	"strings"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/rand"
 	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+)
+
+const (
+	// machineTemplateHashLabel is set by the MachineDeployment controller on its MachineSets and their machines
+	machineTemplateHashLabel = "machine-template-hash"
+
+	// clusterTag and clusterTagPrefix are the cloud resource tags identifying the cluster
+	clusterTag       = "KubernetesCluster"
+	clusterTagPrefix = "kubernetes.io/cluster/"
 )
 
 // HasFinalizer tells if a object has the given finalizer
@@ -16,3 +30,58 @@ func RemoveFinalizer(finalizers []string, toRemove string) []string {
 	set.Delete(toRemove)
 	return set.List()
 }
+
+// MachineDeploymentName returns the name of the MachineDeployment the machine belongs to, or an empty string
+// if it doesn't belong to one. The MachineDeployment controller names its MachineSets after the
+// MachineDeployment and the encoded machine-template-hash, so no lookup is required.
+func MachineDeploymentName(machine *v1alpha1.Machine) string {
+	hash := machine.Labels[machineTemplateHashLabel]
+	if hash == "" {
+		return ""
+	}
+	suffix := "-" + rand.SafeEncodeString(hash)
+	for _, ownerRef := range machine.OwnerReferences {
+		if ownerRef.Kind == "MachineSet" && strings.HasSuffix(ownerRef.Name, suffix) {
+			return strings.TrimSuffix(ownerRef.Name, suffix)
+		}
+	}
+	return ""
+}
+
+// MachineDeploymentOwner returns the name of the MachineDeployment the machine belongs to.
+// Machines without a MachineDeployment are their own owner.
+func MachineDeploymentOwner(machine *v1alpha1.Machine) string {
+	if name := MachineDeploymentName(machine); name != "" {
+		return name
+	}
+	return machine.Spec.Name
+}
+
+// ClusterID returns the id of the cluster from the KubernetesCluster or kubernetes.io/cluster/<id> tag,
+// or an empty string if none of them is set
+func ClusterID(tags map[string]string) string {
+	if id := tags[clusterTag]; id != "" {
+		return id
+	}
+	ids := sets.NewString()
+	for k := range tags {
+		if strings.HasPrefix(k, clusterTagPrefix) {
+			ids.Insert(strings.TrimPrefix(k, clusterTagPrefix))
+		}
+	}
+	if ids.Len() == 0 {
+		return ""
+	}
+	return ids.List()[0]
+}
+
+// MachineDeploymentResourceName returns the name of a cloud resource shared by the machines of a MachineDeployment.
+// It contains the cluster and the namespace, as MachineDeployments of other clusters or namespaces can share the
+// same project. The cluster is left out if its id is empty.
+func MachineDeploymentResourceName(clusterID string, machine *v1alpha1.Machine, suffix string) string {
+	parts := []string{machine.Namespace, MachineDeploymentOwner(machine), suffix}
+	if clusterID != "" {
+		parts = append([]string{clusterID}, parts...)
+	}
+	return strings.Join(parts, "-")
+}
@@ -0,0 +1,144 @@
+package kubernetes
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/rand"
+
+	"sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+)
+
+func newMachine(hash string, ownerRefs ...metav1.OwnerReference) *v1alpha1.Machine {
+	machine := &v1alpha1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "machine",
+			Namespace:       "kube-system",
+			OwnerReferences: ownerRefs,
+		},
+		Spec: v1alpha1.MachineSpec{ObjectMeta: metav1.ObjectMeta{Name: "node"}},
+	}
+	if hash != "" {
+		machine.Labels = map[string]string{machineTemplateHashLabel: hash}
+	}
+	return machine
+}
+
+func TestMachineDeploymentName(t *testing.T) {
+	hash := "5f4rjps8mv"
+	encodedHash := rand.SafeEncodeString(hash)
+
+	tests := []struct {
+		name     string
+		machine  *v1alpha1.Machine
+		expected string
+	}{
+		{
+			name:     "machine of a MachineDeployment",
+			machine:  newMachine(hash, metav1.OwnerReference{Kind: "MachineSet", Name: "workers-" + encodedHash}),
+			expected: "workers",
+		},
+		{
+			name:     "MachineDeployment name containing dashes",
+			machine:  newMachine(hash, metav1.OwnerReference{Kind: "MachineSet", Name: "my-workers-" + encodedHash}),
+			expected: "my-workers",
+		},
+		{
+			name:     "standalone machine",
+			machine:  newMachine(""),
+			expected: "",
+		},
+		{
+			name:     "MachineSet without MachineDeployment",
+			machine:  newMachine("", metav1.OwnerReference{Kind: "MachineSet", Name: "workers"}),
+			expected: "",
+		},
+		{
+			name:     "MachineSet not named after the hash",
+			machine:  newMachine(hash, metav1.OwnerReference{Kind: "MachineSet", Name: "workers"}),
+			expected: "",
+		},
+		{
+			name:     "other owner",
+			machine:  newMachine(hash, metav1.OwnerReference{Kind: "ReplicaSet", Name: "workers-" + encodedHash}),
+			expected: "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if name := MachineDeploymentName(test.machine); name != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, name)
+			}
+		})
+	}
+}
+
+func TestClusterID(t *testing.T) {
+	tests := []struct {
+		name     string
+		tags     map[string]string
+		expected string
+	}{
+		{
+			name:     "no tags",
+			expected: "",
+		},
+		{
+			name:     "KubernetesCluster tag",
+			tags:     map[string]string{"KubernetesCluster": "my-cluster", "kubernetes.io/cluster/other": ""},
+			expected: "my-cluster",
+		},
+		{
+			name:     "kubernetes.io/cluster tag",
+			tags:     map[string]string{"kubernetes.io/cluster/my-cluster": "owned", "role": "worker"},
+			expected: "my-cluster",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if id := ClusterID(test.tags); id != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, id)
+			}
+		})
+	}
+}
+
+func TestMachineDeploymentResourceName(t *testing.T) {
+	hash := "5f4rjps8mv"
+	mdMachine := newMachine(hash, metav1.OwnerReference{Kind: "MachineSet", Name: "workers-" + rand.SafeEncodeString(hash)})
+
+	tests := []struct {
+		name      string
+		clusterID string
+		machine   *v1alpha1.Machine
+		expected  string
+	}{
+		{
+			name:      "machine of a MachineDeployment",
+			clusterID: "my-cluster",
+			machine:   mdMachine,
+			expected:  "my-cluster-kube-system-workers-group",
+		},
+		{
+			name:     "without cluster id",
+			machine:  mdMachine,
+			expected: "kube-system-workers-group",
+		},
+		{
+			name:      "standalone machine",
+			clusterID: "my-cluster",
+			machine:   newMachine(""),
+			expected:  "my-cluster-kube-system-node-group",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if name := MachineDeploymentResourceName(test.clusterID, test.machine, "group"); name != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, name)
+			}
+		})
+	}
+}
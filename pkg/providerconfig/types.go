@@ -31,6 +31,7 @@ const (
 type CloudProvider string
 
 const (
+	CloudProviderAlibaba      CloudProvider = "alibaba"
 	CloudProviderAWS          CloudProvider = "aws"
 	CloudProviderAzure        CloudProvider = "azure"
 	CloudProviderDigitalocean CloudProvider = "digitalocean"
@@ -38,6 +39,8 @@ const (
 	CloudProviderHetzner      CloudProvider = "hetzner"
 	CloudProviderVsphere      CloudProvider = "vsphere"
 	CloudProviderFake         CloudProvider = "fake"
+	CloudProviderPlugin       CloudProvider = "plugin"
+	CloudProviderHostPool     CloudProvider = "hostpool"
 )
 
 // DNSConfig contains a machine's DNS configuration
@@ -94,6 +94,21 @@ func (p Provider) UserData(
 		return "", fmt.Errorf("error extracting cacert: %v", err)
 	}
 
+	dataDisks, err := userdatahelper.GetDataDisks(ccProvider, spec)
+	if err != nil {
+		return "", err
+	}
+
+	cloudConfigFiles, err := userdatahelper.GetCloudConfigFiles(ccProvider, spec)
+	if err != nil {
+		return "", err
+	}
+
+	providerIDScript, err := userdatahelper.GetProviderIDScript(ccProvider, spec)
+	if err != nil {
+		return "", err
+	}
+
 	data := struct {
 		MachineSpec      clusterv1alpha1.MachineSpec
 		ProviderConfig   *providerconfig.Config
@@ -105,6 +120,9 @@ func (p Provider) UserData(
 		ServerAddr       string
 		Kubeconfig       string
 		KubernetesCACert string
+		DataDisks        []cloud.DataDisk
+		CloudConfigFiles []cloud.CloudConfigFile
+		ProviderIDScript string
 	}{
 		MachineSpec:      spec,
 		ProviderConfig:   pconfig,
@@ -116,6 +134,9 @@ func (p Provider) UserData(
 		ServerAddr:       serverAddr,
 		Kubeconfig:       kubeconfigString,
 		KubernetesCACert: kubernetesCACert,
+		DataDisks:        dataDisks,
+		CloudConfigFiles: cloudConfigFiles,
+		ProviderIDScript: providerIDScript,
 	}
 	b := &bytes.Buffer{}
 	err = tmpl.Execute(b, data)
@@ -144,6 +165,20 @@ ssh_authorized_keys:
   - "{{ . }}"
 {{- end }}
 {{- end }}
+{{- if .DataDisks }}
+
+fs_setup:
+{{- range .DataDisks }}
+- device: "{{ .Device }}"
+  filesystem: "{{ .Filesystem }}"
+  partition: none
+{{- end }}
+
+mounts:
+{{- range .DataDisks }}
+- [ "{{ .Device }}", "{{ .MountPath }}", "{{ .Filesystem }}", "defaults,nofail", "0", "2" ]
+{{- end }}
+{{- end }}
 
 write_files:
 - path: "/etc/systemd/journald.conf.d/max_disk_use.conf"
@@ -223,7 +258,18 @@ write_files:
 
 - path: "/etc/systemd/system/kubelet.service"
   content: |
-{{ kubeletSystemdUnit .KubeletVersion .CloudProvider .MachineSpec.Name .ClusterDNSIPs | indent 4 }}
+{{ kubeletSystemdUnit .KubeletVersion .CloudProvider .MachineSpec.Name .ClusterDNSIPs (ne .ProviderIDScript "") | indent 4 }}
+{{- if .ProviderIDScript }}
+
+- path: "/opt/bin/kubelet-provider-id"
+  permissions: "0755"
+  content: |
+{{ .ProviderIDScript | indent 4 }}
+
+- path: "/etc/systemd/system/kubelet-provider-id.service"
+  content: |
+{{ kubeletProviderIDSystemdUnit | indent 4 }}
+{{- end }}
 
 - path: "/etc/systemd/system/kubelet.service.d/extras.conf"
   content: |
@@ -233,6 +279,13 @@ write_files:
 - path: "/etc/kubernetes/cloud-config"
   content: |
 {{ .CloudConfig | indent 4 }}
+{{- range .CloudConfigFiles }}
+
+- path: "{{ .Path }}"
+  permissions: "0400"
+  encoding: b64
+  content: {{ .Content | b64enc }}
+{{- end }}
 
 - path: "/etc/kubernetes/bootstrap-kubelet.conf"
   content: |
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	testhelper "github.com/kubermatic/machine-controller/pkg/test"
+	"github.com/kubermatic/machine-controller/pkg/userdata/cloud"
 
 	"k8s.io/apimachinery/pkg/runtime"
 
@@ -43,15 +44,31 @@ kPe6XoSbiLm/kxk32T0=
 -----END CERTIFICATE-----`
 
 type fakeCloudConfigProvider struct {
-	config string
-	name   string
-	err    error
+	config    string
+	name      string
+	err       error
+	dataDisks []cloud.DataDisk
+	files     []cloud.CloudConfigFile
+
+	providerIDScript string
 }
 
 func (p *fakeCloudConfigProvider) GetCloudConfig(spec clusterv1alpha1.MachineSpec) (config string, name string, err error) {
 	return p.config, p.name, p.err
 }
 
+func (p *fakeCloudConfigProvider) GetDataDisks(spec clusterv1alpha1.MachineSpec) ([]cloud.DataDisk, error) {
+	return p.dataDisks, nil
+}
+
+func (p *fakeCloudConfigProvider) GetCloudConfigFiles(spec clusterv1alpha1.MachineSpec) ([]cloud.CloudConfigFile, error) {
+	return p.files, nil
+}
+
+func (p *fakeCloudConfigProvider) GetProviderIDScript(spec clusterv1alpha1.MachineSpec) (string, error) {
+	return p.providerIDScript, nil
+}
+
 var update = flag.Bool("update", false, "update .golden files")
 
 func TestUserDataGeneration(t *testing.T) {
@@ -62,6 +79,9 @@ func TestUserDataGeneration(t *testing.T) {
 		spec              clusterv1alpha1.MachineSpec
 		clusterDNSIPs     []net.IP
 		cloudProviderName *string
+		dataDisks         []cloud.DataDisk
+		cloudConfigFiles  []cloud.CloudConfigFile
+		providerIDScript  string
 	}{
 		{
 			name: "kubelet-v1.9-aws",
@@ -109,6 +129,43 @@ func TestUserDataGeneration(t *testing.T) {
 			},
 			cloudProviderName: stringPtr("vsphere"),
 		},
+		{
+			name: "kubelet-v1.12-aws-data-disks",
+			spec: clusterv1alpha1.MachineSpec{
+				ObjectMeta: metav1.ObjectMeta{Name: "node1"},
+				Versions: clusterv1alpha1.MachineVersionInfo{
+					Kubelet: "1.12.0",
+				},
+			},
+			dataDisks: []cloud.DataDisk{
+				{Device: "/dev/xvdf", Filesystem: "ext4", MountPath: "/var/lib/docker"},
+				{Device: "/dev/xvdg", Filesystem: "xfs", MountPath: "/mnt/data-1"},
+			},
+		},
+		{
+			name: "kubelet-v1.12-azure-client-certificate",
+			spec: clusterv1alpha1.MachineSpec{
+				ObjectMeta: metav1.ObjectMeta{Name: "node1"},
+				Versions: clusterv1alpha1.MachineVersionInfo{
+					Kubelet: "1.12.0",
+				},
+			},
+			cloudProviderName: stringPtr("azure"),
+			cloudConfigFiles: []cloud.CloudConfigFile{
+				{Path: "/etc/kubernetes/azure-client-certificate.pfx", Content: "\x30\x82pfx"},
+			},
+		},
+		{
+			name: "kubelet-v1.12-provider-id",
+			spec: clusterv1alpha1.MachineSpec{
+				ObjectMeta: metav1.ObjectMeta{Name: "node1"},
+				Versions: clusterv1alpha1.MachineVersionInfo{
+					Kubelet: "1.12.0",
+				},
+			},
+			cloudProviderName: stringPtr("external"),
+			providerIDScript:  "#!/bin/bash\necho provider-id\n",
+		},
 	}
 
 	defaultCloudProvider := &fakeCloudConfigProvider{name: "aws", config: "{aws-config:true}", err: nil}
@@ -127,6 +184,15 @@ func TestUserDataGeneration(t *testing.T) {
 		} else {
 			cloudProvider = defaultCloudProvider
 		}
+		if test.dataDisks != nil {
+			cloudProvider = &fakeCloudConfigProvider{name: cloudProvider.name, config: cloudProvider.config, dataDisks: test.dataDisks}
+		}
+		if test.cloudConfigFiles != nil {
+			cloudProvider = &fakeCloudConfigProvider{name: cloudProvider.name, config: cloudProvider.config, files: test.cloudConfigFiles}
+		}
+		if test.providerIDScript != "" {
+			cloudProvider = &fakeCloudConfigProvider{name: cloudProvider.name, config: cloudProvider.config, providerIDScript: test.providerIDScript}
+		}
 
 		userdata, err := provider.UserData(test.spec, kubeconfig, cloudProvider, test.clusterDNSIPs)
 		if err != nil {
@@ -7,3 +7,38 @@ import (
 type ConfigProvider interface {
 	GetCloudConfig(spec clusterv1alpha1.MachineSpec) (config string, name string, err error)
 }
+
+// DataDisk is an additional disk of the machine which gets formatted and mounted on boot
+type DataDisk struct {
+	// Device is the path of the block device, e.g. /dev/xvdf
+	Device string
+	// Filesystem the disk gets formatted with, e.g. ext4
+	Filesystem string
+	// MountPath is the directory the disk gets mounted to, e.g. /var/lib/docker
+	MountPath string
+}
+
+// DataDiskProvider is implemented by cloud providers which attach additional disks to the machine
+type DataDiskProvider interface {
+	GetDataDisks(spec clusterv1alpha1.MachineSpec) ([]DataDisk, error)
+}
+
+// CloudConfigFile is a file the cloud config refers to, e.g. a client certificate
+type CloudConfigFile struct {
+	// Path of the file on the machine
+	Path string
+	// Content of the file, it can be binary
+	Content string
+}
+
+// CloudConfigFileProvider is implemented by cloud providers whose cloud config refers to additional files
+type CloudConfigFileProvider interface {
+	GetCloudConfigFiles(spec clusterv1alpha1.MachineSpec) ([]CloudConfigFile, error)
+}
+
+// ProviderIDProvider is implemented by cloud providers whose cloud-controller-manager requires the kubelet to set the
+// provider id of the node. The id isn't known before the instance got created, so it gets determined on the machine
+// by a script which prints it.
+type ProviderIDProvider interface {
+	GetProviderIDScript(spec clusterv1alpha1.MachineSpec) (string, error)
+}
@@ -86,6 +86,21 @@ func (p Provider) UserData(
 		return "", fmt.Errorf("error extracting cacert: %v", err)
 	}
 
+	dataDisks, err := userdatahelper.GetDataDisks(ccProvider, spec)
+	if err != nil {
+		return "", err
+	}
+
+	cloudConfigFiles, err := userdatahelper.GetCloudConfigFiles(ccProvider, spec)
+	if err != nil {
+		return "", err
+	}
+
+	providerIDScript, err := userdatahelper.GetProviderIDScript(ccProvider, spec)
+	if err != nil {
+		return "", err
+	}
+
 	data := struct {
 		MachineSpec       clusterv1alpha1.MachineSpec
 		ProviderConfig    *providerconfig.Config
@@ -97,6 +112,9 @@ func (p Provider) UserData(
 		ClusterDNSIPs     []net.IP
 		KubernetesCACert  string
 		KubeletVersion    string
+		DataDisks         []cloud.DataDisk
+		CloudConfigFiles  []cloud.CloudConfigFile
+		ProviderIDScript  string
 	}{
 		MachineSpec:       spec,
 		ProviderConfig:    pconfig,
@@ -108,6 +126,9 @@ func (p Provider) UserData(
 		ClusterDNSIPs:     clusterDNSIPs,
 		KubernetesCACert:  kubernetesCACert,
 		KubeletVersion:    kubeletVersion.String(),
+		DataDisks:         dataDisks,
+		CloudConfigFiles:  cloudConfigFiles,
+		ProviderIDScript:  providerIDScript,
 	}
 	b := &bytes.Buffer{}
 	err = tmpl.Execute(b, data)
@@ -171,6 +192,21 @@ systemd:
 {{ end }}
     - name: docker.service
       enabled: true
+{{- range .DataDisks }}
+
+    - name: {{ systemdMountUnitName .MountPath }}
+      enabled: true
+      contents: |
+        [Unit]
+        Before=local-fs.target
+        [Mount]
+        What={{ .Device }}
+        Where={{ .MountPath }}
+        Type={{ .Filesystem }}
+        Options=defaults,nofail
+        [Install]
+        WantedBy=local-fs.target
+{{- end }}
 
     - name: download-healthcheck-script.service
       enabled: true
@@ -206,6 +242,13 @@ systemd:
       contents: |
 {{ kubeletHealthCheckSystemdUnit | indent 10 }}
 
+{{- if .ProviderIDScript }}
+
+    - name: kubelet-provider-id.service
+      contents: |
+{{ kubeletProviderIDSystemdUnit | indent 8 }}
+{{- end }}
+
     - name: kubelet.service
       enabled: true
       contents: |
@@ -213,8 +256,15 @@ systemd:
         Description=Kubernetes Kubelet
         Requires=docker.service
         After=docker.service
+{{- if .ProviderIDScript }}
+        Requires=kubelet-provider-id.service
+        After=kubelet-provider-id.service
+{{- end }}
         [Service]
         TimeoutStartSec=5min
+{{- if .ProviderIDScript }}
+        EnvironmentFile=/etc/kubernetes/kubelet-provider-id.env
+{{- end }}
         Environment=KUBELET_IMAGE=docker://k8s.gcr.io/hyperkube-amd64:{{ .HyperkubeImageTag }}
         Environment="RKT_RUN_ARGS=--uuid-file-save=/var/cache/kubelet-pod.uuid \
           --insecure-options=image \
@@ -237,7 +287,7 @@ systemd:
         ExecStartPre=-/usr/bin/rkt rm --uuid-file=/var/cache/kubelet-pod.uuid
         ExecStartPre=-/bin/rm -rf /var/lib/rkt/cas/tmp/
         ExecStart=/usr/lib/coreos/kubelet-wrapper \
-{{ kubeletFlags .KubeletVersion .CloudProvider .MachineSpec.Name .ClusterDNSIPs | indent 10 }}
+{{ kubeletFlags .KubeletVersion .CloudProvider .MachineSpec.Name .ClusterDNSIPs (ne .ProviderIDScript "") | indent 10 }}
         ExecStop=-/usr/bin/rkt stop --uuid-file=/var/cache/kubelet-pod.uuid
         Restart=always
         RestartSec=10
@@ -245,6 +295,16 @@ systemd:
         WantedBy=multi-user.target
 
 storage:
+{{- if .DataDisks }}
+  filesystems:
+{{- range $i, $disk := .DataDisks }}
+    - name: data-{{ $i }}
+      mount:
+        device: {{ $disk.Device }}
+        format: {{ $disk.Filesystem }}
+        wipe_filesystem: false
+{{- end }}
+{{- end }}
   files:
     - path: "/etc/systemd/journald.conf.d/max_disk_use.conf"
       filesystem: root
@@ -301,6 +361,15 @@ storage:
       contents:
         inline: |
 {{ .CloudConfig | indent 10 }}
+{{- range .CloudConfigFiles }}
+
+    - path: {{ .Path }}
+      filesystem: root
+      mode: 0400
+      contents:
+        remote:
+          url: "data:;base64,{{ .Content | b64enc }}"
+{{- end }}
 
     - path: /etc/kubernetes/pki/ca.crt
       filesystem: root
@@ -361,4 +430,13 @@ storage:
           #!/bin/bash
           set -xeuo pipefail
 {{ downloadBinariesScript .KubeletVersion false | indent 10 }}
+{{- if .ProviderIDScript }}
+
+    - path: /opt/bin/kubelet-provider-id
+      filesystem: root
+      mode: 0755
+      contents:
+        inline: |
+{{ .ProviderIDScript | indent 10 }}
+{{- end }}
 `
@@ -0,0 +1,78 @@
+package helper
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/kubermatic/machine-controller/pkg/userdata/cloud"
+
+	clusterv1alpha1 "sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+)
+
+// GetDataDisks returns the disks which have to be formatted and mounted on the machine.
+// Cloud providers which don't attach additional disks don't implement the cloud.DataDiskProvider.
+func GetDataDisks(ccProvider cloud.ConfigProvider, spec clusterv1alpha1.MachineSpec) ([]cloud.DataDisk, error) {
+	diskProvider, ok := ccProvider.(cloud.DataDiskProvider)
+	if !ok {
+		return nil, nil
+	}
+	disks, err := diskProvider.GetDataDisks(spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get data disks: %v", err)
+	}
+	return disks, nil
+}
+
+// GetCloudConfigFiles returns the files the cloud config refers to.
+// Cloud providers without such files don't implement the cloud.CloudConfigFileProvider.
+func GetCloudConfigFiles(ccProvider cloud.ConfigProvider, spec clusterv1alpha1.MachineSpec) ([]cloud.CloudConfigFile, error) {
+	fileProvider, ok := ccProvider.(cloud.CloudConfigFileProvider)
+	if !ok {
+		return nil, nil
+	}
+	files, err := fileProvider.GetCloudConfigFiles(spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cloud config files: %v", err)
+	}
+	return files, nil
+}
+
+// SystemdMountUnitName returns the name of the systemd mount unit for the given path,
+// escaped like systemd-escape --path --suffix=mount does
+func SystemdMountUnitName(path string) string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return "-.mount"
+	}
+
+	escaped := &bytes.Buffer{}
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		switch {
+		case c == '/':
+			escaped.WriteByte('-')
+		case c == '.' && i == 0:
+			fmt.Fprintf(escaped, `\x%02x`, c)
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == ':', c == '_', c == '.':
+			escaped.WriteByte(c)
+		default:
+			fmt.Fprintf(escaped, `\x%02x`, c)
+		}
+	}
+	return escaped.String() + ".mount"
+}
+
+// GetProviderIDScript returns the script which determines the provider id of the node on the machine.
+// Cloud providers which don't need the provider id set by the kubelet don't implement the cloud.ProviderIDProvider.
+func GetProviderIDScript(ccProvider cloud.ConfigProvider, spec clusterv1alpha1.MachineSpec) (string, error) {
+	idProvider, ok := ccProvider.(cloud.ProviderIDProvider)
+	if !ok {
+		return "", nil
+	}
+	script, err := idProvider.GetProviderIDScript(spec)
+	if err != nil {
+		return "", fmt.Errorf("failed to get provider id script: %v", err)
+	}
+	return script, nil
+}
@@ -0,0 +1,24 @@
+package helper
+
+import "testing"
+
+func TestSystemdMountUnitName(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{path: "/", expected: "-.mount"},
+		{path: "/var/lib/docker", expected: "var-lib-docker.mount"},
+		{path: "/mnt/data-1/", expected: `mnt-data\x2d1.mount`},
+		{path: "/srv/.cache", expected: "srv-.cache.mount"},
+		{path: "/.hidden", expected: `\x2ehidden.mount`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			if name := SystemdMountUnitName(test.path); name != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, name)
+			}
+		})
+	}
+}
@@ -30,6 +30,9 @@ const (
 {{- if and (.Hostname) (ne .CloudProvider "aws") }}
 --hostname-override={{ .Hostname }} \
 {{- end }}
+{{- if .SetProviderID }}
+--provider-id=${KUBELET_PROVIDER_ID} \
+{{- end }}
 --read-only-port=0 \
 --exit-on-lock-contention \
 --lock-file=/tmp/kubelet.lock \
@@ -41,6 +44,10 @@ const (
 	kubeletSystemdUnitTpl = `[Unit]
 After=docker.service
 Requires=docker.service
+{{- if .SetProviderID }}
+After=kubelet-provider-id.service
+Requires=kubelet-provider-id.service
+{{- end }}
 
 Description=kubelet: The Kubernetes Node Agent
 Documentation=https://kubernetes.io/docs/home/
@@ -51,16 +58,20 @@ StartLimitInterval=0
 RestartSec=10
 
 Environment="PATH=/opt/bin:/bin:/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin/"
+{{- if .SetProviderID }}
+EnvironmentFile=/etc/kubernetes/kubelet-provider-id.env
+{{- end }}
 
 ExecStart=/opt/bin/kubelet $KUBELET_EXTRA_ARGS \
-{{ kubeletFlags .KubeletVersion .CloudProvider .Hostname .ClusterDNSIPs | indent 2 }}
+{{ kubeletFlags .KubeletVersion .CloudProvider .Hostname .ClusterDNSIPs .SetProviderID | indent 2 }}
 
 [Install]
 WantedBy=multi-user.target`
 )
 
-// KubeletSystemdUnit returns the systemd unit for the kubelet
-func KubeletSystemdUnit(kubeletVersion, cloudProvider, hostname string, dnsIPs []net.IP) (string, error) {
+// KubeletSystemdUnit returns the systemd unit for the kubelet.
+// With setProviderID the kubelet reads its provider id from the file written by the kubelet-provider-id unit.
+func KubeletSystemdUnit(kubeletVersion, cloudProvider, hostname string, dnsIPs []net.IP, setProviderID bool) (string, error) {
 	tmpl, err := template.New("kubelet-systemd-unit").Funcs(TxtFuncMap()).Parse(kubeletSystemdUnitTpl)
 	if err != nil {
 		return "", fmt.Errorf("failed to parse kubelet-systemd-unit template: %v", err)
@@ -71,11 +82,13 @@ func KubeletSystemdUnit(kubeletVersion, cloudProvider, hostname string, dnsIPs [
 		CloudProvider  string
 		Hostname       string
 		ClusterDNSIPs  []net.IP
+		SetProviderID  bool
 	}{
 		KubeletVersion: kubeletVersion,
 		CloudProvider:  cloudProvider,
 		Hostname:       hostname,
 		ClusterDNSIPs:  dnsIPs,
+		SetProviderID:  setProviderID,
 	}
 	b := &bytes.Buffer{}
 	err = tmpl.Execute(b, data)
@@ -87,7 +100,7 @@ func KubeletSystemdUnit(kubeletVersion, cloudProvider, hostname string, dnsIPs [
 }
 
 // KubeletFlags returns the kubelet flags
-func KubeletFlags(version, cloudProvider, hostname string, dnsIPs []net.IP) (string, error) {
+func KubeletFlags(version, cloudProvider, hostname string, dnsIPs []net.IP, setProviderID bool) (string, error) {
 	tmpl, err := template.New("kubelet-flags").Funcs(TxtFuncMap()).Parse(kubeletFlagsTpl)
 	if err != nil {
 		return "", fmt.Errorf("failed to parse kubelet-flags template: %v", err)
@@ -98,11 +111,13 @@ func KubeletFlags(version, cloudProvider, hostname string, dnsIPs []net.IP) (str
 		Hostname       string
 		ClusterDNSIPs  []net.IP
 		KubeletVersion string
+		SetProviderID  bool
 	}{
 		CloudProvider:  cloudProvider,
 		Hostname:       hostname,
 		ClusterDNSIPs:  dnsIPs,
 		KubeletVersion: version,
+		SetProviderID:  setProviderID,
 	}
 	b := &bytes.Buffer{}
 	err = tmpl.Execute(b, data)
@@ -113,6 +128,21 @@ func KubeletFlags(version, cloudProvider, hostname string, dnsIPs []net.IP) (str
 	return string(b.String()), nil
 }
 
+// KubeletProviderIDSystemdUnit returns the systemd unit which runs the provider id script
+// and writes its output to the environment file of the kubelet
+func KubeletProviderIDSystemdUnit() string {
+	return `[Unit]
+Description=Determine the provider id of the node
+Wants=network-online.target
+After=network-online.target
+
+[Service]
+Type=oneshot
+RemainAfterExit=true
+ExecStart=/bin/bash -c 'set -o pipefail; /opt/bin/kubelet-provider-id | sed "s/^/KUBELET_PROVIDER_ID=/" > /etc/kubernetes/kubelet-provider-id.env'
+`
+}
+
 func KubeletHealthCheckSystemdUnit() string {
 	return `[Unit]
 Requires=kubelet.service
@@ -16,6 +16,7 @@ type kubeletFlagTestCase struct {
 	dnsIPs        []net.IP
 	hostname      string
 	cloudProvider string
+	setProviderID bool
 }
 
 func TestKubeletSystemdUnit(t *testing.T) {
@@ -46,12 +47,20 @@ func TestKubeletSystemdUnit(t *testing.T) {
 			hostname:      "some-test-node",
 			cloudProvider: "aws",
 		},
+		{
+			name:          "provider-id-set",
+			version:       semver.MustParse("v1.12.0"),
+			dnsIPs:        []net.IP{net.ParseIP("10.10.10.10")},
+			hostname:      "some-test-node",
+			cloudProvider: "external",
+			setProviderID: true,
+		},
 	}...)
 
 	for _, test := range tests {
 		name := fmt.Sprintf("kublet_systemd_unit_%s", test.name)
 		t.Run(name, func(t *testing.T) {
-			out, err := KubeletSystemdUnit(test.version.String(), test.cloudProvider, test.hostname, test.dnsIPs)
+			out, err := KubeletSystemdUnit(test.version.String(), test.cloudProvider, test.hostname, test.dnsIPs, test.setProviderID)
 			if err != nil {
 				t.Error(err)
 			}
@@ -16,8 +16,10 @@ func TxtFuncMap() template.FuncMap {
 	funcMap["kernelModules"] = KernelModules
 	funcMap["kernelSettings"] = KernelSettings
 	funcMap["journalDConfig"] = JournalDConfig
+	funcMap["kubeletProviderIDSystemdUnit"] = KubeletProviderIDSystemdUnit
 	funcMap["kubeletHealthCheckSystemdUnit"] = KubeletHealthCheckSystemdUnit
 	funcMap["containerRuntimeHealthCheckSystemdUnit"] = ContainerRuntimeHealthCheckSystemdUnit
+	funcMap["systemdMountUnitName"] = SystemdMountUnitName
 
 	return funcMap
 }
@@ -57,15 +57,31 @@ const (
 )
 
 type fakeCloudConfigProvider struct {
-	config string
-	name   string
-	err    error
+	config    string
+	name      string
+	err       error
+	dataDisks []cloud.DataDisk
+	files     []cloud.CloudConfigFile
+
+	providerIDScript string
 }
 
 func (p *fakeCloudConfigProvider) GetCloudConfig(spec clusterv1alpha1.MachineSpec) (config string, name string, err error) {
 	return p.config, p.name, p.err
 }
 
+func (p *fakeCloudConfigProvider) GetDataDisks(spec clusterv1alpha1.MachineSpec) ([]cloud.DataDisk, error) {
+	return p.dataDisks, nil
+}
+
+func (p *fakeCloudConfigProvider) GetCloudConfigFiles(spec clusterv1alpha1.MachineSpec) ([]cloud.CloudConfigFile, error) {
+	return p.files, nil
+}
+
+func (p *fakeCloudConfigProvider) GetProviderIDScript(spec clusterv1alpha1.MachineSpec) (string, error) {
+	return p.providerIDScript, nil
+}
+
 var update = flag.Bool("update", false, "update .golden files")
 
 func getSimpleVersionTests() []userDataTestCase {
@@ -236,6 +252,62 @@ func TestProvider_UserData(t *testing.T) {
 			kubernetesCACert: "CACert",
 			osConfig:         &Config{DistUpgradeOnBoot: false},
 		},
+		{
+			name: "aws-data-disks",
+			providerConfig: &providerconfig.Config{
+				CloudProvider: "aws",
+				SSHPublicKeys: []string{"ssh-rsa AAABBB"},
+			},
+			spec: clusterv1alpha1.MachineSpec{
+				ObjectMeta: metav1.ObjectMeta{Name: "node1"},
+				Versions: clusterv1alpha1.MachineVersionInfo{
+					Kubelet: defaultVersion,
+				},
+			},
+			ccProvider: &fakeCloudConfigProvider{name: "aws", config: "{aws-config:true}", err: nil, dataDisks: []cloud.DataDisk{
+				{Device: "/dev/xvdf", Filesystem: "ext4", MountPath: "/var/lib/docker"},
+				{Device: "/dev/xvdg", Filesystem: "xfs", MountPath: "/mnt/data-1"},
+			}},
+			DNSIPs:           []net.IP{net.ParseIP("10.10.10.10")},
+			kubernetesCACert: "CACert",
+			osConfig:         &Config{DistUpgradeOnBoot: false},
+		},
+		{
+			name: "azure-client-certificate",
+			providerConfig: &providerconfig.Config{
+				CloudProvider: "azure",
+				SSHPublicKeys: []string{"ssh-rsa AAABBB"},
+			},
+			spec: clusterv1alpha1.MachineSpec{
+				ObjectMeta: metav1.ObjectMeta{Name: "node1"},
+				Versions: clusterv1alpha1.MachineVersionInfo{
+					Kubelet: defaultVersion,
+				},
+			},
+			ccProvider: &fakeCloudConfigProvider{name: "azure", config: "{azure-config:true}", err: nil, files: []cloud.CloudConfigFile{
+				{Path: "/etc/kubernetes/azure-client-certificate.pfx", Content: "\x30\x82pfx"},
+			}},
+			DNSIPs:           []net.IP{net.ParseIP("10.10.10.10")},
+			kubernetesCACert: "CACert",
+			osConfig:         &Config{DistUpgradeOnBoot: false},
+		},
+		{
+			name: "provider-id",
+			providerConfig: &providerconfig.Config{
+				CloudProvider: "alibaba",
+				SSHPublicKeys: []string{"ssh-rsa AAABBB", "ssh-rsa CCCDDD"},
+			},
+			spec: clusterv1alpha1.MachineSpec{
+				ObjectMeta: metav1.ObjectMeta{Name: "node1"},
+				Versions: clusterv1alpha1.MachineVersionInfo{
+					Kubelet: defaultVersion,
+				},
+			},
+			ccProvider:       &fakeCloudConfigProvider{name: "external", config: "{alibaba-config:true}", err: nil, providerIDScript: "#!/bin/bash\necho provider-id\n"},
+			DNSIPs:           []net.IP{net.ParseIP("10.10.10.10")},
+			kubernetesCACert: "CACert",
+			osConfig:         &Config{DistUpgradeOnBoot: false},
+		},
 	}...)
 
 	for _, test := range tests {